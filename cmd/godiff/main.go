@@ -0,0 +1,237 @@
+// Command godiff diffs, patches and searches texts with the dmp package
+// from the command line:
+//
+//	godiff diff [-html | -unified | -delta | -patch] a.txt b.txt
+//	godiff patch file patchfile
+//	godiff match text pattern loc
+//
+// diff prints the changes from a.txt to b.txt, colored for a terminal
+// unless another format is chosen, and exits with status 1 if the files
+// differ.  patch applies a patch in the format of dmp.PatchToText, or a
+// unified diff with -unified, to file and prints the result; it exits
+// with status 1 if a hunk does not apply.  match prints the offset of the
+// best match of pattern in text near loc, or -1.  "-" reads a file from
+// standard input.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+// Exit statuses, as for diff(1): 1 reports differences or failed hunks,
+// 2 trouble.
+const (
+	exitOK      = 0
+	exitChanges = 1
+	exitError   = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run runs the command line args, and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: godiff diff|patch|match [flags] args...")
+		return exitError
+	}
+	cmds := map[string]func(*command) (int, error){
+		"diff":  diffCmd,
+		"patch": patchCmd,
+		"match": matchCmd,
+	}
+	fn, ok := cmds[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "godiff: unknown command %q\n", args[0])
+		return exitError
+	}
+	c := &command{
+		flags:  flag.NewFlagSet("godiff "+args[0], flag.ContinueOnError),
+		args:   args[1:],
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+	}
+	c.flags.SetOutput(stderr)
+	c.flags.DurationVar(&c.timeout, "timeout", time.Second,
+		"time to spend on a diff before giving up (0 for no limit)")
+	status, err := fn(c)
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(stderr, "godiff:", err)
+		}
+		return exitError
+	}
+	return status
+}
+
+// command is a subcommand being run.
+type command struct {
+	flags   *flag.FlagSet
+	args    []string
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+	timeout time.Duration
+}
+
+// parse parses the flags of c, which may come before, between or after
+// the n positional arguments it returns.
+func (c *command) parse(n int) ([]string, error) {
+	var pos []string
+	args := c.args
+	for {
+		if err := c.flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = c.flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+	if len(pos) != n {
+		return nil, fmt.Errorf("%s takes %d arguments, not %d",
+			c.flags.Name(), n, len(pos))
+	}
+	return pos, nil
+}
+
+// dmp returns the settings of the diffs of c.
+func (c *command) dmp() *dmp.DMP {
+	d := dmp.New()
+	d.DiffTimeout = c.timeout
+	return d
+}
+
+// read returns the content of the file at path, or of the standard input
+// for "-".
+func (c *command) read(path string) (string, error) {
+	if path == "-" {
+		b, err := ioutil.ReadAll(c.stdin)
+		return string(b), err
+	}
+	b, err := ioutil.ReadFile(path)
+	return string(b), err
+}
+
+func diffCmd(c *command) (int, error) {
+	html := c.flags.Bool("html", false, "print the diff as HTML")
+	unified := c.flags.Bool("unified", false, "print a unified diff")
+	delta := c.flags.Bool("delta", false, "print the diff as a delta")
+	patch := c.flags.Bool("patch", false, "print a patch")
+	context := c.flags.Int("context", 3,
+		"unchanged lines around the changes of a unified diff")
+	args, err := c.parse(2)
+	if err != nil {
+		return 0, err
+	}
+	text1, err := c.read(args[0])
+	if err != nil {
+		return 0, err
+	}
+	text2, err := c.read(args[1])
+	if err != nil {
+		return 0, err
+	}
+	if text1 == text2 {
+		return exitOK, nil
+	}
+
+	d := c.dmp()
+	w := c.stdout
+	switch {
+	case *unified:
+		_, err = io.WriteString(w, "--- "+args[0]+"\n+++ "+args[1]+"\n")
+		if err == nil {
+			err = d.DiffWriteUnified(w, text1, text2, *context)
+		}
+	case *patch:
+		err = dmp.PatchWriteText(w, d.PatchMake(text1, text2))
+	default:
+		diffs := d.DiffCleanupSemantic(d.DiffMain(text1, text2, true))
+		switch {
+		case *html:
+			err = dmp.DiffWriteHtml(w, diffs, dmp.HtmlOptions{})
+			if err == nil {
+				_, err = io.WriteString(w, "\n")
+			}
+		case *delta:
+			_, err = io.WriteString(w, dmp.DiffToDelta(diffs)+"\n")
+		default:
+			err = dmp.DiffWriteText(w, diffs, dmp.TextOptions{})
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return exitChanges, nil
+}
+
+func patchCmd(c *command) (int, error) {
+	unified := c.flags.Bool("unified", false,
+		"read the patch as a unified diff")
+	args, err := c.parse(2)
+	if err != nil {
+		return 0, err
+	}
+	text, err := c.read(args[0])
+	if err != nil {
+		return 0, err
+	}
+	patch, err := c.read(args[1])
+	if err != nil {
+		return 0, err
+	}
+
+	d := c.dmp()
+	var applied []bool
+	if *unified {
+		if text, applied, err = d.ApplyUnified(patch, text); err != nil {
+			return 0, err
+		}
+	} else {
+		ps, err := dmp.PatchFromText(patch)
+		if err != nil {
+			return 0, err
+		}
+		text, applied = d.Apply(ps, text)
+	}
+	if _, err := io.WriteString(c.stdout, text); err != nil {
+		return 0, err
+	}
+	status := exitOK
+	for i, ok := range applied {
+		if !ok {
+			fmt.Fprintf(c.stderr, "godiff: hunk %d does not apply\n", i+1)
+			status = exitChanges
+		}
+	}
+	return status, nil
+}
+
+func matchCmd(c *command) (int, error) {
+	args, err := c.parse(3)
+	if err != nil {
+		return 0, err
+	}
+	loc, err := strconv.Atoi(args[2])
+	if err != nil {
+		return 0, err
+	}
+	i := c.dmp().MatchMain(args[0], args[1], loc)
+	if _, err := fmt.Fprintln(c.stdout, i); err != nil {
+		return 0, err
+	}
+	return exitOK, nil
+}
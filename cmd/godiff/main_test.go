@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+// godiff runs the command line args with stdin, and returns its exit
+// status and output.
+func godiff(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestGodiff(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	assert.Nil(t, ioutil.WriteFile(a, []byte("one\ntwo\nthree\n"), 0644), "")
+	assert.Nil(t, ioutil.WriteFile(b, []byte("one\n2\nthree\n"), 0644), "")
+
+	status, out, _ := godiff("", "diff", a, a)
+	assert.Equal(t, 0, status, "")
+	assert.Equal(t, "", out, "")
+
+	status, out, _ = godiff("", "diff", "--delta", a, b)
+	assert.Equal(t, 1, status, "")
+	assert.Equal(t, "=4\t-3\t+2\t=7\n", out, "")
+
+	// Flags may follow the files.
+	status, out, _ = godiff("", "diff", a, b, "-unified", "-context", "0")
+	assert.Equal(t, 1, status, "")
+	assert.Equal(t, "--- "+a+"\n+++ "+b+"\n@@ -2 +2 @@\n-two\n+2\n", out, "")
+
+	status, out, _ = godiff("one\ntwo\n", "diff", "-html", "-", a)
+	assert.Equal(t, 1, status, "")
+	assert.True(t, strings.Contains(out, "<ins"), out)
+
+	// A patch made by diff applies with patch.
+	_, patch, _ := godiff("", "diff", "-patch", a, b)
+	p := filepath.Join(dir, "p.patch")
+	assert.Nil(t, ioutil.WriteFile(p, []byte(patch), 0644), "")
+	status, out, _ = godiff("", "patch", a, p)
+	assert.Equal(t, 0, status, "")
+	assert.Equal(t, "one\n2\nthree\n", out, "")
+	status, _, errs := godiff("nothing alike\n", "patch", "-", p)
+	assert.Equal(t, 1, status, "")
+	assert.Equal(t, "godiff: hunk 1 does not apply\n", errs, "")
+
+	_, unified, _ := godiff("", "diff", "-unified", a, b)
+	status, out, _ = godiff(unified, "patch", "-unified", a, "-")
+	assert.Equal(t, 0, status, "")
+	assert.Equal(t, "one\n2\nthree\n", out, "")
+
+	status, out, _ = godiff("", "match", "abcdef", "cde", "0")
+	assert.Equal(t, 0, status, "")
+	assert.Equal(t, "2\n", out, "")
+
+	status, _, errs = godiff("", "match", "abc", "b")
+	assert.Equal(t, 2, status, "")
+	assert.Equal(t, "godiff: godiff match takes 3 arguments, not 2\n", errs, "")
+	status, _, _ = godiff("", "frobnicate")
+	assert.Equal(t, 2, status, "")
+	status, _, _ = godiff("", "diff", a, filepath.Join(dir, "missing"))
+	assert.Equal(t, 2, status, "")
+}
@@ -0,0 +1,108 @@
+package dmp
+
+import (
+	"time"
+)
+
+// Algorithm selects how the built-in engine splits a diff in two once the
+// speedups are exhausted.
+type Algorithm int8
+
+const (
+	// AlgorithmMyers searches the middle snake of Myers's O(ND)
+	// algorithm, in its linear space refinement.  Its two working arrays
+	// hold an int per character of both texts, and its time grows with
+	// the number of edits, so that similar texts are diffed quickly.
+	// This is the default.
+	AlgorithmMyers Algorithm = iota
+	// AlgorithmHirschberg splits the longer text in the middle and finds
+	// where to split the shorter one from two rows of longest common
+	// subsequence lengths, after Hirschberg.  Its working arrays hold an
+	// int per character of the shorter text only, but its time grows
+	// with the product of the lengths of the texts, however similar.
+	AlgorithmHirschberg
+)
+
+// diffSplitPoint returns where the algorithm of dmp splits s1 and s2, or
+// false if the deadline was reached, the context or budget of dmp ran out
+// or the texts have nothing in common.
+func diffSplitPoint[T comparable](
+	dmp *DMP, s1, s2 []T, deadline time.Time,
+) (int, int, bool) {
+	if dmp.DiffAlgorithm == AlgorithmHirschberg {
+		return hirschbergSplit(dmp, s1, s2, deadline)
+	}
+	return diffMiddleSnake(dmp, s1, s2, deadline)
+}
+
+// hirschbergSplit splits the longer of s1 and s2 in the middle, and the
+// other where the longest common subsequences of the two halves add up to
+// the longest.
+func hirschbergSplit[T comparable](
+	dmp *DMP, s1, s2 []T, deadline time.Time,
+) (int, int, bool) {
+	if len(s1) < len(s2) {
+		y, x, ok := hirschbergSplit(dmp, s2, s1, deadline)
+		return x, y, ok
+	}
+	n := len(s2)
+	if !dmp.budget.alloc(4 * (n + 1) * intSize) {
+		return 0, 0, false
+	}
+	mid := len(s1) / 2
+	front, ok := lcsRow(dmp, s1[:mid], s2, false, deadline)
+	if !ok {
+		return 0, 0, false
+	}
+	back, ok := lcsRow(dmp, s1[mid:], s2, true, deadline)
+	if !ok {
+		return 0, 0, false
+	}
+	best, y := 0, 0
+	for j := 0; j <= n; j++ {
+		if l := front[j] + back[n-j]; l > best {
+			best, y = l, j
+		}
+	}
+	if best == 0 {
+		return 0, 0, false
+	}
+	return mid, y, true
+}
+
+// lcsRow returns the lengths of the longest common subsequences of a and
+// each prefix of b, indexed by the length of the prefix, or of each suffix
+// of b if reverse is set, in which case a is read backwards too.  Returns
+// false if the deadline was reached or the context or budget of dmp ran
+// out.
+func lcsRow[T comparable](
+	dmp *DMP, a, b []T, reverse bool, deadline time.Time,
+) ([]int, bool) {
+	n := len(b)
+	prev := make([]int, n+1)
+	row := make([]int, n+1)
+	for i := range a {
+		if time.Now().After(deadline) || isDone(dmp.done) ||
+			!dmp.budget.step() {
+			dmp.budget.cut()
+			return nil, false
+		}
+		r := a[i]
+		if reverse {
+			r = a[len(a)-1-i]
+		}
+		for j := 1; j <= n; j++ {
+			c := b[j-1]
+			if reverse {
+				c = b[n-j]
+			}
+			if r == c {
+				row[j] = prev[j-1] + 1
+			} else {
+				row[j] = max(prev[j], row[j-1])
+			}
+		}
+		prev, row = row, prev
+	}
+	return prev, true
+}
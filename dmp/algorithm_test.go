@@ -0,0 +1,59 @@
+package dmp
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffAlgorithm(t *testing.T) {
+	myers := New()
+	myers.DiffTimeout = 0
+	hirschberg := New()
+	hirschberg.DiffTimeout = 0
+	hirschberg.DiffAlgorithm = AlgorithmHirschberg
+
+	assert.Equal(t,
+		[]Diff{{DiffDelete, "a"}, {DiffInsert, "ڀ"}, {DiffEqual, "x"},
+			{DiffDelete, "\t"}, {DiffInsert, "\u0000"}},
+		hirschberg.DiffMain("ax\t", "ڀx\u0000", false), "")
+	assert.Equal(t,
+		[]Diff{{DiffDelete, "abc"}, {DiffInsert, "xyz"}},
+		hirschberg.DiffMain("abc", "xyz", false), "")
+
+	// Both algorithms find minimal diffs.
+	r := rand.New(rand.NewSource(1))
+	text := func() string {
+		b := make([]byte, r.Intn(40))
+		for i := range b {
+			b[i] = "abcd"[r.Intn(4)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 500; i++ {
+		s1, s2 := text(), text()
+		want := myers.DiffMain(s1, s2, false)
+		diffs := hirschberg.DiffMain(s1, s2, false)
+		assert.Equal(t, s1, DiffText1(diffs), "")
+		assert.Equal(t, s2, DiffText2(diffs), "")
+		assert.Equal(t, diffEdits(want), diffEdits(diffs), s1+" "+s2)
+	}
+
+	s1 := readFile("speedtest1.txt", t)
+	s2 := readFile("speedtest2.txt", t)
+	diffs := hirschberg.DiffMain(s1, s2, true)
+	assert.Equal(t, s1, DiffText1(diffs), "")
+	assert.Equal(t, s2, DiffText2(diffs), "")
+}
+
+// diffEdits counts the inserted and deleted characters of diffs.
+func diffEdits(diffs []Diff) int {
+	n := 0
+	for _, d := range diffs {
+		if d.Type != DiffEqual {
+			n += len(d.Text)
+		}
+	}
+	return n
+}
@@ -0,0 +1,46 @@
+package dmp
+
+import (
+	"strings"
+)
+
+// AmbiguityPolicy selects where Apply puts a patch whose text occurs more
+// than once in the target text.
+type AmbiguityPolicy int8
+
+const (
+	// AmbiguityBestScore leaves the choice to the matcher, whose score
+	// mixes the number of errors and the distance from the expected
+	// location, so a close fuzzy match may win over a distant exact one.
+	// This is the default.
+	AmbiguityBestScore AmbiguityPolicy = iota
+	// AmbiguityFail refuses to apply the patch.
+	AmbiguityFail
+	// AmbiguityFirst uses the first occurrence in the text.
+	AmbiguityFirst
+	// AmbiguityNearest uses the exact occurrence nearest to the expected
+	// location, preferring the earlier one on ties.
+	AmbiguityNearest
+)
+
+// ambiguousMatch picks among the occurrences of pattern in text according
+// to policy.  Returns -1 if the patch should not be applied.
+func ambiguousMatch(
+	policy AmbiguityPolicy, text, pattern string, loc int,
+) int {
+	switch policy {
+	case AmbiguityFirst:
+		return strings.Index(text, pattern)
+	case AmbiguityNearest:
+		best := -1
+		for i := indexOf(text, pattern, 0); i != -1; i = indexOf(
+			text, pattern, i+1,
+		) {
+			if best == -1 || abs(i-loc) < abs(best-loc) {
+				best = i
+			}
+		}
+		return best
+	}
+	return -1
+}
@@ -0,0 +1,58 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchAmbiguity(t *testing.T) {
+	dmp := New()
+	patches := dmp.PatchMake("0123456789 The cat sat. 0123456789",
+		"0123456789 The dog sat. 0123456789")
+	assert.Equal(t, 1, len(patches), "")
+	target := "The cat sat. BBBBBBB The cat sat. CCCC"
+
+	// Default: the matcher picks the occurrence near the expected location.
+	s, results := dmp.ApplyDetailed(patches, target)
+	// The text is not searched for copies.
+	assert.Equal(t, "The cat sat. BBBBBBB The dog sat. CCCC", s, "")
+	assert.False(t, results[0].Ambiguous, "")
+	assert.True(t, results[0].Applied, "")
+
+	dmp.PatchAmbiguity = AmbiguityFirst
+	s, results = dmp.ApplyDetailed(patches, target)
+	assert.Equal(t, "The dog sat. BBBBBBB The cat sat. CCCC", s, "")
+	assert.True(t, results[0].Ambiguous, "")
+	assert.True(t, results[0].Applied, "")
+
+	dmp.PatchAmbiguity = AmbiguityNearest
+	s, _ = dmp.ApplyDetailed(patches, target)
+	assert.Equal(t, "The cat sat. BBBBBBB The dog sat. CCCC", s, "")
+
+	dmp.PatchAmbiguity = AmbiguityFail
+	s, results = dmp.ApplyDetailed(patches, target)
+	assert.Equal(t, target, s, "")
+	assert.True(t, results[0].Ambiguous, "")
+	assert.False(t, results[0].Applied, "")
+
+	// Unambiguous patches are not affected by the policy.
+	s, results = dmp.ApplyDetailed(patches, "AAAA The cat sat. CCCC")
+	assert.Equal(t, "AAAA The dog sat. CCCC", s, "")
+	assert.False(t, results[0].Ambiguous, "")
+
+	// Nor are insertions into an empty text.
+	patches = dmp.PatchMake("", "new")
+	s, results = dmp.ApplyDetailed(patches, "")
+	assert.Equal(t, "new", s, "")
+	assert.False(t, results[0].Ambiguous, "")
+	assert.True(t, results[0].Applied, "")
+}
+
+func TestAmbiguousMatch(t *testing.T) {
+	assert.Equal(t, 0, ambiguousMatch(AmbiguityFirst, "ab ab ab", "ab", 5), "")
+	assert.Equal(t, 6, ambiguousMatch(AmbiguityNearest, "ab ab ab", "ab", 5), "")
+	assert.Equal(t, 0, ambiguousMatch(AmbiguityNearest, "ab ab ab", "ab", 1), "")
+	assert.Equal(t, 3, ambiguousMatch(AmbiguityNearest, "ab ab ab", "ab", 3), "")
+	assert.Equal(t, -1, ambiguousMatch(AmbiguityFail, "ab ab ab", "ab", 3), "")
+}
@@ -0,0 +1,60 @@
+package dmp
+
+import (
+	"unicode/utf8"
+)
+
+// Anchor is a region known to be equal in both texts of a diff:
+// text1[Start1:Start1+Length] is text2[Start2:Start2+Length].
+type Anchor struct {
+	Start1 int
+	Start2 int
+	Length int
+}
+
+// DiffMainAnchored finds the differences between two texts like DiffMain,
+// but keeps the anchors given as equalities and only diffs the gaps
+// between them, as when the unchanged regions are known from a previous
+// diff or from hashes of the content.  The anchors must be in order in
+// both texts and not overlap; those that do not, or whose texts are not
+// equal or do not start and end on rune boundaries, are skipped.
+func (dmp *DMP) DiffMainAnchored(
+	text1, text2 string, anchors []Anchor,
+) []Diff {
+	diffs := []Diff{}
+	end1, end2 := 0, 0
+	for _, a := range anchors {
+		if !anchorValid(text1, text2, a, end1, end2) {
+			continue
+		}
+		diffs = append(diffs, dmp.DiffMain(
+			text1[end1:a.Start1], text2[end2:a.Start2], true,
+		)...)
+		end1, end2 = a.Start1+a.Length, a.Start2+a.Length
+		diffs = append(diffs, Diff{DiffEqual, text1[a.Start1:end1]})
+	}
+	diffs = append(diffs, dmp.DiffMain(text1[end1:], text2[end2:], true)...)
+	return diffCleanupMerge(diffs)
+}
+
+// anchorValid reports whether a can anchor a diff of text1 and text2 whose
+// previous anchor ends at end1 and end2.
+func anchorValid(text1, text2 string, a Anchor, end1, end2 int) bool {
+	if a.Length <= 0 || a.Start1 < end1 || a.Start2 < end2 ||
+		a.Length > len(text1)-a.Start1 || a.Length > len(text2)-a.Start2 {
+		return false
+	}
+	for _, b := range []struct {
+		s string
+		i int
+	}{
+		{text1, a.Start1}, {text1, a.Start1 + a.Length},
+		{text2, a.Start2}, {text2, a.Start2 + a.Length},
+	} {
+		if b.i < len(b.s) && !utf8.RuneStart(b.s[b.i]) {
+			return false
+		}
+	}
+	return text1[a.Start1:a.Start1+a.Length] ==
+		text2[a.Start2:a.Start2+a.Length]
+}
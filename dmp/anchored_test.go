@@ -0,0 +1,62 @@
+package dmp
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffMainAnchored(t *testing.T) {
+	dmp := New()
+	text1 := "The cat sat on the mat."
+	text2 := "The dog sat on the rug."
+
+	// The anchors are kept and the gaps diffed.
+	diffs := dmp.DiffMainAnchored(text1, text2, []Anchor{{7, 7, 12}})
+	assert.Equal(t, []Diff{
+		{DiffEqual, "The "},
+		{DiffDelete, "cat"},
+		{DiffInsert, "dog"},
+		{DiffEqual, " sat on the "},
+		{DiffDelete, "mat"},
+		{DiffInsert, "rug"},
+		{DiffEqual, "."}}, diffs, "")
+
+	// An anchor DiffMain would not choose is kept.
+	diffs = dmp.DiffMainAnchored("abcab", "ab", []Anchor{{3, 0, 2}})
+	assert.Equal(t, []Diff{{DiffDelete, "abc"}, {DiffEqual, "ab"}}, diffs, "")
+	assert.Equal(t, []Diff{{DiffEqual, "ab"}, {DiffDelete, "cab"}},
+		dmp.DiffMain("abcab", "ab", false), "")
+
+	// Invalid anchors are skipped.
+	want := dmp.DiffMain(text1, text2, true)
+	for _, anchors := range [][]Anchor{
+		nil,
+		{{0, 0, 5}},               // Not equal.
+		{{7, 7, 20}},              // Past the end.
+		{{7, 7, 0}},               // Empty.
+		{{-1, 0, 1}},              // Negative.
+		{{8, 8, 4}, {7, 7, 4}},    // Out of order.
+		{{18, 18, 4}, {4, 4, 15}}, // Overlapping.
+		{{1, 1, math.MaxInt}},     // Overflowing.
+	} {
+		got := dmp.DiffMainAnchored(text1, text2, anchors)
+		assert.Equal(t, DiffText1(want), DiffText1(got), "")
+		assert.Equal(t, DiffText2(want), DiffText2(got), "")
+	}
+	diffs = dmp.DiffMainAnchored("Ünï", "Ün!", []Anchor{{1, 1, 2}})
+	assert.Equal(t, "Ünï", DiffText1(diffs), "Not on rune boundaries.")
+	assert.Equal(t, "Ün!", DiffText2(diffs), "")
+
+	// Re-diff after an edit, with the regions around it as anchors.
+	old := strings.Repeat("line\n", 1000)
+	edited := old[:2500] + "edit\n" + old[2500:]
+	diffs = dmp.DiffMainAnchored(old, edited, []Anchor{
+		{0, 0, 2400}, {2600, 2605, 2400},
+	})
+	assert.Equal(t, old, DiffText1(diffs), "")
+	assert.Equal(t, edited, DiffText2(diffs), "")
+	assert.Equal(t, 5, DiffLevenshtein(diffs), "")
+}
@@ -0,0 +1,234 @@
+package dmp
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// ApplyReader applies patches to the text read from r, and writes the
+// patched text to w, like Apply but without holding the whole text: only a
+// window around each patch is read, of MatchMaxBits plus MatchThreshold
+// times MatchDistance bytes on each side, and the text between windows is
+// copied through.  The patches must be sorted by location, as PatchMake
+// makes them.  A patch is only searched for inside its window, so unlike
+// with Apply, patches are not found farther from where they are expected
+// than MatchDistance allows, nor is PatchAmbiguity told of copies of their
+// text outside the window.
+//
+// It returns which patches were applied, one per patch split by
+// PatchSplitMax, as Apply does.
+func (dmp *DMP) ApplyReader(
+	ps []Patch, r io.ReadSeeker, w io.Writer,
+) ([]bool, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if len(ps) == 0 {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		_, err := io.Copy(w, r)
+		return []bool{}, err
+	}
+
+	ps = PatchDeepCopy(ps)
+	pad, _ := patchAddPaddingEdges(ps, patchPadding(dmp))
+	ps = patchSplitMax(ps, dmp.MatchMaxBits, dmp.PatchMargin)
+	sa := &streamApply{
+		r:    r,
+		w:    w,
+		pad:  pad,
+		size: int(size) + 2*len(pad),
+		skip: len(pad),
+	}
+	margin := int(dmp.MatchThreshold*float64(dmp.MatchDistance)) +
+		dmp.MatchMaxBits
+
+	applied := make([]bool, len(ps))
+	// delta is the offset between the expected and actual location of the
+	// previous patch, as in Apply.
+	delta := 0
+	for i, p := range ps {
+		expected := p.start2 + delta
+		// Where the patch is expected in the text read, as the changes
+		// made so far are before it.
+		loc := expected - (sa.out + len(sa.window) - sa.end)
+		lo := max(loc-margin, sa.start)
+		hi := min(loc+p.length1+margin, sa.size)
+		// Keep the padding in one piece, to strip it off at the end.
+		if lo < len(pad) {
+			lo = 0
+		}
+		lo = min(lo, sa.size-len(pad))
+		if hi > sa.size-len(pad) {
+			hi = sa.size
+		}
+		if err := sa.slide(lo, hi); err != nil {
+			return applied, err
+		}
+
+		p.start1 = expected - sa.out
+		p.start2 = p.start1
+		s, results, stats := patchApply(
+			dmp, []Patch{p}, sa.window, applyOptions{prepared: true},
+		)
+		sa.window = s
+		applied[i] = results[0].Applied
+		if applied[i] {
+			delta = stats.Drift[0]
+		} else {
+			delta -= p.length2 - p.length1
+		}
+	}
+
+	if sa.end == sa.size {
+		return applied, sa.emit(
+			sa.window[:max(0, len(sa.window)-len(pad))],
+		)
+	}
+	if err := sa.emit(sa.window); err != nil {
+		return applied, err
+	}
+	return applied, sa.copy(sa.end, sa.size-len(pad))
+}
+
+// ApplyToFile applies patches to the file at path with ApplyReader, and
+// replaces the file with the result if all the patches applied.  The
+// result is written to a temporary file next to it first, so the file is
+// either patched whole or left alone.
+func (dmp *DMP) ApplyToFile(ps []Patch, path string) ([]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	tmp, err := ioutil.TempFile(
+		filepath.Dir(path), "."+filepath.Base(path)+".*",
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	applied, err := dmp.ApplyReader(ps, f, tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || !allTrue(applied) {
+		return applied, err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return applied, err
+	}
+	return applied, os.Rename(tmp.Name(), path)
+}
+
+// streamApply is the state of ApplyReader.  Offsets are in the text read,
+// with the padding of Apply on both ends.
+type streamApply struct {
+	r    io.ReadSeeker
+	w    io.Writer
+	pad  string
+	size int
+	// window is the text from start to end, patched.
+	window     string
+	start, end int
+	// out is the length of the patched text written out, padding
+	// included.
+	out int
+	// skip is the number of bytes of padding yet to drop from the output.
+	skip int
+}
+
+// slide moves the window to start at lo and end at hi or after: the text
+// before lo is written out, and the text up to hi read.
+func (sa *streamApply) slide(lo, hi int) error {
+	if lo > sa.start {
+		// Count from the end of the window, past the changes before lo.
+		cut := min(max(len(sa.window)-(sa.end-lo), 0), len(sa.window))
+		if err := sa.emit(sa.window[:cut]); err != nil {
+			return err
+		}
+		sa.window = sa.window[cut:]
+		if lo > sa.end {
+			if err := sa.copy(sa.end, lo); err != nil {
+				return err
+			}
+			sa.end = lo
+		}
+		sa.start = lo
+	}
+	if hi > sa.end {
+		text, err := sa.read(sa.end, hi)
+		if err != nil {
+			return err
+		}
+		sa.window += text
+		sa.end = hi
+	}
+	return nil
+}
+
+// emit writes s out, less the padding still to drop.
+func (sa *streamApply) emit(s string) error {
+	sa.out += len(s)
+	n := min(sa.skip, len(s))
+	sa.skip -= n
+	_, err := io.WriteString(sa.w, s[n:])
+	return err
+}
+
+// file returns the offsets in r of the text from lo to hi.
+func (sa *streamApply) file(lo, hi int) (int, int) {
+	n := len(sa.pad)
+	return max(lo-n, 0), max(min(hi, sa.size-n)-n, 0)
+}
+
+// read returns the text from lo to hi.
+func (sa *streamApply) read(lo, hi int) (string, error) {
+	var buf bytes.Buffer
+	n := len(sa.pad)
+	if lo < n {
+		buf.WriteString(sa.pad[lo:min(hi, n)])
+	}
+	flo, fhi := sa.file(lo, hi)
+	if flo < fhi {
+		if _, err := sa.r.Seek(int64(flo), io.SeekStart); err != nil {
+			return "", err
+		}
+		if _, err := io.CopyN(&buf, sa.r, int64(fhi-flo)); err != nil {
+			return "", err
+		}
+	}
+	if tail := sa.size - n; hi > tail {
+		buf.WriteString(sa.pad[max(lo, tail)-tail : hi-tail])
+	}
+	return buf.String(), nil
+}
+
+// copy writes out the text from lo to hi, which is outside of windows.
+func (sa *streamApply) copy(lo, hi int) error {
+	if lo < len(sa.pad) {
+		if err := sa.emit(sa.pad[lo:min(hi, len(sa.pad))]); err != nil {
+			return err
+		}
+	}
+	flo, fhi := sa.file(lo, hi)
+	if flo >= fhi {
+		return nil
+	}
+	if _, err := sa.r.Seek(int64(flo), io.SeekStart); err != nil {
+		return err
+	}
+	sa.out += fhi - flo
+	_, err := io.CopyN(sa.w, sa.r, int64(fhi-flo))
+	return err
+}
@@ -0,0 +1,104 @@
+package dmp
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func applyReader(dmp *DMP, ps []Patch, s string) (string, []bool) {
+	var buf bytes.Buffer
+	applied, err := dmp.ApplyReader(ps, strings.NewReader(s), &buf)
+	if err != nil {
+		panic(err)
+	}
+	return buf.String(), applied
+}
+
+func TestApplyReader(t *testing.T) {
+	dmp := New()
+	var b1, b2 bytes.Buffer
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&b1, "line %d\n", i)
+		if i%1000 == 7 || i%1000 == 8 {
+			fmt.Fprintf(&b2, "LINE %d %s\n", i, strings.Repeat("=", 300))
+		} else if i != 0 && i != 4999 {
+			fmt.Fprintf(&b2, "line %d\n", i)
+		}
+	}
+	text1, text2 := b1.String(), b2.String()
+	ps := dmp.PatchMake(text1, text2)
+
+	// Changes at both ends and far apart, that shift the text by more
+	// than a window.
+	s, applied := applyReader(dmp, ps, text1)
+	assert.Equal(t, text2, s, "")
+	want, wantApplied := dmp.Apply(ps, text1)
+	assert.Equal(t, want, s, "")
+	assert.Equal(t, wantApplied, applied, "")
+
+	// Drifted and fuzzy text.
+	drifted := "preface\n" + strings.Replace(text1, "line 3007", "lime 3007", 1)
+	s, applied = applyReader(dmp, ps, drifted)
+	want, wantApplied = dmp.Apply(ps, drifted)
+	assert.Equal(t, want, s, "")
+	assert.Equal(t, wantApplied, applied, "")
+
+	// Failed patches.
+	s, applied = applyReader(dmp, ps, "unrelated")
+	want, wantApplied = dmp.Apply(ps, "unrelated")
+	assert.Equal(t, want, s, "")
+	assert.Equal(t, wantApplied, applied, "")
+
+	// Small texts fit in one window.
+	ps = dmp.PatchMake("The quick brown fox.", "That quick brown fox!")
+	s, applied = applyReader(dmp, ps, "The quick brown fox.")
+	assert.Equal(t, "That quick brown fox!", s, "")
+	assert.Equal(t, []bool{true, true}, applied, "")
+
+	// Null case.
+	s, applied = applyReader(dmp, nil, "Hello world.")
+	assert.Equal(t, "Hello world.", s, "")
+	assert.Equal(t, 0, len(applied), "")
+
+	// Write errors.
+	_, err := dmp.ApplyReader(ps, strings.NewReader("The quick brown fox."),
+		&failWriter{0})
+	assert.NotNil(t, err, "")
+}
+
+func TestApplyToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dmp := New()
+	ps := dmp.PatchMake("alpha\nbeta\ngamma\n", "alpha\nBETA\ngamma\n")
+	path := writeTempFile(t, dir, "a.txt", "alpha\nbeta\ngamma\n")
+	applied, err := dmp.ApplyToFile(ps, path)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []bool{true}, applied, "")
+	b, _ := ioutil.ReadFile(path)
+	assert.Equal(t, "alpha\nBETA\ngamma\n", string(b), "")
+
+	// The file is left alone if a patch fails.
+	path = writeTempFile(t, dir, "b.txt", "something else\n")
+	applied, err = dmp.ApplyToFile(ps, path)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []bool{false}, applied, "")
+	b, _ = ioutil.ReadFile(path)
+	assert.Equal(t, "something else\n", string(b), "")
+	files, _ := ioutil.ReadDir(dir)
+	assert.Equal(t, 2, len(files), "No temporary files left.")
+
+	_, err = dmp.ApplyToFile(ps, filepath.Join(dir, "missing"))
+	assert.NotNil(t, err, "")
+}
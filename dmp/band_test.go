@@ -0,0 +1,51 @@
+package dmp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffBand(t *testing.T) {
+	s1 := readFile("speedtest1.txt", t)
+	near := strings.Replace(s1, "the", "teh", 3)
+	far := readFile("speedtest2.txt", t)
+
+	for _, s2 := range []string{near, far} {
+		full := New()
+		full.DiffTimeout = 0
+		banded := New()
+		banded.DiffTimeout = 0
+		for _, band := range []int{1, 2, 5, 100} {
+			banded.DiffBand = band
+			diffs := banded.DiffMain(s1, s2, false)
+			assert.Equal(t, full.DiffMain(s1, s2, false), diffs, "")
+			assert.Equal(t, s1, DiffText1(diffs), "")
+			assert.Equal(t, s2, DiffText2(diffs), "")
+		}
+	}
+
+	// Texts of very different lengths skip the band.
+	dmp := New()
+	dmp.DiffBand = 2
+	diffs := dmp.DiffMain("abc", strings.Repeat("xaybzc", 10), false)
+	assert.Equal(t, "abc", DiffText1(diffs), "")
+}
+
+func Benchmark_DiffBand(b *testing.B) {
+	s1 := readFile("speedtest1.txt", b)
+	s2 := strings.Replace(s1, "the", "teh", 3)
+	for _, band := range []int{0, 16} {
+		dmp := New()
+		dmp.DiffTimeout = 0
+		dmp.DiffBand = band
+		b.Run(fmt.Sprintf("band=%d", band), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				dmp.DiffMain(s1, s2, false)
+			}
+		})
+	}
+}
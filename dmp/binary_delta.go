@@ -0,0 +1,84 @@
+package dmp
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// DiffToBinaryDelta crushes the diff into a compact binary form of
+// DiffToDelta, for payloads that are not text or not ASCII.  Each
+// operation is a byte, '=', '-' or '+', followed by the length of its
+// text in bytes as a uvarint, and, for insertions, by the text itself as
+// is.  Unlike the deltas of DiffToDelta, the lengths count bytes, so the
+// texts need not be valid UTF-8.
+func DiffToBinaryDelta(diffs []Diff) []byte {
+	diffs = withoutMoves(diffs)
+	delta := []byte{}
+	var n [binary.MaxVarintLen64]byte
+	for _, d := range diffs {
+		switch d.Type {
+		case DiffInsert:
+			delta = append(delta, '+')
+		case DiffDelete:
+			delta = append(delta, '-')
+		case DiffEqual:
+			delta = append(delta, '=')
+		default:
+			continue
+		}
+		k := binary.PutUvarint(n[:], uint64(len(d.Text)))
+		delta = append(delta, n[:k]...)
+		if d.Type == DiffInsert {
+			delta = append(delta, d.Text...)
+		}
+	}
+	return delta
+}
+
+// DiffFromBinaryDelta rebuilds the diff from text1 and a delta of
+// DiffToBinaryDelta.
+func DiffFromBinaryDelta(text1 string, delta []byte) ([]Diff, error) {
+	diffs := []Diff{}
+	pointer := 0 // Cursor in text1
+	for len(delta) > 0 {
+		op := delta[0]
+		if op != '+' && op != '-' && op != '=' {
+			return nil, fmt.Errorf(
+				"Invalid diff operation in DiffFromBinaryDelta: %q", op,
+			)
+		}
+		n, k := binary.Uvarint(delta[1:])
+		if k <= 0 {
+			return nil, fmt.Errorf("Invalid length in DiffFromBinaryDelta")
+		}
+		delta = delta[1+k:]
+
+		switch op {
+		case '+':
+			if n > uint64(len(delta)) {
+				return nil, fmt.Errorf("Insertion past the end of the delta")
+			}
+			diffs = append(diffs, Diff{DiffInsert, string(delta[:n])})
+			delta = delta[n:]
+		default:
+			if n > uint64(len(text1)-pointer) {
+				return nil, fmt.Errorf("Index out of bound")
+			}
+			text := text1[pointer : pointer+int(n)]
+			pointer += int(n)
+			if op == '=' {
+				diffs = append(diffs, Diff{DiffEqual, text})
+			} else {
+				diffs = append(diffs, Diff{DiffDelete, text})
+			}
+		}
+	}
+
+	if pointer != len(text1) {
+		return nil, fmt.Errorf(
+			"Delta length (%v) smaller than source text length (%v)",
+			pointer, len(text1),
+		)
+	}
+	return diffs, nil
+}
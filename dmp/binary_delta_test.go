@@ -0,0 +1,60 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffBinaryDelta(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "jump"},
+		{DiffDelete, "s"},
+		{DiffInsert, "ed"},
+		{DiffEqual, " over "},
+		{DiffDelete, "the"},
+		{DiffInsert, "a"},
+		{DiffEqual, " lazy"},
+		{DiffInsert, "old dog"}}
+	text1 := DiffText1(diffs)
+	delta := DiffToBinaryDelta(diffs)
+	assert.Equal(t, "=\x04-\x01+\x02ed=\x06-\x03+\x01a=\x05+\x07old dog",
+		string(delta), "")
+	got, err := DiffFromBinaryDelta(text1, delta)
+	assert.Nil(t, err, "")
+	assert.Equal(t, diffs, got, "")
+
+	// Non-ASCII and invalid UTF-8 text is kept as is.
+	diffs = []Diff{
+		{DiffEqual, "ڀ \x00 \t %"},
+		{DiffDelete, "\x81\x82"},
+		{DiffInsert, "ځ \x01 \n ^\xff"}}
+	delta = DiffToBinaryDelta(diffs)
+	assert.Equal(t, "=\x08-\x02+\x09ځ \x01 \n ^\xff", string(delta), "")
+	got, err = DiffFromBinaryDelta(DiffText1(diffs), delta)
+	assert.Nil(t, err, "")
+	assert.Equal(t, diffs, got, "")
+
+	// Long texts have longer lengths.
+	long := string(make([]byte, 300))
+	delta = DiffToBinaryDelta([]Diff{{DiffEqual, long}})
+	assert.Equal(t, []byte{'=', 0xac, 0x02}, delta, "")
+
+	// Null case.
+	assert.Equal(t, []byte{}, DiffToBinaryDelta(nil), "")
+	got, err = DiffFromBinaryDelta("", nil)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []Diff{}, got, "")
+
+	// Errors.
+	_, err = DiffFromBinaryDelta("jumps!", []byte("=\x05"))
+	assert.NotNil(t, err, "Delta too short.")
+	_, err = DiffFromBinaryDelta("ab", []byte("=\x03"))
+	assert.NotNil(t, err, "Delta too long.")
+	_, err = DiffFromBinaryDelta("", []byte("+\x03ab"))
+	assert.NotNil(t, err, "Truncated insertion.")
+	_, err = DiffFromBinaryDelta("ab", []byte("=\x80"))
+	assert.NotNil(t, err, "Truncated length.")
+	_, err = DiffFromBinaryDelta("ab", []byte("*\x02"))
+	assert.NotNil(t, err, "Invalid operation.")
+}
@@ -22,21 +22,28 @@ func matchBitapScore(
 	return accuracy + (proximity / float64(dmp.MatchDistance))
 }
 
-func matchBitap(dmp *DMP, text, pattern string, loc int) int {
-	// Initialise the alphabet.
-	s := MatchAlphabet(pattern)
+// matchBitap locates the best instance of pattern in text near loc, using
+// mi, if not nil, to find exact matches.
+func matchBitap(
+	dmp *DMP, text, pattern string, loc int, mi *matchIndex,
+) int {
+	// Initialise the alphabet, as a table for quick lookups.
+	var s [256]int
+	for b, mask := range MatchAlphabet(pattern) {
+		s[b] = mask
+	}
 
 	// Highest score beyond which we give up.
 	var score_threshold float64 = dmp.MatchThreshold
 	// Is there a nearby exact match? (speedup)
-	bestLoc := indexOf(text, pattern, loc)
+	bestLoc := mi.indexOf(text, pattern, loc)
 	if bestLoc != -1 {
 		score_threshold = math.Min(
 			matchBitapScore(dmp, 0, bestLoc, loc, pattern),
 			score_threshold,
 		)
 		// What about in the other direction? (speedup)
-		bestLoc = lastIndexOf(text, pattern, loc+len(pattern))
+		bestLoc = mi.lastIndexOf(text, pattern, loc+len(pattern))
 		if bestLoc != -1 {
 			score_threshold = math.Min(
 				matchBitapScore(dmp, 0, bestLoc, loc, pattern),
@@ -52,7 +59,12 @@ func matchBitap(dmp *DMP, text, pattern string, loc int) int {
 	var binMin, binMid int
 	bin_max := len(pattern) + len(text)
 	lastRD := []int{}
-	for d := 0; d < len(pattern); d++ {
+	lastBase := 0
+	for d := 0; d < len(pattern) && !isDone(dmp.done); d++ {
+		if !dmp.budget.step() {
+			dmp.budget.cut()
+			break
+		}
 		// Scan for the best match; each iteration allows for one more error.
 		// Run a binary search to determine how far from 'loc' we can stray at
 		// this error level.
@@ -72,29 +84,33 @@ func matchBitap(dmp *DMP, text, pattern string, loc int) int {
 		start := max(1, loc-binMid+1)
 		finish := min(loc+binMid, len(text)) + len(pattern)
 
-		rd := make([]int, finish+2)
-		rd[finish+1] = (1 << uint(d)) - 1
+		// rd only covers the part of the text that can be scanned, from
+		// base on.  The scan may go below start when a match is found past
+		// loc, but never below 2*loc-finish+1.
+		base := max(1, min(start, 2*loc-finish+1))
+		rd := make([]int, finish+2-base)
+		rd[finish+1-base] = (1 << uint(d)) - 1
 
 		for j := finish; j >= start; j-- {
 			var charMatch int
 			if len(text) <= j-1 {
 				// Out of range.
 				charMatch = 0
-			} else if _, ok := s[text[j-1]]; !ok {
-				charMatch = 0
 			} else {
 				charMatch = s[text[j-1]]
 			}
 
+			i := j - base
 			if d == 0 {
 				// First pass: exact match.
-				rd[j] = ((rd[j+1] << 1) | 1) & charMatch
+				rd[i] = ((rd[i+1] << 1) | 1) & charMatch
 			} else {
 				// Subsequent passes: fuzzy match.
-				rd[j] = ((rd[j+1]<<1)|1)&charMatch |
-					(((lastRD[j+1] | lastRD[j]) << 1) | 1) | lastRD[j+1]
+				last := lastRD[j-lastBase : j-lastBase+2]
+				rd[i] = ((rd[i+1]<<1)|1)&charMatch |
+					(((last[1] | last[0]) << 1) | 1) | last[1]
 			}
-			if (rd[j] & matchmask) != 0 {
+			if (rd[i] & matchmask) != 0 {
 				score := matchBitapScore(dmp, d, j-1, loc, pattern)
 				// This match will almost certainly be better than any
 				// existing match.  But check anyway.
@@ -118,6 +134,7 @@ func matchBitap(dmp *DMP, text, pattern string, loc int) int {
 			break
 		}
 		lastRD = rd
+		lastBase = base
 	}
 	return bestLoc
 }
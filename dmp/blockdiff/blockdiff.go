@@ -0,0 +1,98 @@
+// Package blockdiff computes rsync-style deltas between large files.
+//
+// The receiver, which holds the old version, computes a Signature of it: a
+// weak rolling hash and a strong hash per fixed-size block.  The sender
+// runs Delta over the new version against that signature, producing
+// copies of blocks the receiver already has and literal data for the rest.
+// The receiver rebuilds the new version with Patch.  Unlike the dmp
+// package, nothing needs to hold both versions in memory.
+package blockdiff
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+)
+
+// DefaultBlockSize is a reasonable block size for files of a few megabytes.
+const DefaultBlockSize = 2048
+
+// BlockSig is the signature of one block of the old version.
+type BlockSig struct {
+	Weak   uint32
+	Strong [sha256.Size]byte
+	// Length is the block size, except for a shorter last block.
+	Length int
+}
+
+// Signature describes the old version of a file, block by block.
+type Signature struct {
+	BlockSize int
+	Blocks    []BlockSig
+}
+
+// NewSignature reads r to the end and returns its signature.
+func NewSignature(r io.Reader, blockSize int) (*Signature, error) {
+	if blockSize <= 0 {
+		return nil, fmt.Errorf("Invalid block size: %d", blockSize)
+	}
+	sig := &Signature{BlockSize: blockSize}
+	buf := make([]byte, blockSize)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			sig.Blocks = append(sig.Blocks, BlockSig{
+				Weak:   newRollingHash(buf[:n]).sum(),
+				Strong: sha256.Sum256(buf[:n]),
+				Length: n,
+			})
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return sig, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// OpKind tags what an Op does.
+type OpKind int8
+
+const (
+	// OpCopy copies Count blocks of the old version, starting at Block.
+	OpCopy OpKind = iota
+	// OpLiteral inserts Data.
+	OpLiteral
+)
+
+// Op is one instruction of a delta.
+type Op struct {
+	Kind  OpKind
+	Block int
+	Count int
+	Data  []byte
+}
+
+// Patch writes the new version to w, reading copied blocks from base,
+// the old version the signature was computed from.
+func Patch(base io.ReaderAt, blockSize int, ops []Op, w io.Writer) error {
+	for _, op := range ops {
+		if op.Kind == OpLiteral {
+			if _, err := w.Write(op.Data); err != nil {
+				return err
+			}
+			continue
+		}
+		off := int64(op.Block) * int64(blockSize)
+		n := int64(op.Count) * int64(blockSize)
+		copied, err := io.Copy(w, io.NewSectionReader(base, off, n))
+		if err != nil {
+			return err
+		}
+		if copied == 0 && n > 0 {
+			return fmt.Errorf("Block out of range: %d", op.Block)
+		}
+	}
+	return nil
+}
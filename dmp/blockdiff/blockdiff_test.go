@@ -0,0 +1,88 @@
+package blockdiff
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func roundTrip(t *testing.T, old, cur []byte, blockSize int) []Op {
+	sig, err := NewSignature(bytes.NewReader(old), blockSize)
+	assert.Nil(t, err, "")
+	ops, err := Delta(sig, bytes.NewReader(cur))
+	assert.Nil(t, err, "")
+	var out bytes.Buffer
+	err = Patch(bytes.NewReader(old), blockSize, ops, &out)
+	assert.Nil(t, err, "")
+	assert.Equal(t, string(cur), out.String(), "Round trip.")
+	return ops
+}
+
+func literalBytes(ops []Op) int {
+	n := 0
+	for _, op := range ops {
+		n += len(op.Data)
+	}
+	return n
+}
+
+func TestRollingHash(t *testing.T) {
+	data := []byte("The quick brown fox jumps over the lazy dog.")
+	h := newRollingHash(data[:8])
+	for i := 8; i < len(data); i++ {
+		h.roll(8, data[i-8], data[i])
+		assert.Equal(t, newRollingHash(data[i-7:i+1]).sum(), h.sum(), "")
+	}
+	h.shrink(8, data[len(data)-8])
+	assert.Equal(t, newRollingHash(data[len(data)-7:]).sum(), h.sum(), "")
+}
+
+func TestDelta(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	old := make([]byte, 10000)
+	rnd.Read(old)
+
+	ops := roundTrip(t, old, old, 64)
+	assert.Equal(t, []Op{{Kind: OpCopy, Block: 0, Count: 157}}, ops,
+		"Identical files are one copy, including the short last block.")
+
+	// Insert and delete in the middle.
+	cur := append([]byte{}, old[:3000]...)
+	cur = append(cur, []byte("inserted text")...)
+	cur = append(cur, old[3100:]...)
+	ops = roundTrip(t, old, cur, 64)
+	assert.True(t, literalBytes(ops) < 200, "Only the edited region is sent.")
+
+	// Unrelated content.
+	other := make([]byte, 500)
+	rnd.Read(other)
+	ops = roundTrip(t, old, other, 64)
+	assert.Equal(t, 500, literalBytes(ops), "")
+	for _, op := range ops {
+		assert.True(t, len(op.Data) <= 64, "Literals are chunked.")
+	}
+
+	roundTrip(t, nil, old[:100], 64)
+	roundTrip(t, old[:100], nil, 64)
+	roundTrip(t, nil, nil, 64)
+	roundTrip(t, old[:10], append(old[:10:10], old[:10]...), 64)
+}
+
+func TestDeltaErrors(t *testing.T) {
+	_, err := NewSignature(bytes.NewReader(nil), 0)
+	assert.NotNil(t, err, "Invalid block size.")
+
+	sig, _ := NewSignature(bytes.NewReader([]byte("abcdefgh")), 4)
+	stop := errors.New("stop")
+	err = DeltaFunc(sig, bytes.NewReader([]byte("abcdefgh")),
+		func(Op) error { return stop })
+	assert.Equal(t, stop, err, "Errors of the callback are returned.")
+
+	var out bytes.Buffer
+	err = Patch(bytes.NewReader([]byte("abcd")), 4,
+		[]Op{{Kind: OpCopy, Block: 5, Count: 1}}, &out)
+	assert.NotNil(t, err, "Block out of range.")
+}
@@ -0,0 +1,185 @@
+package blockdiff
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"io"
+)
+
+// rollingHash is the weak checksum of rsync: a is the sum of the bytes of
+// the window, b the sum of the bytes weighted by their distance to the end
+// of the window.  Both are kept modulo 2^16 on extraction.
+type rollingHash struct {
+	a, b uint32
+}
+
+func newRollingHash(data []byte) rollingHash {
+	var h rollingHash
+	n := uint32(len(data))
+	for i, c := range data {
+		h.a += uint32(c)
+		h.b += (n - uint32(i)) * uint32(c)
+	}
+	return h
+}
+
+func (h rollingHash) sum() uint32 {
+	return h.a&0xffff | h.b<<16
+}
+
+// roll moves a window of n bytes one byte forward, dropping out and adding
+// in.
+func (h *rollingHash) roll(n int, out, in byte) {
+	h.a += uint32(in) - uint32(out)
+	h.b += h.a - uint32(n)*uint32(out)
+}
+
+// shrink drops the first byte out of a window of n bytes.
+func (h *rollingHash) shrink(n int, out byte) {
+	h.a -= uint32(out)
+	h.b -= uint32(n) * uint32(out)
+}
+
+// Delta reads the new version from r and returns the ops rebuilding it
+// from the old version described by sig.
+func Delta(sig *Signature, r io.Reader) ([]Op, error) {
+	ops := []Op{}
+	err := DeltaFunc(sig, r, func(op Op) error {
+		ops = append(ops, op)
+		return nil
+	})
+	return ops, err
+}
+
+// DeltaFunc is like Delta but hands each op to fn as soon as it is known,
+// so that the delta of a large file need not be held in memory.  Literal
+// runs are handed over in chunks of at most the block size.  Data slices
+// are not reused.
+func DeltaFunc(sig *Signature, r io.Reader, fn func(Op) error) error {
+	blocks := map[uint32][]int{}
+	for i, b := range sig.Blocks {
+		blocks[b.Weak] = append(blocks[b.Weak], i)
+	}
+	bs := sig.BlockSize
+	br := bufio.NewReader(r)
+
+	var pending Op
+	flush := func() error {
+		if pending.Count == 0 && len(pending.Data) == 0 {
+			return nil
+		}
+		op := pending
+		pending = Op{}
+		return fn(op)
+	}
+	emitCopy := func(block int) error {
+		if pending.Kind == OpCopy && pending.Count > 0 &&
+			pending.Block+pending.Count == block {
+			pending.Count++
+			return nil
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		pending = Op{Kind: OpCopy, Block: block, Count: 1}
+		return nil
+	}
+	emitByte := func(c byte) error {
+		if pending.Kind != OpLiteral || len(pending.Data) >= bs {
+			if err := flush(); err != nil {
+				return err
+			}
+			pending = Op{Kind: OpLiteral, Data: make([]byte, 0, bs)}
+		}
+		pending.Data = append(pending.Data, c)
+		return nil
+	}
+
+	// The window is buf[start:]; buf is compacted as the window moves.
+	buf := make([]byte, 0, 2*bs)
+	start := 0
+	fill := func() error {
+		buf = buf[:bs]
+		n, err := io.ReadFull(br, buf)
+		buf = buf[:n]
+		start = 0
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		return err
+	}
+
+	if err := fill(); err != nil {
+		return err
+	}
+	h := newRollingHash(buf)
+	eof := len(buf) < bs
+	for len(buf) > start {
+		window := buf[start:]
+		if block := findBlock(sig, blocks, h.sum(), window); block >= 0 {
+			if err := emitCopy(block); err != nil {
+				return err
+			}
+			if eof {
+				break
+			}
+			if err := fill(); err != nil {
+				return err
+			}
+			h = newRollingHash(buf)
+			eof = len(buf) < bs
+			continue
+		}
+
+		out := window[0]
+		if err := emitByte(out); err != nil {
+			return err
+		}
+		start++
+		if !eof {
+			in, err := br.ReadByte()
+			if err == io.EOF {
+				eof = true
+			} else if err != nil {
+				return err
+			} else {
+				if start >= bs {
+					buf = append(buf[:0], buf[start:]...)
+					start = 0
+				}
+				buf = append(buf, in)
+				h.roll(len(window), out, in)
+				continue
+			}
+		}
+		h.shrink(len(window), out)
+	}
+	return flush()
+}
+
+// findBlock returns the index of the block of sig matching window, or -1.
+func findBlock(
+	sig *Signature, blocks map[uint32][]int, weak uint32, window []byte,
+) int {
+	candidates, ok := blocks[weak]
+	if !ok {
+		return -1
+	}
+	var strong [sha256.Size]byte
+	hashed := false
+	for _, i := range candidates {
+		b := sig.Blocks[i]
+		if b.Length != len(window) {
+			continue
+		}
+		if !hashed {
+			strong = sha256.Sum256(window)
+			hashed = true
+		}
+		if bytes.Equal(b.Strong[:], strong[:]) {
+			return i
+		}
+	}
+	return -1
+}
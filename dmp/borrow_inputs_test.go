@@ -0,0 +1,74 @@
+package dmp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+// scribbler is a Differ that overwrites its inputs.
+type scribbler struct {
+	a []rune
+}
+
+func (s *scribbler) DiffRunes(a, b []rune, deadline time.Time) []Diff {
+	s.a = a
+	for i := range a {
+		a[i] = 'x'
+	}
+	return []Diff{{DiffDelete, string(a)}, {DiffInsert, string(b)}}
+}
+
+func TestCleanupInputsUntouched(t *testing.T) {
+	diffs := func() []Diff {
+		return []Diff{
+			{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffEqual, "c"},
+			{DiffInsert, "d"}, {DiffDelete, "e"}, {DiffEqual, "f"},
+			{DiffInsert, "g"}, {DiffEqual, "The c"}, {DiffInsert, "at c"},
+			{DiffEqual, "ame."}}
+	}
+	dmp := New()
+	dmp.DiffFragmentGap = 1
+	for name, f := range map[string]func([]Diff) []Diff{
+		"Merge":             DiffCleanupMerge,
+		"Semantic":          DiffCleanupSemantic,
+		"SemanticLossless":  DiffCleanupSemanticLossless,
+		"dmp.Merge":         dmp.DiffCleanupMerge,
+		"dmp.Semantic":      dmp.DiffCleanupSemantic,
+		"dmp.Lossless":      dmp.DiffCleanupSemanticLossless,
+		"dmp.Efficiency":    dmp.DiffCleanupEfficiency,
+		"dmp.Fragments":     dmp.DiffCleanupFragments,
+		"dmp.FragmentsNone": New().DiffCleanupFragments,
+	} {
+		in := diffs()
+		out := f(in)
+		assert.Equal(t, diffs(), in, name)
+		if len(out) > 0 {
+			out[0].Text = "changed"
+			assert.Equal(t, diffs(), in, name)
+		}
+	}
+}
+
+func TestBorrowInputs(t *testing.T) {
+	dmp := New()
+	dmp.BorrowInputs = true
+	in := append(make([]Diff, 0, 4),
+		Diff{DiffEqual, "a"}, Diff{DiffEqual, "b"}, Diff{DiffInsert, "c"})
+	out := dmp.DiffCleanupMerge(in)
+	assert.Equal(t, []Diff{{DiffEqual, "ab"}, {DiffInsert, "c"}}, out, "")
+	assert.Equal(t, "ab", in[0].Text, "Cleaned up in place.")
+
+	s := &scribbler{}
+	a := []rune("abc")
+	dmp.Differ = s
+	dmp.DiffMainRunes(a, []rune("abd"), false)
+	assert.Equal(t, "xxx", string(a), "The Differ got the caller's slice.")
+
+	dmp.BorrowInputs = false
+	a = []rune("abc")
+	diffs := dmp.DiffMainRunes(a, []rune("abd"), false)
+	assert.Equal(t, "abc", string(a), "The Differ got a copy.")
+	assert.Equal(t, "xxx", DiffText1(diffs), "")
+}
@@ -0,0 +1,109 @@
+package dmp
+
+import (
+	"math/bits"
+	"time"
+	"unicode/utf8"
+)
+
+// Size of an int in bytes, for memory estimates.
+const intSize = bits.UintSize / 8
+
+// Budget limits the resources of one diff, so that services diffing texts
+// for many users can share a machine fairly.  Zero fields are unlimited.
+// Once a limit is reached, the parts of the diff left are reported as
+// plain deletions and insertions, as after DiffTimeout.
+type Budget struct {
+	// Wall time of the diff, on top of DiffTimeout.
+	MaxTime time.Duration
+
+	// Steps of the bisection, summed over all bisections.  Each step
+	// extends the search by one edit in both directions, or by one
+	// character of the longer text with AlgorithmHirschberg.  The Bitap
+	// search of a match counts a step per number of errors it allows.
+	MaxIterations int
+
+	// Estimated memory in bytes: the runes of the texts and the largest
+	// working arrays of the bisection.
+	MaxMemory int
+}
+
+// BudgetUsage reports the resources a diff consumed.
+type BudgetUsage struct {
+	Time       time.Duration
+	Iterations int
+	// Estimated peak memory in bytes, as in Budget.
+	Memory int
+	// Exceeded is true if a limit of the budget or DiffTimeout cut the
+	// diff short, so that it may not be minimal.
+	Exceeded bool
+}
+
+// budgetState tracks the usage of a Budget during a diff.  A nil
+// *budgetState is unlimited and tracks nothing.
+type budgetState struct {
+	Budget
+	usage BudgetUsage
+	// Memory of the texts, which the working arrays come on top of.
+	base int
+}
+
+// step counts a step of the bisection, and tells whether the budget
+// allows it.
+func (b *budgetState) step() bool {
+	if b == nil {
+		return true
+	}
+	if b.MaxIterations > 0 && b.usage.Iterations >= b.MaxIterations {
+		return false
+	}
+	b.usage.Iterations++
+	return true
+}
+
+// alloc tells whether the budget allows working arrays of n bytes, and
+// records them.
+func (b *budgetState) alloc(n int) bool {
+	if b == nil {
+		return true
+	}
+	if b.MaxMemory > 0 && b.base+n > b.MaxMemory {
+		b.usage.Exceeded = true
+		return false
+	}
+	b.usage.Memory = max(b.usage.Memory, b.base+n)
+	return true
+}
+
+// cut records that the diff was cut short.
+func (b *budgetState) cut() {
+	if b != nil {
+		b.usage.Exceeded = true
+	}
+}
+
+// DiffMainBudget is like DiffMain, but stays within budget and reports
+// the resources used.  A Differ is only held to MaxTime.
+func (dmp *DMP) DiffMainBudget(
+	s1, s2 string, checkLines bool, budget Budget,
+) ([]Diff, BudgetUsage) {
+	start := time.Now()
+	end := deadline(dmp.DiffTimeout)
+	if budget.MaxTime > 0 && start.Add(budget.MaxTime).Before(end) {
+		end = start.Add(budget.MaxTime)
+	}
+	e := *dmp
+	e.budget = &budgetState{Budget: budget}
+	if e.Differ == nil {
+		runes := utf8.RuneCountInString(s1) + utf8.RuneCountInString(s2)
+		if e.DiffBytes {
+			runes = len(s1) + len(s2)
+		}
+		e.budget.base = runes * 4
+		e.budget.usage.Memory = e.budget.base
+	}
+	diffs := e.diffDispatch(s1, s2, checkLines, end)
+	usage := e.budget.usage
+	usage.Time = time.Since(start)
+	return diffs, usage
+}
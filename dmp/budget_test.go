@@ -0,0 +1,66 @@
+package dmp
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffMainBudget(t *testing.T) {
+	dmp := New()
+	dmp.DiffTimeout = 0
+	diffs, usage := dmp.DiffMainBudget(
+		"The cat sat.", "The hat sits.", false, Budget{},
+	)
+	assert.Equal(t,
+		dmp.DiffMain("The cat sat.", "The hat sits.", false), diffs, "")
+	assert.False(t, usage.Exceeded, "")
+	assert.True(t, usage.Iterations > 0, "")
+	assert.True(t, usage.Memory >= 25*4, "Counts the runes of the texts.")
+
+	rnd := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		b := make([]byte, 2000)
+		for i := range b {
+			b[i] = "abcd"[rnd.Intn(4)]
+		}
+		return string(b)
+	}
+	text1, text2 := randomText(), randomText()
+	full, usage := dmp.DiffMainBudget(text1, text2, false, Budget{})
+	assert.False(t, usage.Exceeded, "")
+
+	// Iterations.
+	budget := Budget{MaxIterations: usage.Iterations / 10}
+	diffs, limited := dmp.DiffMainBudget(text1, text2, false, budget)
+	assert.True(t, limited.Exceeded, "")
+	assert.Equal(t, budget.MaxIterations, limited.Iterations, "")
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.True(t, DiffLevenshtein(diffs) > DiffLevenshtein(full), "")
+
+	// Memory: the first bisection needs more than the texts.
+	diffs, limited = dmp.DiffMainBudget(
+		text1, text2, false, Budget{MaxMemory: 4 * 4000},
+	)
+	assert.True(t, limited.Exceeded, "")
+	assert.Equal(t, 0, limited.Iterations, "")
+	assert.Equal(t, []Diff{
+		{DiffEqual, "b"}, {DiffDelete, text1[1:]}, {DiffInsert, text2[1:]},
+	}, diffs, "")
+
+	// Time.
+	long1, long2 := text1, text2
+	for i := 0; i < 5; i++ {
+		long1 += randomText()
+		long2 += randomText()
+	}
+	start := time.Now()
+	_, limited = dmp.DiffMainBudget(
+		long1, long2, false, Budget{MaxTime: 10 * time.Millisecond},
+	)
+	assert.True(t, limited.Exceeded, "")
+	assert.True(t, time.Since(start) < time.Second, "")
+}
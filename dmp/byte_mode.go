@@ -0,0 +1,43 @@
+package dmp
+
+import (
+	"time"
+)
+
+// diffBytes diffs s1 and s2 byte by byte, for DiffBytes.  Each byte is
+// diffed as the rune of the same value, which keeps the engine, and any
+// Differ, working on runes; the texts of the diffs are then turned back
+// into the original bytes.
+func (dmp *DMP) diffBytes(
+	s1, s2 string, checkLines bool, deadline time.Time,
+) []Diff {
+	r1, r2 := byteRunes(s1), byteRunes(s2)
+	var diffs []Diff
+	if dmp.Differ != nil {
+		diffs = dmp.Differ.DiffRunes(r1, r2, deadline)
+	} else {
+		diffs = dmp.diffMainRunes(r1, r2, checkLines, deadline)
+	}
+	for i, d := range diffs {
+		diffs[i].Text = runeBytes(d.Text)
+	}
+	return diffs
+}
+
+// byteRunes returns a rune for each byte of s.
+func byteRunes(s string) []rune {
+	rs := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		rs[i] = rune(s[i])
+	}
+	return rs
+}
+
+// runeBytes undoes byteRunes on the text of a diff.
+func runeBytes(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		b = append(b, byte(r))
+	}
+	return string(b)
+}
@@ -0,0 +1,64 @@
+package dmp
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffBytes(t *testing.T) {
+	dmp := New()
+	dmp.DiffBytes = true
+	// Latin-1 against UTF-8.
+	text1 := "caf\xe9 cr\xe8me\nna\xefve\n"
+	text2 := "café crème\nnaïve\n"
+	diffs := dmp.DiffMain(text1, text2, false)
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.Equal(t, []Diff{
+		{DiffEqual, "caf"}, {DiffDelete, "\xe9"}, {DiffInsert, "é"},
+		{DiffEqual, " cr"}, {DiffDelete, "\xe8"}, {DiffInsert, "è"},
+		{DiffEqual, "me\nna"}, {DiffDelete, "\xef"}, {DiffInsert, "ï"},
+		{DiffEqual, "ve\n"},
+	}, diffs, "")
+
+	// Rune mode reads both invalid bytes as U+FFFD.
+	dmp.DiffBytes = false
+	diffs = dmp.DiffMain("a\xe9", "a\xe8", false)
+	assert.Equal(t, "a�", DiffText1(diffs), "")
+
+	dmp.DiffBytes = true
+	rnd := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		b := make([]byte, 50+rnd.Intn(300))
+		for i := range b {
+			b[i] = "ab\n\xc3\xa9\xff\x80"[rnd.Intn(7)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 50; i++ {
+		text1, text2 := randomText(), randomText()
+		diffs := dmp.DiffMain(text1, text2, i%2 == 0)
+		assert.Equal(t, text1, DiffText1(diffs), "")
+		assert.Equal(t, text2, DiffText2(diffs), "")
+
+		ps := dmp.PatchMake(text1, text2)
+		ps, err := PatchFromText(PatchToText(ps))
+		assert.Nil(t, err, "")
+		patched, _ := dmp.Apply(ps, text1)
+		assert.Equal(t, text2, patched, "")
+	}
+}
+
+func TestCleanupMergeMultiByte(t *testing.T) {
+	// Common prefixes and suffixes are factored out in whole runes.
+	assert.Equal(t,
+		[]Diff{{DiffEqual, "é"}, {DiffDelete, "a"}, {DiffInsert, "b"}},
+		diffCleanupMerge([]Diff{{DiffDelete, "éa"}, {DiffInsert, "éb"}}),
+		"")
+	assert.Equal(t,
+		[]Diff{{DiffEqual, "a"}, {DiffDelete, "é"}, {DiffInsert, "è"}},
+		diffCleanupMerge([]Diff{{DiffDelete, "aé"}, {DiffInsert, "aè"}}),
+		"")
+}
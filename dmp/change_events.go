@@ -0,0 +1,94 @@
+package dmp
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChangeEvent is one diff as a flat record, for storing diffs in the
+// change log table of a database.  Seq orders the events of a revision.
+// Offset is where the event applies when the events of a revision are
+// applied in order to the previous revision: equalities are skipped,
+// deletions removed and insertions inserted at Offset, so it is also the
+// offset of the event in the new revision.  Offsets and lengths are in
+// bytes.
+type ChangeEvent struct {
+	Doc    string    `json:"doc"`
+	Rev    int       `json:"rev"`
+	Seq    int       `json:"seq"`
+	Op     Operation `json:"op"`
+	Offset int       `json:"offset"`
+	Length int       `json:"length"`
+	Text   string    `json:"text"`
+}
+
+// ToChangeEvents turns the diffs making revision rev of document docID
+// into change events.
+func ToChangeEvents(diffs []Diff, docID string, rev int) []ChangeEvent {
+	diffs = withoutMoves(diffs)
+	events := make([]ChangeEvent, 0, len(diffs))
+	offset := 0
+	for _, d := range diffs {
+		if len(d.Text) == 0 {
+			continue
+		}
+		events = append(events, ChangeEvent{
+			Doc:    docID,
+			Rev:    rev,
+			Seq:    len(events),
+			Op:     d.Type,
+			Offset: offset,
+			Length: len(d.Text),
+			Text:   d.Text,
+		})
+		if d.Type != DiffDelete {
+			offset += len(d.Text)
+		}
+	}
+	return events
+}
+
+// FromChangeEvents rebuilds the diffs of one revision from its change
+// events, in any order.  It returns an error if the events belong to
+// different revisions or do not fit together.
+func FromChangeEvents(events []ChangeEvent) ([]Diff, error) {
+	sorted := append([]ChangeEvent{}, events...)
+	sort.Sort(eventsBySeq(sorted))
+	diffs := make([]Diff, 0, len(sorted))
+	offset := 0
+	for i, e := range sorted {
+		if e.Doc != sorted[0].Doc || e.Rev != sorted[0].Rev {
+			return nil, fmt.Errorf(
+				"Events of different revisions: %s@%d and %s@%d",
+				sorted[0].Doc, sorted[0].Rev, e.Doc, e.Rev,
+			)
+		}
+		if e.Seq != i {
+			return nil, fmt.Errorf("Missing event %d of %s@%d",
+				i, e.Doc, e.Rev)
+		}
+		if e.Offset != offset || e.Length != len(e.Text) {
+			return nil, fmt.Errorf(
+				"Event %d of %s@%d at %d+%d does not fit at %d+%d",
+				e.Seq, e.Doc, e.Rev, e.Offset, e.Length,
+				offset, len(e.Text),
+			)
+		}
+		switch e.Op {
+		case DiffEqual, DiffInsert:
+			offset += e.Length
+		case DiffDelete:
+		default:
+			return nil, fmt.Errorf("Invalid operation %d in event %d of %s@%d",
+				e.Op, e.Seq, e.Doc, e.Rev)
+		}
+		diffs = append(diffs, Diff{e.Op, e.Text})
+	}
+	return diffs, nil
+}
+
+type eventsBySeq []ChangeEvent
+
+func (s eventsBySeq) Len() int           { return len(s) }
+func (s eventsBySeq) Less(i, j int) bool { return s[i].Seq < s[j].Seq }
+func (s eventsBySeq) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,45 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestChangeEvents(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "The "}, {DiffDelete, "quick"}, {DiffInsert, "slow"},
+		{DiffEqual, " fox"}, {DiffInsert, "!"},
+	}
+	events := ToChangeEvents(diffs, "doc1", 7)
+	assert.Equal(t, []ChangeEvent{
+		{"doc1", 7, 0, DiffEqual, 0, 4, "The "},
+		{"doc1", 7, 1, DiffDelete, 4, 5, "quick"},
+		{"doc1", 7, 2, DiffInsert, 4, 4, "slow"},
+		{"doc1", 7, 3, DiffEqual, 8, 4, " fox"},
+		{"doc1", 7, 4, DiffInsert, 12, 1, "!"},
+	}, events, "")
+
+	// Storage may return the events in any order.
+	shuffled := []ChangeEvent{events[3], events[0], events[4], events[2],
+		events[1]}
+	result, err := FromChangeEvents(shuffled)
+	assert.Nil(t, err, "")
+	assert.Equal(t, diffs, result, "")
+
+	result, err = FromChangeEvents(nil)
+	assert.Nil(t, err, "")
+	assert.Equal(t, 0, len(result), "")
+
+	_, err = FromChangeEvents(events[1:])
+	assert.NotNil(t, err, "Missing event.")
+
+	other := ToChangeEvents(diffs, "doc1", 8)
+	_, err = FromChangeEvents(append(events[:2:2], other[2:]...))
+	assert.NotNil(t, err, "Mixed revisions.")
+
+	bad := append([]ChangeEvent{}, events...)
+	bad[3].Offset = 9
+	_, err = FromChangeEvents(bad)
+	assert.NotNil(t, err, "Offset does not fit.")
+}
@@ -7,13 +7,19 @@ import (
 // DiffCharsToLines rehydrates the text in a diff from a string of line hashes
 // to real lines of text.
 func DiffCharsToLines(diffs []Diff, lineArray []string) []Diff {
+	diffs = withoutMoves(diffs)
 	hydrated := make([]Diff, 0, len(diffs))
 	for _, d := range diffs {
 		chars := d.Text
-		text := make([]string, len(chars))
+		text := make([]string, 0, len(chars))
 
-		for i, r := range chars {
-			text[i] = lineArray[r]
+		for _, r := range chars {
+			// The runes skip the surrogates.
+			id := int(r)
+			if id >= surrogateEnd {
+				id -= surrogateEnd - surrogateMin
+			}
+			text = append(text, lineArray[id])
 		}
 
 		d.Text = strings.Join(text, "")
@@ -100,7 +100,7 @@ func diffCleanupEfficiency(diffs []Diff, editCost int) []Diff {
 	}
 
 	if changes {
-		diffs = DiffCleanupMerge(diffs)
+		diffs = diffCleanupMerge(diffs)
 	}
 
 	return diffs
@@ -0,0 +1,68 @@
+package dmp
+
+// CleanupFixpoint applies cleanup to diffs until the diffs stop changing,
+// at most maxIter times, so that caches keyed on cleaned diffs see one
+// result.  A cleanup that oscillates between several diffs stops at the
+// one with the fewest diffs, ties broken by comparing their operations and
+// texts in order, which is the same whichever of them it started from.
+// The result can be cleaned again by CleanupFixpoint without change.
+// Returns false if maxIter was reached first, in which case the last
+// diffs are returned.  The given slice is not modified, and cleanup is
+// given a copy of the diffs each time.
+func CleanupFixpoint(
+	diffs []Diff, cleanup func([]Diff) []Diff, maxIter int,
+) ([]Diff, bool) {
+	seen := [][]Diff{diffs}
+	for i := 0; i < maxIter; i++ {
+		next := cleanup(copyDiffs(diffs))
+		for j, prev := range seen {
+			if diffsEqual(prev, next) {
+				return firstDiffs(seen[j:]), true
+			}
+		}
+		seen = append(seen, next)
+		diffs = next
+	}
+	return diffs, false
+}
+
+// diffsEqual tells whether a and b are the same diffs.
+func diffsEqual(a, b []Diff) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// firstDiffs returns the first of cands in the order of CleanupFixpoint.
+func firstDiffs(cands [][]Diff) []Diff {
+	best := cands[0]
+	for _, c := range cands[1:] {
+		if diffsLess(c, best) {
+			best = c
+		}
+	}
+	return best
+}
+
+// diffsLess orders diffs by their number, then by the operations and
+// texts of their diffs in order.
+func diffsLess(a, b []Diff) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	for i := range a {
+		if a[i].Type != b[i].Type {
+			return a[i].Type < b[i].Type
+		}
+		if a[i].Text != b[i].Text {
+			return a[i].Text < b[i].Text
+		}
+	}
+	return false
+}
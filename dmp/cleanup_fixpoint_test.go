@@ -0,0 +1,70 @@
+package dmp
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+// randomDiffs returns up to n diffs of short random texts, which need not
+// be normalized.
+func randomDiffs(r *rand.Rand, n int) []Diff {
+	diffs := make([]Diff, r.Intn(n+1))
+	for i := range diffs {
+		text := make([]rune, r.Intn(8))
+		for j := range text {
+			text[j] = []rune("ab .\n語")[r.Intn(6)]
+		}
+		diffs[i] = Diff{Operation(r.Intn(3) - 1), string(text)}
+	}
+	return diffs
+}
+
+func TestCleanupIdempotent(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 20000; i++ {
+		diffs := randomDiffs(r, 8)
+		text1, text2 := DiffText1(diffs), DiffText2(diffs)
+		for _, cleanup := range []func([]Diff) []Diff{
+			DiffCleanupMerge, DiffCleanupSemantic,
+		} {
+			once := cleanup(diffs)
+			assert.Equal(t, text1, DiffText1(once), "")
+			assert.Equal(t, text2, DiffText2(once), "")
+			assert.Equal(t, once, cleanup(once), "")
+		}
+	}
+}
+
+func TestCleanupFixpoint(t *testing.T) {
+	a := []Diff{{DiffDelete, "a"}, {DiffInsert, "b"}}
+	b := []Diff{{DiffInsert, "b"}, {DiffDelete, "a"}}
+	swap := func(diffs []Diff) []Diff {
+		diffs[0], diffs[1] = diffs[1], diffs[0]
+		return diffs
+	}
+	// Oscillations stop at the same diffs from either end.
+	got, ok := CleanupFixpoint(a, swap, 10)
+	assert.True(t, ok, "")
+	assert.Equal(t, a, got, "")
+	got, ok = CleanupFixpoint(b, swap, 10)
+	assert.True(t, ok, "")
+	assert.Equal(t, a, got, "")
+	assert.Equal(t, []Diff{{DiffDelete, "a"}, {DiffInsert, "b"}}, a, "")
+
+	grow := func(diffs []Diff) []Diff {
+		return append(diffs, Diff{DiffEqual, "x"})
+	}
+	got, ok = CleanupFixpoint(a, grow, 3)
+	assert.False(t, ok, "")
+	assert.Equal(t, 5, len(got), "")
+
+	got, ok = CleanupFixpoint(a, DiffCleanupMerge, 10)
+	assert.True(t, ok, "")
+	assert.Equal(t, a, got, "")
+
+	// One pass of the semantic cleanup leaves an equality the next removes.
+	got = DiffCleanupSemantic([]Diff{{DiffDelete, ".a"}, {DiffInsert, ".."}})
+	assert.Equal(t, got, DiffCleanupSemantic(got), "")
+}
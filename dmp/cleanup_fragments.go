@@ -0,0 +1,37 @@
+package dmp
+
+import (
+	"unicode/utf8"
+)
+
+// diffCleanupFragments folds equalities of at most gap characters that sit
+// between two edits into the surrounding edits, so runs of micro-edits
+// become one larger replacement.
+func diffCleanupFragments(diffs []Diff, gap int) []Diff {
+	if gap <= 0 {
+		return diffs
+	}
+
+	changes := false
+	ret := make([]Diff, 0, len(diffs))
+	for i, d := range diffs {
+		if d.Type == DiffEqual && i > 0 && i < len(diffs)-1 &&
+			diffs[i-1].Type != DiffEqual &&
+			diffs[i+1].Type != DiffEqual &&
+			utf8.RuneCountInString(d.Text) <= gap {
+			// Replace the equality with a deletion and an insertion.
+			ret = append(ret,
+				Diff{DiffDelete, d.Text},
+				Diff{DiffInsert, d.Text},
+			)
+			changes = true
+			continue
+		}
+		ret = append(ret, d)
+	}
+
+	if changes {
+		ret = diffCleanupMerge(ret)
+	}
+	return ret
+}
@@ -0,0 +1,54 @@
+package dmp
+
+import (
+	"testing"
+)
+
+func TestDiffCleanupFragments(t *testing.T) {
+	dmp := New()
+	// Disabled by default.
+	diffs := []Diff{
+		{DiffDelete, "a"},
+		{DiffInsert, "1"},
+		{DiffEqual, "x"},
+		{DiffDelete, "b"},
+		{DiffInsert, "2"}}
+	assertDiffEqual(t, diffs, dmp.DiffCleanupFragments(diffs))
+
+	dmp.DiffFragmentGap = 2
+	// Null case.
+	assertDiffEqual(t, []Diff{}, dmp.DiffCleanupFragments([]Diff{}))
+
+	// Merge across a small gap.
+	diffs = []Diff{
+		{DiffEqual, "="},
+		{DiffDelete, "a"},
+		{DiffInsert, "1"},
+		{DiffEqual, "xy"},
+		{DiffDelete, "b"},
+		{DiffInsert, "2"},
+		{DiffEqual, "z"},
+		{DiffInsert, "3"},
+		{DiffEqual, "end"}}
+	assertDiffEqual(t, []Diff{
+		{DiffEqual, "="},
+		{DiffDelete, "axybz"},
+		{DiffInsert, "1xy2z3"},
+		{DiffEqual, "end"}}, dmp.DiffCleanupFragments(diffs))
+
+	// Gaps wider than the limit are kept.
+	diffs = []Diff{
+		{DiffDelete, "a"},
+		{DiffEqual, "xyz"},
+		{DiffInsert, "1"}}
+	assertDiffEqual(t, diffs, dmp.DiffCleanupFragments(diffs))
+
+	// Characters, not bytes, are counted.
+	diffs = []Diff{
+		{DiffDelete, "a"},
+		{DiffEqual, "日本"},
+		{DiffInsert, "1"}}
+	assertDiffEqual(t, []Diff{
+		{DiffDelete, "a日本"},
+		{DiffInsert, "日本1"}}, dmp.DiffCleanupFragments(diffs))
+}
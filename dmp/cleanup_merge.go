@@ -6,8 +6,24 @@ import (
 
 // DiffCleanupMerge reorders and merges like edit sections.  Merge
 // equalities.  Any edit section can move as long as it doesn't cross an
-// equality.
+// equality.  Drop empty diffs.  Cleaning the result again changes nothing.
+// The given slice is not modified.
 func DiffCleanupMerge(ds []Diff) []Diff {
+	ds = withoutMoves(ds)
+	return diffCleanupMerge(copyDiffs(ds))
+}
+
+// diffCleanupMerge is DiffCleanupMerge working in place.
+func diffCleanupMerge(ds []Diff) []Diff {
+	// Drop empty diffs, which would split the runs of edits.
+	n := 0
+	for _, d := range ds {
+		if len(d.Text) != 0 {
+			ds[n] = d
+			n++
+		}
+	}
+	ds = ds[:n]
 	// Add a dummy entry at the end.
 	ds = append(ds, Diff{DiffEqual, ""})
 	i := 0
@@ -34,7 +50,7 @@ func DiffCleanupMerge(ds []Diff) []Diff {
 			if ndel+nins > 1 {
 				if ndel != 0 && nins != 0 {
 					// Factor out any common prefixies.
-					commonlength = DiffCommonPrefix(
+					commonlength = commonPrefixBytes(
 						insStr, delStr,
 					)
 					if commonlength != 0 {
@@ -55,7 +71,7 @@ func DiffCleanupMerge(ds []Diff) []Diff {
 						delStr = delStr[commonlength:]
 					}
 					// Factor out any common suffixies.
-					commonlength = DiffCommonSuffix(
+					commonlength = commonSuffixBytes(
 						insStr, delStr,
 					)
 					if commonlength != 0 {
@@ -68,30 +84,16 @@ func DiffCleanupMerge(ds []Diff) []Diff {
 					}
 				}
 				// Delete the offending records and add the merged ones.
-				if ndel == 0 {
-					ds = splice(ds, i-nins,
-						ndel+nins,
-						Diff{DiffInsert, insStr})
-				} else if nins == 0 {
-					ds = splice(ds, i-ndel,
-						ndel+nins,
-						Diff{DiffDelete, delStr})
-				} else {
-					ds = splice(
-						ds, i-ndel-nins,
-						ndel+nins,
-						Diff{DiffDelete, delStr},
-						Diff{DiffInsert, insStr},
-					)
-				}
-
-				i = i - ndel - nins + 1
-				if ndel != 0 {
-					i += 1
+				merged := make([]Diff, 0, 2)
+				if len(delStr) != 0 {
+					merged = append(merged, Diff{DiffDelete, delStr})
 				}
-				if nins != 0 {
-					i += 1
+				if len(insStr) != 0 {
+					merged = append(merged, Diff{DiffInsert, insStr})
 				}
+				ds = splice(ds, i-ndel-nins, ndel+nins, merged...)
+				// Go back to the equality, which may now follow another.
+				i = i - ndel - nins + len(merged)
 			} else if i != 0 && ds[i-1].Type == DiffEqual {
 				// Merge this equality with the previous one.
 				ds[i-1].Text += ds[i].Text
@@ -146,7 +148,7 @@ func DiffCleanupMerge(ds []Diff) []Diff {
 
 	// If shifts were made, the diff needs reordering and another shift sweep.
 	if changes {
-		ds = DiffCleanupMerge(ds)
+		ds = diffCleanupMerge(ds)
 	}
 
 	return ds
@@ -1,6 +1,7 @@
 package dmp
 
 import (
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -8,61 +9,113 @@ import (
 // sides by equalities which can be shifted sideways to align the edit to a
 // word boundary.
 // e.g: The c<ins>at c</ins>ame. -> The <ins>cat </ins>came.
+// The given slice is not modified.
 func DiffCleanupSemanticLossless(diffs []Diff) []Diff {
-	/**
-	 * Given two strings, compute a score representing whether the internal
-	 * boundary falls on logical boundaries.
-	 * Scores range from 6 (best) to 0 (worst).
-	 * Closure, but does not reference any external variables.
-	 * @param {string} one First string.
-	 * @param {string} two Second string.
-	 * @return {number} The score.
-	 * @private
-	 */
-	diffCleanupSemanticScore := func(one, two string) int {
-		if len(one) == 0 || len(two) == 0 {
-			// Edges are the best.
-			return 6
-		}
+	diffs = withoutMoves(diffs)
+	return diffCleanupSemanticLossless(copyDiffs(diffs), nil)
+}
 
-		// Each port of this function behaves slightly differently due to
-		// subtle differences in each language's definition of things like
-		// 'whitespace'.  Since this function's purpose is largely cosmetic,
-		// the choice has been made to use each language's native features
-		// rather than force total conformity.
-		rune1, _ := utf8.DecodeLastRuneInString(one)
-		rune2, _ := utf8.DecodeRuneInString(two)
-		char1 := string(rune1)
-		char2 := string(rune2)
-
-		nonAlphaNumeric1 := nonAlphaNumericRegex_.MatchString(char1)
-		nonAlphaNumeric2 := nonAlphaNumericRegex_.MatchString(char2)
-		whitespace1 := nonAlphaNumeric1 && whitespaceRegex_.MatchString(char1)
-		whitespace2 := nonAlphaNumeric2 && whitespaceRegex_.MatchString(char2)
-		lineBreak1 := whitespace1 && linebreakRegex_.MatchString(char1)
-		lineBreak2 := whitespace2 && linebreakRegex_.MatchString(char2)
-		blankLine1 := lineBreak1 && blanklineEndRegex_.MatchString(one)
-		blankLine2 := lineBreak2 && blanklineEndRegex_.MatchString(two)
-
-		if blankLine1 || blankLine2 {
-			// Five points for blank lines.
-			return 5
-		} else if lineBreak1 || lineBreak2 {
-			// Four points for line breaks.
-			return 4
-		} else if nonAlphaNumeric1 && !whitespace1 && whitespace2 {
-			// Three points for end of sentences.
-			return 3
-		} else if whitespace1 || whitespace2 {
-			// Two points for whitespace.
-			return 2
-		} else if nonAlphaNumeric1 || nonAlphaNumeric2 {
-			// One point for non-alphanumeric.
-			return 1
-		}
+// SemanticBoundaryScore scores whether the boundary between one and two
+// falls on a logical boundary, from 6 (best) to 0 (worst): edges, blank
+// lines, line breaks, ends of sentences, whitespace and other boundaries
+// of words score in that order.  Characters are classified by their
+// Unicode properties, so that letters of any script make words, each
+// ideograph or kana is a word of its own, as line breaking takes them, and
+// a boundary never splits a character from the marks, joiners and
+// modifiers that combine with it.  It is the default DiffSemanticScore.
+func SemanticBoundaryScore(one, two string) int {
+	if len(one) == 0 || len(two) == 0 {
+		// Edges are the best.
+		return 6
+	}
+
+	rune1, _ := utf8.DecodeLastRuneInString(one)
+	rune2, _ := utf8.DecodeRuneInString(two)
+	if rune1 == zeroWidthJoiner || isCombining(rune2) {
+		// Never split a grapheme, such as an emoji sequence.
 		return 0
 	}
 
+	nonAlphaNumeric1 := !isWordRune(rune1)
+	nonAlphaNumeric2 := !isWordRune(rune2)
+	whitespace1 := nonAlphaNumeric1 && unicode.IsSpace(rune1)
+	whitespace2 := nonAlphaNumeric2 && unicode.IsSpace(rune2)
+	lineBreak1 := whitespace1 && isLineBreak(rune1)
+	lineBreak2 := whitespace2 && isLineBreak(rune2)
+	blankLine1 := lineBreak1 && blanklineEndRegex_.MatchString(one)
+	blankLine2 := lineBreak2 && blanklineStartRegex_.MatchString(two)
+	// Full stops of scripts written without spaces end sentences without
+	// being followed by one.
+	sentenceEnd1 := nonAlphaNumeric1 && !whitespace1 &&
+		(whitespace2 || isWideTerminal(rune1))
+
+	if blankLine1 || blankLine2 {
+		// Five points for blank lines.
+		return 5
+	} else if lineBreak1 || lineBreak2 {
+		// Four points for line breaks.
+		return 4
+	} else if sentenceEnd1 {
+		// Three points for end of sentences.
+		return 3
+	} else if whitespace1 || whitespace2 {
+		// Two points for whitespace.
+		return 2
+	} else if nonAlphaNumeric1 || nonAlphaNumeric2 ||
+		isIdeographic(rune1) || isIdeographic(rune2) {
+		// One point for non-alphanumeric, and between ideographs.
+		return 1
+	}
+	return 0
+}
+
+// The zero width joiner glues emoji into sequences.
+const zeroWidthJoiner = '\u200D'
+
+// isWordRune tells whether r belongs in words: letters, digits and marks.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// isCombining tells whether r combines with the character before it:
+// marks, including variation selectors, joiners and emoji modifiers.
+func isCombining(r rune) bool {
+	return unicode.IsMark(r) || r == zeroWidthJoiner ||
+		r >= 0x1F3FB && r <= 0x1F3FF
+}
+
+// isLineBreak tells whether r forces a line break in the Unicode line
+// breaking algorithm.
+func isLineBreak(r rune) bool {
+	switch r {
+	case '\n', '\r', '\v', '\f', '\u0085', '\u2028', '\u2029':
+		return true
+	}
+	return false
+}
+
+// isIdeographic tells whether r is written without spaces between words,
+// as Chinese and Japanese are.
+func isIdeographic(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// isWideTerminal tells whether r is a full stop, question or exclamation
+// mark of the scripts of isIdeographic, such as "。" and "！".
+func isWideTerminal(r rune) bool {
+	return unicode.Is(unicode.Terminal_Punctuation, r) &&
+		(r >= 0x3000 && r <= 0x303F || r >= 0xFF00 && r <= 0xFFEF)
+}
+
+// diffCleanupSemanticLossless is DiffCleanupSemanticLossless working in
+// place, scoring boundaries with score (nil for SemanticBoundaryScore).
+func diffCleanupSemanticLossless(
+	diffs []Diff, score func(one, two string) int,
+) []Diff {
+	if score == nil {
+		score = SemanticBoundaryScore
+	}
+
 	i := 1
 
 	// Intentionally ignore the first and last element (don't need checking).
@@ -76,7 +129,7 @@ func DiffCleanupSemanticLossless(diffs []Diff) []Diff {
 			equality2 := diffs[i+1].Text
 
 			// First, shift the edit as far left as possible.
-			commonOffset := DiffCommonSuffix(equality1, edit)
+			commonOffset := commonSuffixBytes(equality1, edit)
 			if commonOffset > 0 {
 				commonString := edit[len(edit)-commonOffset:]
 				equality1 = equality1[0 : len(equality1)-commonOffset]
@@ -89,8 +142,8 @@ func DiffCleanupSemanticLossless(diffs []Diff) []Diff {
 			bestEquality1 := equality1
 			bestEdit := edit
 			bestEquality2 := equality2
-			bestScore := diffCleanupSemanticScore(equality1, edit) +
-				diffCleanupSemanticScore(edit, equality2)
+			bestScore := score(equality1, edit) +
+				score(edit, equality2)
 
 			for len(edit) != 0 && len(equality2) != 0 {
 				_, sz := utf8.DecodeRuneInString(edit)
@@ -100,12 +153,12 @@ func DiffCleanupSemanticLossless(diffs []Diff) []Diff {
 				equality1 += edit[:sz]
 				edit = edit[sz:] + equality2[:sz]
 				equality2 = equality2[sz:]
-				score := diffCleanupSemanticScore(equality1, edit) +
-					diffCleanupSemanticScore(edit, equality2)
+				total := score(equality1, edit) +
+					score(edit, equality2)
 					// The >= encourages trailing rather than leading
 					// whitespace on edits.
-				if score >= bestScore {
-					bestScore = score
+				if total >= bestScore {
+					bestScore = total
 					bestEquality1 = equality1
 					bestEdit = edit
 					bestEquality2 = equality2
@@ -137,8 +190,48 @@ func DiffCleanupSemanticLossless(diffs []Diff) []Diff {
 }
 
 // DiffCleanupSemantic reduces the number of edits by eliminating
-// semantically trivial equalities.
+// semantically trivial equalities.  Cleaning the result again changes
+// nothing.  The given slice is not modified.
 func DiffCleanupSemantic(diffs []Diff) []Diff {
+	diffs = withoutMoves(diffs)
+	return diffCleanupSemantic(copyDiffs(diffs), semanticOptions{})
+}
+
+// semanticOptions are the settings of diffCleanupSemantic, from the
+// DiffSemantic... fields of DMP.
+type semanticOptions struct {
+	minEquality int
+	score       func(one, two string) int
+	noOverlaps  bool
+}
+
+// semanticOptions returns the settings of the semantic cleanup of dmp.
+func (dmp *DMP) semanticOptions() semanticOptions {
+	return semanticOptions{
+		minEquality: dmp.DiffSemanticMinEquality,
+		score:       dmp.DiffSemanticScore,
+		noOverlaps:  dmp.DiffSemanticNoOverlaps,
+	}
+}
+
+// diffCleanupSemantic is DiffCleanupSemantic working in place, with the
+// given settings.
+func diffCleanupSemantic(diffs []Diff, opts semanticOptions) []Diff {
+	// A pass can leave equalities that the next one removes, or oscillate
+	// between removing equalities and extracting overlaps, so repeat it to
+	// make the result stable.
+	diffs, _ = CleanupFixpoint(diffs, func(diffs []Diff) []Diff {
+		return diffCleanupSemanticPass(diffCleanupMerge(diffs), opts)
+	}, maxSemanticPasses)
+	return diffs
+}
+
+// Maximum number of passes of diffCleanupSemantic, which stays well above
+// what diffs take in practice.
+const maxSemanticPasses = 64
+
+// diffCleanupSemanticPass is one pass of diffCleanupSemantic.
+func diffCleanupSemanticPass(diffs []Diff, opts semanticOptions) []Diff {
 	changes := false
 	equalities := new(Stack) // Stack of indices where equalities are found.
 
@@ -171,7 +264,9 @@ func DiffCleanupSemantic(diffs []Diff) []Diff {
 			d2 := max(insLen2, delLen2)
 			if len(lastequality) > 0 &&
 				(len(lastequality) <= d1) &&
-				(len(lastequality) <= d2) {
+				(len(lastequality) <= d2) &&
+				(opts.minEquality == 0 ||
+					len(lastequality) < opts.minEquality) {
 				// Duplicate record.
 				insPoint := equalities.Peek().(int)
 				diffs = append(
@@ -207,9 +302,12 @@ func DiffCleanupSemantic(diffs []Diff) []Diff {
 
 	// Normalize the diff.
 	if changes {
-		diffs = DiffCleanupMerge(diffs)
+		diffs = diffCleanupMerge(diffs)
+	}
+	diffs = diffCleanupSemanticLossless(diffs, opts.score)
+	if opts.noOverlaps {
+		return diffs
 	}
-	diffs = DiffCleanupSemanticLossless(diffs)
 	// Find any overlaps between deletions and insertions.
 	// e.g: <del>abcxxx</del><ins>xxxdef</ins>
 	//   -> <del>abc</del>xxx<ins>def</ins>
@@ -224,7 +322,9 @@ func DiffCleanupSemantic(diffs []Diff) []Diff {
 			insertion := diffs[i].Text
 			overlap_length1 := DiffCommonOverlap(deletion, insertion)
 			overlap_length2 := DiffCommonOverlap(insertion, deletion)
-			if overlap_length1 >= overlap_length2 {
+			if overlap_length1 == 0 && overlap_length2 == 0 {
+				// Nothing to extract.
+			} else if overlap_length1 >= overlap_length2 {
 				if float64(overlap_length1) >= float64(len(deletion))/2 ||
 					float64(overlap_length1) >= float64(len(insertion))/2 {
 
@@ -250,7 +350,7 @@ func DiffCleanupSemantic(diffs []Diff) []Diff {
 					// Reverse overlap found.
 					// Insert an equality and swap and trim the surrounding
 					// edits.
-					overlap := Diff{DiffEqual, insertion[overlap_length2:]}
+					overlap := Diff{DiffEqual, deletion[:overlap_length2]}
 					diffs = append(
 						diffs[:i],
 						append([]Diff{overlap}, diffs[i:]...)...)
@@ -2,6 +2,7 @@ package dmp
 
 import (
 	"strings"
+	"unicode/utf8"
 )
 
 // commonPrefixLength returns the length of the common prefix of two rune
@@ -41,6 +42,32 @@ func DiffCommonSuffix(s1, s2 string) int {
 	return commonSuffixLength([]rune(s1), []rune(s2))
 }
 
+// commonPrefixBytes returns the length in bytes of the common prefix of
+// two strings, cut before any partial rune.
+func commonPrefixBytes(s1, s2 string) int {
+	n := 0
+	for n < len(s1) && n < len(s2) && s1[n] == s2[n] {
+		n++
+	}
+	for n > 0 && n < len(s1) && !utf8.RuneStart(s1[n]) {
+		n--
+	}
+	return n
+}
+
+// commonSuffixBytes returns the length in bytes of the common suffix of
+// two strings, cut after any partial rune.
+func commonSuffixBytes(s1, s2 string) int {
+	n := 0
+	for n < len(s1) && n < len(s2) && s1[len(s1)-n-1] == s2[len(s2)-n-1] {
+		n++
+	}
+	for n > 0 && !utf8.RuneStart(s1[len(s1)-n]) {
+		n--
+	}
+	return n
+}
+
 // DiffCommonOverlap determines if the suffix of one string is the prefix of
 // another.
 func DiffCommonOverlap(s1, s2 string) int {
@@ -80,5 +107,4 @@ func DiffCommonOverlap(s1, s2 string) int {
 			length++
 		}
 	}
-	return 0
 }
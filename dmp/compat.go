@@ -0,0 +1,140 @@
+package dmp
+
+import (
+	"bytes"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// CompatibilityMode selects which port of diff-match-patch the output of
+// DiffToDelta and PatchToText reproduces byte for byte, for systems that
+// hash or compare that text across languages.
+//
+// All ports escape the same characters, but the C# port writes lowercase
+// hex escapes.  The ports also measure text differently: JavaScript and C#
+// count UTF-16 code units, Python counts code points, and this package
+// counts code points in deltas but bytes in patch headers.  The output
+// only differs for text with escaped or non-ASCII characters.
+//
+// The patches themselves are not converted: PatchMake adds context by
+// bytes, so where the context ends inside a multi-byte character the
+// other ports would have made a different patch.
+type CompatibilityMode int8
+
+const (
+	// CompatGo is the output of this package.
+	CompatGo CompatibilityMode = iota
+	// CompatJS is the output of the JavaScript port.
+	CompatJS
+	// CompatPython is the output of the Python 3 port.
+	CompatPython
+	// CompatCSharp is the output of the C# port.
+	CompatCSharp
+)
+
+// DiffToDelta is like the package function DiffToDelta, with the escapes
+// and lengths of the port m.
+func (m CompatibilityMode) DiffToDelta(diffs []Diff) string {
+	diffs = withoutMoves(diffs)
+	if m == CompatGo {
+		return DiffToDelta(diffs)
+	}
+	parts := make([]string, len(diffs))
+	for i, d := range diffs {
+		switch d.Type {
+		case DiffInsert:
+			parts[i] = "+" + m.escape(d.Text)
+		case DiffDelete:
+			parts[i] = "-" + strconv.Itoa(m.length(d.Text))
+		case DiffEqual:
+			parts[i] = "=" + strconv.Itoa(m.length(d.Text))
+		}
+	}
+	return strings.Join(parts, "\t")
+}
+
+// PatchToText is like the package function PatchToText, with the escapes
+// and coordinates of the port m.  Converting the starts of the patches
+// needs the text they apply to: each patch is taken to apply to text1
+// with the patches before it applied, which holds for the output of
+// PatchMake.
+func (m CompatibilityMode) PatchToText(ps []Patch, text1 string) string {
+	if m == CompatGo {
+		return PatchToText(ps)
+	}
+	var buf bytes.Buffer
+	text := text1
+	for _, p := range ps {
+		before := text[:min(p.start1, len(text))]
+		start1 := m.length(before)
+		// Apply the patch, so that the start in the patched text and the
+		// starts of later patches can be measured.
+		if end := p.start1 + p.length1; end <= len(text) {
+			text = before + DiffText2(p.diffs) + text[end:]
+		}
+		start2 := m.length(text[:min(p.start2, len(text))])
+		if p.start2 == p.start1 {
+			start2 = start1
+		}
+
+		buf.WriteString("@@ -")
+		buf.WriteString(patchCoords(start1, m.length(DiffText1(p.diffs))))
+		buf.WriteString(" +")
+		buf.WriteString(patchCoords(start2, m.length(DiffText2(p.diffs))))
+		buf.WriteString(" @@\n")
+		for _, d := range p.diffs {
+			switch d.Type {
+			case DiffInsert:
+				buf.WriteString("+")
+			case DiffDelete:
+				buf.WriteString("-")
+			case DiffEqual:
+				buf.WriteString(" ")
+			}
+			buf.WriteString(m.escape(d.Text))
+			buf.WriteString("\n")
+		}
+	}
+	return buf.String()
+}
+
+// length measures s the way the port m measures strings.
+func (m CompatibilityMode) length(s string) int {
+	n := utf8.RuneCountInString(s)
+	if m == CompatJS || m == CompatCSharp {
+		// Runes outside the BMP are surrogate pairs in UTF-16.
+		for _, r := range s {
+			if r > 0xFFFF {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// escape applies the %xx escaping of the port m.
+func (m CompatibilityMode) escape(s string) string {
+	s = strings.Replace(url.QueryEscape(s), "+", " ", -1)
+	s = unescaper.Replace(s)
+	if m != CompatCSharp {
+		return s
+	}
+	b := []byte(s)
+	for i := 0; i+2 < len(b); i++ {
+		if b[i] == '%' {
+			b[i+1] = lowerHex(b[i+1])
+			b[i+2] = lowerHex(b[i+2])
+			i += 2
+		}
+	}
+	return string(b)
+}
+
+func lowerHex(c byte) byte {
+	if 'A' <= c && c <= 'F' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
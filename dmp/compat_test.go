@@ -0,0 +1,54 @@
+package dmp
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+// compatCases are the text pairs of testdata/compat.  The golden files
+// there were written by the scripts next to them, which format the diffs
+// and patches of input.json with the escaping functions of each port's
+// language.
+var compatCases = [][2]string{
+	{"The quick brown fox.", "The slow brown fox!"},
+	{"Punctuation: !~*'();/?:@&=+$,# []{}|\\^`\"<>%",
+		"Punctuation; !~*'()/?:@&=+$,#\t[]{}|\\^`\"<> 100%"},
+	{"Crème brûlée\nand café", "Crème brûlée\nand thé au café"},
+	{"Emoji 😀 first, then text that changes.",
+		"Emoji 😀 first, then text that changed 🎉."},
+	{"見出し: text 日本語 text", "見出し: text 中国語 text"},
+}
+
+func TestCompatibilityMode(t *testing.T) {
+	type golden struct {
+		Delta string `json:"delta"`
+		Patch string `json:"patch"`
+	}
+	files := map[CompatibilityMode]string{
+		CompatJS:     "testdata/compat/js.json",
+		CompatPython: "testdata/compat/python.json",
+		CompatCSharp: "testdata/compat/csharp.json",
+	}
+	dmp := New()
+	for mode, file := range files {
+		b, err := ioutil.ReadFile(file)
+		assert.Nil(t, err, "")
+		var want []golden
+		assert.Nil(t, json.Unmarshal(b, &want), "")
+		assert.Equal(t, len(compatCases), len(want), file)
+		for i, c := range compatCases {
+			diffs := dmp.DiffMain(c[0], c[1], false)
+			ps := dmp.PatchMake(c[0], diffs)
+			assert.Equal(t, want[i].Delta, mode.DiffToDelta(diffs), file)
+			assert.Equal(t, want[i].Patch, mode.PatchToText(ps, c[0]), file)
+		}
+	}
+
+	diffs := dmp.DiffMain("abc", "abd", false)
+	ps := dmp.PatchMake("abc", diffs)
+	assert.Equal(t, DiffToDelta(diffs), CompatGo.DiffToDelta(diffs), "")
+	assert.Equal(t, PatchToText(ps), CompatGo.PatchToText(ps, "abc"), "")
+}
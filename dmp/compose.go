@@ -0,0 +1,207 @@
+package dmp
+
+import (
+	"fmt"
+)
+
+// Length of the unchanged text after the last patch, which is unknown.
+const composeTail = int(^uint(0) >> 1)
+
+// sparseDiff is a diff of a text that is only partly known, as patches
+// describe it: either a Diff, or unchanged text of which only the length
+// is known.
+type sparseDiff struct {
+	Diff
+	// Length of unknown unchanged text, or 0 for a Diff.
+	unknown int
+}
+
+// length returns how many bytes d covers in the text it is applied to
+// (old) or in the text it produces (new).
+func (d sparseDiff) length(old bool) int {
+	switch {
+	case d.unknown > 0:
+		return d.unknown
+	case d.Type == DiffEqual, old && d.Type == DiffDelete,
+		!old && d.Type == DiffInsert:
+		return len(d.Text)
+	}
+	return 0
+}
+
+// DiffCompose composes the diffs a, from text1 to text2, and b, from text2
+// to text3, into diffs from text1 to text3 without building text2.  The
+// text2 of a must be the text1 of b.
+func DiffCompose(a, b []Diff) ([]Diff, error) {
+	a = withoutMoves(a)
+	b = withoutMoves(b)
+	if len(DiffText2(a)) != len(DiffText1(b)) {
+		return nil, fmt.Errorf("Diffs do not compose: text lengths %d and %d",
+			len(DiffText2(a)), len(DiffText1(b)))
+	}
+	tail := sparseDiff{unknown: composeTail}
+	composed, err := composeSparse(
+		append(sparseDiffs(a), tail), append(sparseDiffs(b), tail),
+	)
+	if err != nil {
+		return nil, err
+	}
+	diffs := []Diff{}
+	for _, d := range composed[:len(composed)-1] {
+		diffs = append(diffs, d.Diff)
+	}
+	return diffCleanupMerge(diffs), nil
+}
+
+// PatchCompose composes the patches a, from text1 to text2, and b, from
+// text2 to text3, into patches from text1 to text3 without the texts.  The
+// text of b that a also covers, context included, must agree with what a
+// leaves there.  The context of the result is what is known of the text
+// around the changes from the contexts of a and b.
+func PatchCompose(a, b []Patch) ([]Patch, error) {
+	ops1, err := patchSparse(a)
+	if err != nil {
+		return nil, err
+	}
+	ops2, err := patchSparse(b)
+	if err != nil {
+		return nil, err
+	}
+	composed, err := composeSparse(ops1, ops2)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each run of known diffs with changes is a patch.
+	ps := []Patch{}
+	var p Patch
+	edits := false
+	pos := 0
+	for _, d := range composed {
+		if d.unknown > 0 {
+			if edits {
+				ps = append(ps, p)
+			}
+			if d.unknown == composeTail {
+				break
+			}
+			pos += d.unknown
+			p, edits = Patch{start1: pos, start2: pos}, false
+			continue
+		}
+		p.diffs = appendDiffText(p.diffs, d.Type, d.Text)
+		p.length1 += d.length(true)
+		p.length2 += d.length(false)
+		pos += d.length(false)
+		edits = edits || d.Type != DiffEqual
+	}
+	return ps, nil
+}
+
+// sparseDiffs wraps diffs.
+func sparseDiffs(diffs []Diff) []sparseDiff {
+	ops := make([]sparseDiff, len(diffs))
+	for i, d := range diffs {
+		ops[i] = sparseDiff{Diff: d}
+	}
+	return ops
+}
+
+// patchSparse describes the change made by patches, with the text between
+// them unknown.  Each patch applies to the text with the earlier patches
+// applied, where start1 counts, so the patches are composed one by one.
+// Their contexts may then overlap each other, or the changes before them,
+// in any way that agrees on the text.
+func patchSparse(ps []Patch) ([]sparseDiff, error) {
+	ops := []sparseDiff{{unknown: composeTail}}
+	for i, p := range ps {
+		if p.start1 < 0 {
+			return nil, fmt.Errorf("Invalid patch start: %d", p.start1)
+		}
+		op := []sparseDiff{}
+		if p.start1 > 0 {
+			op = append(op, sparseDiff{unknown: p.start1})
+		}
+		op = append(op, sparseDiffs(p.diffs)...)
+		op = append(op, sparseDiff{unknown: composeTail})
+		var err error
+		if ops, err = composeSparse(ops, op); err != nil {
+			return nil, fmt.Errorf(
+				"Patch %d does not agree with the patches before", i,
+			)
+		}
+	}
+	return ops, nil
+}
+
+// composeSparse composes a, from text1 to text2, and b, from text2 to
+// text3.  Both end with an unknown tail.
+func composeSparse(a, b []sparseDiff) ([]sparseDiff, error) {
+	out := []sparseDiff{}
+	emit := func(d sparseDiff) {
+		n := len(out)
+		if d.unknown > 0 && n > 0 && out[n-1].unknown > 0 {
+			out[n-1].unknown += d.unknown
+			return
+		}
+		out = append(out, d)
+	}
+	// Bytes of text2 consumed of the current diffs of a and b.
+	i, j, off1, off2 := 0, 0, 0, 0
+	// Offset in text2, for errors.
+	pos := 0
+	for i < len(a)-1 || j < len(b)-1 {
+		d1, d2 := a[i], b[j]
+		if d1.unknown == 0 && d1.Type == DiffDelete {
+			emit(d1)
+			i++
+			continue
+		}
+		if d2.unknown == 0 && d2.Type == DiffInsert {
+			emit(d2)
+			j++
+			continue
+		}
+		n := min(d1.length(false)-off1, d2.length(true)-off2)
+		piece1, piece2 := d1, d2
+		if d1.unknown > 0 {
+			piece1.unknown = n
+		} else {
+			piece1.Text = d1.Text[off1 : off1+n]
+		}
+		if d2.unknown > 0 {
+			piece2.unknown = n
+		} else {
+			piece2.Text = d2.Text[off2 : off2+n]
+		}
+		if piece1.unknown == 0 && piece2.unknown == 0 &&
+			piece1.Text != piece2.Text {
+			return nil, fmt.Errorf(
+				"Changes do not agree on the intermediate text at %d", pos,
+			)
+		}
+		switch {
+		case piece2.Type == DiffDelete:
+			if piece1.Type != DiffInsert {
+				emit(piece2)
+			}
+		case piece1.Type == DiffInsert:
+			emit(piece1)
+		case piece1.unknown == 0:
+			emit(piece1)
+		default:
+			emit(piece2)
+		}
+		pos += n
+		if off1 += n; off1 == d1.length(false) {
+			i, off1 = i+1, 0
+		}
+		if off2 += n; off2 == d2.length(true) {
+			j, off2 = j+1, 0
+		}
+	}
+	if n := len(out); n > 0 && out[n-1].unknown > 0 {
+		out = out[:n-1]
+	}
+	return append(out, sparseDiff{unknown: composeTail}), nil
+}
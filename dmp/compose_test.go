@@ -0,0 +1,111 @@
+package dmp
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffCompose(t *testing.T) {
+	dmp := New()
+	text1 := "The quick brown fox jumps over the lazy dog."
+	text2 := "That quick brown fox jumped over a lazy dog."
+	text3 := "That slow brown fox jumped over a lazy cat!"
+	diffs, err := DiffCompose(
+		dmp.DiffMain(text1, text2, false), dmp.DiffMain(text2, text3, false),
+	)
+	assert.Nil(t, err, "")
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text3, DiffText2(diffs), "")
+
+	// Text inserted by a and deleted by b vanishes.
+	diffs, err = DiffCompose(
+		[]Diff{{DiffEqual, "ab"}, {DiffInsert, "xyz"}, {DiffEqual, "c"}},
+		[]Diff{{DiffEqual, "ab"}, {DiffDelete, "xyz"}, {DiffEqual, "c"}},
+	)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []Diff{{DiffEqual, "abc"}}, diffs, "")
+
+	_, err = DiffCompose(
+		[]Diff{{DiffInsert, "abc"}}, []Diff{{DiffDelete, "ab"}},
+	)
+	assert.NotNil(t, err, "Lengths differ.")
+	_, err = DiffCompose(
+		[]Diff{{DiffInsert, "abc"}}, []Diff{{DiffDelete, "abd"}},
+	)
+	assert.NotNil(t, err, "Texts differ.")
+}
+
+func TestPatchCompose(t *testing.T) {
+	dmp := New()
+	text1 := "The quick brown fox jumps over the lazy dog."
+	text2 := "That quick brown fox jumped over a lazy dog."
+	text3 := "That slow brown fox jumped over a lazy cat!"
+	ps, err := PatchCompose(
+		dmp.PatchMake(text1, text2), dmp.PatchMake(text2, text3),
+	)
+	assert.Nil(t, err, "")
+	patched, applied := dmp.Apply(ps, text1)
+	assert.Equal(t, text3, patched, "")
+	for _, ok := range applied {
+		assert.True(t, ok, "")
+	}
+
+	// Patches far apart in long texts.
+	rnd := rand.New(rand.NewSource(1))
+	base := readFile("speedtest1.txt", t)
+	edit := func(s string) string {
+		for k := 0; k < 5; k++ {
+			i := rnd.Intn(len(s) - 20)
+			j := i + rnd.Intn(20)
+			s = s[:i] + []string{"", "xyz", "Hello\n"}[rnd.Intn(3)] + s[j:]
+		}
+		return s
+	}
+	for n := 0; n < 20; n++ {
+		text1 := base[:2000+rnd.Intn(2000)]
+		text2 := edit(text1)
+		text3 := edit(text2)
+		ps, err := PatchCompose(
+			dmp.PatchMake(text1, text2), dmp.PatchMake(text2, text3),
+		)
+		assert.Nil(t, err, "")
+		for _, p := range ps {
+			assert.Equal(t, p.length1, len(DiffText1(p.diffs)), "")
+			assert.Equal(t, p.length2, len(DiffText2(p.diffs)), "")
+		}
+		patched, _ := dmp.Apply(ps, text1)
+		assert.Equal(t, text3, patched, "")
+	}
+
+	// Patches that disagree on the intermediate text.
+	_, err = PatchCompose(
+		dmp.PatchMake(text1, text2), dmp.PatchMake(text1, text3),
+	)
+	assert.NotNil(t, err, "")
+}
+
+func TestPatchComposeRandom(t *testing.T) {
+	// Short texts of few letters give patches whose contexts overlap each
+	// other and the changes before them.
+	dmp := New()
+	rnd := rand.New(rand.NewSource(1))
+	text := func() string {
+		b := make([]byte, rnd.Intn(80))
+		for i := range b {
+			b[i] = "abc "[rnd.Intn(4)]
+		}
+		return string(b)
+	}
+	for n := 0; n < 2000; n++ {
+		text1, text2, text3 := text(), text(), text()
+		a, b := dmp.PatchMake(text1, text2), dmp.PatchMake(text2, text3)
+		ps, err := PatchCompose(a, b)
+		assert.Nil(t, err, "")
+		patched, _ := dmp.Apply(a, text1)
+		want, _ := dmp.Apply(b, patched)
+		patched, _ = dmp.Apply(ps, text1)
+		assert.Equal(t, want, patched, "")
+	}
+}
@@ -0,0 +1,113 @@
+package dmp
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// OutputFormat selects what DiffReaders writes.
+type OutputFormat int8
+
+const (
+	// FormatPatch writes the patches from the first text to the second, as
+	// PatchToText does.
+	FormatPatch OutputFormat = iota
+	// FormatDelta writes the diff as DiffToDelta does.
+	FormatDelta
+	// FormatHtml writes the diff as DiffWriteHtml does.
+	FormatHtml
+)
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+	// The magic numbers of a bzip2 block and of the end of the stream, one
+	// of which follows the header.
+	bzip2BlockMagic = []byte{0x31, 0x41, 0x59, 0x26, 0x53, 0x59}
+	bzip2EndMagic   = []byte{0x17, 0x72, 0x45, 0x38, 0x50, 0x90}
+)
+
+// Decompress sniffs the first bytes of r and, if they are the magic number
+// of gzip or bzip2 data, returns a reader of the decompressed data.  Other
+// data is returned as is.  Zstandard data is recognized but can not be
+// read, as the standard library has no decoder for it: it yields an error,
+// and must be decompressed by the caller.
+func Decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, err := br.Peek(len(bzip2Magic) + 1 + len(bzip2BlockMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch {
+	case bytes.HasPrefix(head, gzipMagic):
+		return gzip.NewReader(br)
+	case isBzip2(head):
+		return bzip2.NewReader(br), nil
+	case bytes.HasPrefix(head, zstdMagic):
+		return nil, fmt.Errorf("Zstandard compression is not supported")
+	}
+	return br, nil
+}
+
+// DiffReaders reads two texts, decompressing them if needed, diffs them in
+// line mode and writes the result to w in the given format.  It is meant
+// for comparing rotated logs and compressed artifacts, compressed with gzip
+// or bzip2; Zstandard data fails, see Decompress.
+func (dmp *DMP) DiffReaders(
+	w io.Writer, r1, r2 io.Reader, format OutputFormat,
+) error {
+	text1, err := readDecompressed(r1)
+	if err != nil {
+		return err
+	}
+	text2, err := readDecompressed(r2)
+	if err != nil {
+		return err
+	}
+
+	diffs := dmp.DiffMain(text1, text2, true)
+	switch format {
+	case FormatPatch:
+		_, err = io.WriteString(w, PatchToText(dmp.PatchMake(text1, diffs)))
+	case FormatDelta:
+		_, err = io.WriteString(w, DiffToDelta(diffs))
+	case FormatHtml:
+		err = DiffWriteHtml(w, diffs, HtmlOptions{})
+	default:
+		err = fmt.Errorf("Invalid output format: %d", format)
+	}
+	return err
+}
+
+// isBzip2 tells whether head starts with a bzip2 header: "BZh", the block
+// size from '1' to '9', and the magic number of the first block or of the
+// end of an empty stream.  Text starting with "BZh" is not taken for it.
+func isBzip2(head []byte) bool {
+	n := len(bzip2Magic)
+	if !bytes.HasPrefix(head, bzip2Magic) || len(head) < n+1 ||
+		head[n] < '1' || head[n] > '9' {
+		return false
+	}
+	magic := head[n+1:]
+	return bytes.Equal(magic, bzip2BlockMagic) ||
+		bytes.Equal(magic, bzip2EndMagic)
+}
+
+func readDecompressed(r io.Reader) (string, error) {
+	dr, err := Decompress(r)
+	if err != nil {
+		return "", err
+	}
+	b, err := ioutil.ReadAll(dr)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
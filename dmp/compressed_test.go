@@ -0,0 +1,83 @@
+package dmp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func gzipped(t *testing.T, s string) *bytes.Buffer {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestDecompress(t *testing.T) {
+	r, err := Decompress(gzipped(t, "hello\n"))
+	assert.Nil(t, err, "")
+	b, _ := ioutil.ReadAll(r)
+	assert.Equal(t, "hello\n", string(b), "")
+
+	for _, plain := range []string{"", "h", "plain text\n"} {
+		r, err = Decompress(strings.NewReader(plain))
+		assert.Nil(t, err, "")
+		b, _ = ioutil.ReadAll(r)
+		assert.Equal(t, plain, string(b), "Uncompressed data is passed through.")
+	}
+
+	for _, c := range []struct{ data, want string }{
+		{"BZh91AY&SY\xc1\xc0\x80\xe2\x00\x00\x01\x41\x00\x00\x10\x02" +
+			"\x44\xa0\x00\x30\xcd\x00\xc3\x46\x29\x97\x17\x72\x45\x38" +
+			"\x50\x90\xc1\xc0\x80\xe2", "hello\n"},
+		{"BZh9\x17\x72\x45\x38\x50\x90\x00\x00\x00\x00", ""},
+	} {
+		r, err = Decompress(strings.NewReader(c.data))
+		assert.Nil(t, err, "")
+		b, err = ioutil.ReadAll(r)
+		assert.Nil(t, err, "bzip2")
+		assert.Equal(t, c.want, string(b), "")
+	}
+	// Text that starts like bzip2 data is not.
+	for _, plain := range []string{"BZh", "BZhello, world\n", "BZh9 lines\n"} {
+		r, err = Decompress(strings.NewReader(plain))
+		assert.Nil(t, err, "")
+		b, _ = ioutil.ReadAll(r)
+		assert.Equal(t, plain, string(b), "")
+	}
+
+	_, err = Decompress(bytes.NewReader([]byte{0x28, 0xb5, 0x2f, 0xfd, 0}))
+	assert.NotNil(t, err, "zstd")
+	_, err = Decompress(bytes.NewReader([]byte{0x1f, 0x8b, 0}))
+	assert.NotNil(t, err, "Truncated gzip header.")
+}
+
+func TestDiffReaders(t *testing.T) {
+	dmp := New()
+	var buf bytes.Buffer
+	err := dmp.DiffReaders(&buf, gzipped(t, "a\nb\nc\n"), strings.NewReader("a\nB\nc\n"), FormatDelta)
+	assert.Nil(t, err, "")
+	assert.Equal(t, "=2\t-1\t+B\t=3", buf.String(), "")
+
+	buf.Reset()
+	err = dmp.DiffReaders(&buf, gzipped(t, "a\nb\nc\n"), gzipped(t, "a\nB\nc\n"), FormatPatch)
+	assert.Nil(t, err, "")
+	assert.Equal(t, "@@ -1,6 +1,6 @@\n a%0A\n-b\n+B\n %0Ac%0A\n", buf.String(), "")
+
+	buf.Reset()
+	err = dmp.DiffReaders(&buf, strings.NewReader("x"), strings.NewReader("y"), FormatHtml)
+	assert.Nil(t, err, "")
+	assert.Equal(t, DiffPrettyHtml([]Diff{{DiffDelete, "x"}, {DiffInsert, "y"}}), buf.String(), "")
+
+	err = dmp.DiffReaders(&buf, strings.NewReader("x"), strings.NewReader("y"), OutputFormat(9))
+	assert.NotNil(t, err, "")
+}
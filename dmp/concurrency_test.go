@@ -0,0 +1,85 @@
+package dmp
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+// TestConcurrentUse runs diff, match and patch operations on one shared DMP
+// and one shared patch list from several goroutines.  Run with -race.
+func TestConcurrentUse(t *testing.T) {
+	dmp := New()
+	text1 := "The quick brown fox jumps over the lazy dog.\n"
+	text2 := "That quick brown fox jumped over a lazy dog.\n"
+	for i := 0; i < 4; i++ {
+		text1 += text1
+		text2 += text2
+	}
+
+	wantDiffs := dmp.DiffMain(text1, text2, true)
+	patches := dmp.PatchMake(text1, text2)
+	patchText := PatchToText(patches)
+	wantApplied, _ := dmp.Apply(patches, text1)
+	wantMatch := dmp.MatchMain(text1, "lazy dgo", 30)
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 64)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				diffs := dmp.DiffMain(text1, text2, true)
+				if len(diffs) != len(wantDiffs) {
+					errs <- "DiffMain"
+				}
+				if s, _ := dmp.Apply(patches, text1); s != wantApplied {
+					errs <- "Apply"
+				}
+				if PatchToText(dmp.PatchMake(text1, text2)) != patchText {
+					errs <- "PatchMake"
+				}
+				if dmp.MatchMain(text1, "lazy dgo", 30) != wantMatch {
+					errs <- "MatchMain"
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for e := range errs {
+		t.Errorf("Concurrent %s differs from the serial result.", e)
+	}
+	assert.Equal(t, patchText, PatchToText(patches), "Shared patches are not modified.")
+}
+
+func TestDiffParallelism(t *testing.T) {
+	s1 := readFile("speedtest1.txt", t)
+	s2 := readFile("speedtest2.txt", t)
+	dmp := New()
+	dmp.DiffTimeout = 0
+	for _, checkLines := range []bool{false, true} {
+		want := dmp.DiffMain(s1, s2, checkLines)
+		dmp.DiffParallelism = 4
+		assert.Equal(t, want, dmp.DiffMain(s1, s2, checkLines), "")
+		dmp.DiffParallelism = 0
+	}
+}
+
+func Benchmark_DiffParallelism(b *testing.B) {
+	s1 := readFile("speedtest1.txt", b)
+	s2 := readFile("speedtest2.txt", b)
+	for i := 0; i < 3; i++ {
+		s1 += s2
+		s2 += s1
+	}
+	dmp := New()
+	dmp.DiffTimeout = 0
+	dmp.DiffParallelism = 8
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dmp.DiffMain(s1, s2, false)
+	}
+}
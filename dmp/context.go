@@ -0,0 +1,82 @@
+package dmp
+
+import (
+	"context"
+)
+
+// withContext returns a copy of dmp whose diffs, matches and patch
+// applications stop early once ctx is done.
+func (dmp *DMP) withContext(ctx context.Context) *DMP {
+	e := *dmp
+	e.done = ctx.Done()
+	return &e
+}
+
+// isDone tells whether done is closed.  A nil channel is never done.
+func isDone(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// DiffMainContext is like DiffMain, but gives up and returns the error of
+// ctx once ctx is done, as well as after DiffTimeout.  A Differ is only
+// told the deadline of ctx.
+func (dmp *DMP) DiffMainContext(
+	ctx context.Context, s1, s2 string, checkLines bool,
+) ([]Diff, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := dmp.checkTexts("text1", s1, "text2", s2); err != nil {
+		return nil, err
+	}
+	e := dmp.withContext(ctx)
+	end := deadline(e.DiffTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(end) && e.Differ != nil {
+		end = d
+	}
+	diffs := e.diffDispatch(s1, s2, checkLines, end)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return diffs, nil
+}
+
+// MatchMainContext is like MatchMain, but gives up and returns the error
+// of ctx once ctx is done.
+func (dmp *DMP) MatchMainContext(
+	ctx context.Context, s, pattern string, loc int,
+) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+	loc = matchMain(dmp.withContext(ctx), s, pattern, loc, nil)
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+	return loc, nil
+}
+
+// ApplyContext is like Apply, but stops once ctx is done and returns the
+// error of ctx along with the partial result: the text with the patches
+// applied so far, and false for the patches that were not reached.  Like
+// ApplyChecked, it fails on malformed patches.
+func (dmp *DMP) ApplyContext(ctx context.Context, ps []Patch, s string) (
+	string, []bool, error,
+) {
+	if err := PatchValidate(ps); err != nil {
+		return s, nil, err
+	}
+	if err := dmp.checkPatches(ps); err != nil {
+		return s, nil, err
+	}
+	if err := dmp.checkTexts("text", s); err != nil {
+		return s, nil, err
+	}
+	patched, applied := dmp.withContext(ctx).Apply(ps, s)
+	return patched, applied, ctx.Err()
+}
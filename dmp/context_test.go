@@ -0,0 +1,102 @@
+package dmp
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffMainContext(t *testing.T) {
+	dmp := New()
+	ctx := context.Background()
+	diffs, err := dmp.DiffMainContext(ctx, "The cat", "The hat", false)
+	assert.Nil(t, err, "")
+	assert.Equal(t, dmp.DiffMain("The cat", "The hat", false), diffs, "")
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = dmp.DiffMainContext(canceled, "a", "b", false)
+	assert.Equal(t, context.Canceled, err, "")
+
+	// A diff that would run for a long time without a timeout.
+	rnd := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		b := make([]byte, 20000)
+		for i := range b {
+			b[i] = "abcd"[rnd.Intn(4)]
+		}
+		return string(b)
+	}
+	text1, text2 := randomText(), randomText()
+	dmp.DiffTimeout = 0
+	ctx, cancel = context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = dmp.DiffMainContext(ctx, text1, text2, false)
+	assert.Equal(t, context.DeadlineExceeded, err, "")
+	assert.True(t, time.Since(start) < 2*time.Second, "Stops early.")
+}
+
+func TestMatchMainContext(t *testing.T) {
+	dmp := New()
+	loc, err := dmp.MatchMainContext(
+		context.Background(), "abcdefghijk", "efxhi", 0,
+	)
+	assert.Nil(t, err, "")
+	assert.Equal(t, 4, loc, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	loc, err = dmp.MatchMainContext(ctx, "abcdefghijk", "efxhi", 0)
+	assert.Equal(t, context.Canceled, err, "")
+	assert.Equal(t, -1, loc, "")
+}
+
+func TestApplyContext(t *testing.T) {
+	dmp := New()
+	ps := dmp.PatchMake("The quick brown fox.", "The slow brown fox.")
+	s, applied, err := dmp.ApplyContext(
+		context.Background(), ps, "The quick brown fox!",
+	)
+	assert.Nil(t, err, "")
+	assert.Equal(t, "The slow brown fox!", s, "")
+	assert.Equal(t, []bool{true}, applied, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s, applied, err = dmp.ApplyContext(ctx, ps, "The quick brown fox!")
+	assert.Equal(t, context.Canceled, err, "")
+	assert.Equal(t, "The quick brown fox!", s, "The text is unchanged.")
+	assert.Equal(t, []bool{false}, applied, "")
+
+	// Cancel while applying: the patches before the cancellation are kept.
+	text1 := strings.Repeat("The quick brown fox. ", 50)
+	text2 := strings.Replace(text1, "quick", "slow", -1)
+	ps = dmp.PatchMake(text1, text2)
+	ctx, cancel = context.WithCancel(context.Background())
+	n := 0
+	dmp.Differ = differFunc(func(a, b []rune) []Diff {
+		if n++; n == 10 {
+			cancel()
+		}
+		return New().DiffMainRunes(a, b, false)
+	})
+	// The patches only match approximately, so applying them diffs.
+	target := strings.Replace(text1, "brown", "brawn", -1)
+	s, applied, err = dmp.ApplyContext(ctx, ps, target)
+	assert.Equal(t, context.Canceled, err, "")
+	assert.Equal(t, len(ps), len(applied), "")
+	assert.True(t, applied[0] && !applied[len(ps)-1], "")
+	assert.True(t, strings.HasPrefix(s, "The slow brawn fox. "), s)
+	assert.True(t, strings.HasSuffix(s, "The quick brawn fox. "), s)
+}
+
+type differFunc func(a, b []rune) []Diff
+
+func (f differFunc) DiffRunes(a, b []rune, deadline time.Time) []Diff {
+	return f(a, b)
+}
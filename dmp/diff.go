@@ -18,3 +18,12 @@ func diffPrepend(head Diff, diffs []Diff) []Diff {
 func diffAppend(diffs []Diff, tail Diff) []Diff {
 	return append(diffs, tail)
 }
+
+// copyDiffs returns a copy of diffs that can be modified freely.
+func copyDiffs(diffs []Diff) []Diff {
+	if diffs == nil {
+		return nil
+	}
+	// One spare slot for the dummy entry added by diffCleanupMerge.
+	return append(make([]Diff, 0, len(diffs)+1), diffs...)
+}
@@ -2,46 +2,39 @@ package dmp
 
 /**
  * Does a substring of shorttext exist within longtext such that the substring
- * is at least half the length of longtext?
+ * is at least ratio (usually half) the length of longtext?
  * @param {string} longtext Longer string.
  * @param {string} shorttext Shorter string.
  * @param {number} i Start index of quarter length substring within longtext.
+ * @param {number} ratio Minimum length of the common substring, relative to
+ *     the length of longtext.
  * @return {Array.<string>} Five element Array, containing the prefix of
  *     longtext, the suffix of longtext, the prefix of shorttext, the suffix
  *     of shorttext and the common middle.  Or null if there was no match.
  * @private
  */
-func diffHalfMatchI(l, s []rune, i int) [][]rune {
+func diffHalfMatchI(dmp *DMP, l, s []rune, i int, ratio float64) [][]rune {
 	// Start with a 1/4 length substring at position i as a seed.
 	seed := l[i : i+len(l)/4]
-	j := -1
 	common := []rune{}
 	longA := []rune{}
 	longB := []rune{}
 	shortA := []rune{}
 	shortB := []rune{}
 
-	if j < len(s) {
-		j = runesIndexOf(s, seed, j+1)
-		for {
-			if j == -1 {
-				break
-			}
-
-			prefixLen := commonPrefixLength(l[i:], s[j:])
-			suffixLen := commonSuffixLength(l[:i], s[:j])
-			if len(common) < suffixLen+prefixLen {
-				common = concat(s[j-suffixLen:j], s[j:j+prefixLen])
-				longA = l[:i-suffixLen]
-				longB = l[i+prefixLen:]
-				shortA = s[:j-suffixLen]
-				shortB = s[j+prefixLen:]
-			}
-			j = runesIndexOf(s, seed, j+1)
+	for _, j := range dmp.runesIndexAll(s, seed) {
+		prefixLen := commonPrefixLength(l[i:], s[j:])
+		suffixLen := commonSuffixLength(l[:i], s[:j])
+		if len(common) < suffixLen+prefixLen {
+			common = concat(s[j-suffixLen:j], s[j:j+prefixLen])
+			longA = l[:i-suffixLen]
+			longB = l[i+prefixLen:]
+			shortA = s[:j-suffixLen]
+			shortB = s[j+prefixLen:]
 		}
 	}
 
-	if len(common)*2 >= len(l) {
+	if float64(len(common)) >= ratio*float64(len(l)) {
 		return [][]rune{
 			longA, longB,
 			shortA, shortB,
@@ -66,15 +59,19 @@ func diffHalfMatch(dmp *DMP, text1, text2 []rune) [][]rune {
 		short = text1
 	}
 
-	if len(long) < 4 || len(short)*2 < len(long) {
+	ratio := dmp.DiffHalfMatchRatio
+	if ratio <= 0 {
+		ratio = 0.5
+	}
+	if len(long) < 4 || float64(len(short)) < ratio*float64(len(long)) {
 		return nil // Pointless.
 	}
 
 	// First check if the second quarter is the seed for a half-match.
-	hm1 := diffHalfMatchI(long, short, int(float64(len(long)+3)/4))
+	hm1 := diffHalfMatchI(dmp, long, short, int(float64(len(long)+3)/4), ratio)
 
 	// Check again based on the third quarter.
-	hm2 := diffHalfMatchI(long, short, int(float64(len(long)+1)/2))
+	hm2 := diffHalfMatchI(dmp, long, short, int(float64(len(long)+1)/2), ratio)
 
 	hm := [][]rune{}
 	if hm1 == nil && hm2 == nil {
@@ -0,0 +1,56 @@
+package dmp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffHalfMatchRatio(t *testing.T) {
+	dmp := New()
+	// The common "3456" is 40% of the longer text.
+	assert.True(t, dmp.DiffHalfMatch("1234567890", "x3456y") == nil, "")
+
+	dmp.DiffHalfMatchRatio = 0.3
+	assertStrEqual(t, []string{"12", "7890", "x", "y", "3456"},
+		dmp.DiffHalfMatch("1234567890", "x3456y"))
+	assertStrEqual(t, []string{"x", "y", "12", "7890", "3456"},
+		dmp.DiffHalfMatch("x3456y", "1234567890"))
+
+	// Still pointless when the short text is below the ratio.
+	assert.True(t, dmp.DiffHalfMatch("1234567890", "45") == nil, "")
+
+	// Zero falls back to the default.
+	dmp.DiffHalfMatchRatio = 0
+	assert.True(t, dmp.DiffHalfMatch("1234567890", "x3456y") == nil, "")
+}
+
+// halfMatchTexts builds two texts sharing a block of about a third of
+// their length, with unrelated text around it.
+func halfMatchTexts() (string, string) {
+	shared := strings.Repeat("The shared block of text. ", 35)
+	a := strings.Repeat("alpha beta gamma delta ", 30)
+	b := strings.Repeat("one two three four five ", 55)
+	c := strings.Repeat("red green blue cyan ", 35)
+	d := strings.Repeat("north east south west ", 30)
+	return a + shared + b, c + shared + d
+}
+
+func benchmarkDiffHalfMatchRatio(b *testing.B, ratio float64) {
+	s1, s2 := halfMatchTexts()
+	dmp := New()
+	dmp.DiffHalfMatchRatio = ratio
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dmp.DiffMain(s1, s2, false)
+	}
+}
+
+func Benchmark_DiffHalfMatchRatio50(b *testing.B) {
+	benchmarkDiffHalfMatchRatio(b, 0.5)
+}
+
+func Benchmark_DiffHalfMatchRatio30(b *testing.B) {
+	benchmarkDiffHalfMatchRatio(b, 0.3)
+}
@@ -0,0 +1,193 @@
+package dmp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf8"
+)
+
+// DiffsEncodeJSONStream writes diffs to w as a JSON array of [op, text]
+// pairs, as the JSON output of the other ports: [[0,"The "],[-1,"quick"]].
+// The texts are escaped straight into a buffered writer, so no copy of the
+// whole document is built in memory.  Invalid UTF-8 is written as
+// U+FFFD, as encoding/json does.
+func DiffsEncodeJSONStream(w io.Writer, diffs []Diff) error {
+	diffs = withoutMoves(diffs)
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	for i, d := range diffs {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteByte('[')
+		bw.WriteString(strconv.Itoa(int(d.Type)))
+		bw.WriteByte(',')
+		writeJSONString(bw, d.Text)
+		bw.WriteByte(']')
+	}
+	bw.WriteByte(']')
+	return bw.Flush()
+}
+
+func writeJSONString(w *bufio.Writer, s string) {
+	const hex = "0123456789abcdef"
+	w.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' && c < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if c < utf8.RuneSelf {
+			w.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				w.WriteByte('\\')
+				w.WriteByte(c)
+			case '\n':
+				w.WriteString(`\n`)
+			case '\r':
+				w.WriteString(`\r`)
+			case '\t':
+				w.WriteString(`\t`)
+			default:
+				w.WriteString(`\u00`)
+				w.WriteByte(hex[c>>4])
+				w.WriteByte(hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			w.WriteString(s[start:i])
+			w.WriteString(`\ufffd`)
+			i++
+			start = i
+			continue
+		}
+		i += size
+	}
+	w.WriteString(s[start:])
+	w.WriteByte('"')
+}
+
+// DiffsDecodeJSONStream reads the output of DiffsEncodeJSONStream from r
+// and hands each diff to fn as soon as it is read.  Only one diff is held
+// in memory at a time.  Errors of fn stop the decoding and are returned.
+func DiffsDecodeJSONStream(r io.Reader, fn func(Diff) error) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := expectJSONDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := expectJSONDelim(dec, '['); err != nil {
+			return err
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		num, ok := tok.(json.Number)
+		op, convErr := strconv.Atoi(string(num))
+		if !ok || convErr != nil || op < -1 || op > 1 {
+			return fmt.Errorf("Invalid diff operation: %v", tok)
+		}
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		text, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("Invalid diff text: %v", tok)
+		}
+		if err := expectJSONDelim(dec, ']'); err != nil {
+			return err
+		}
+		if err := fn(Diff{Operation(op), text}); err != nil {
+			return err
+		}
+	}
+	return expectJSONDelim(dec, ']')
+}
+
+// DiffsDecodeJSON reads the output of DiffsEncodeJSONStream from r.
+func DiffsDecodeJSON(r io.Reader) ([]Diff, error) {
+	diffs := []Diff{}
+	err := DiffsDecodeJSONStream(r, func(d Diff) error {
+		diffs = append(diffs, d)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return diffs, nil
+}
+
+func expectJSONDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("Expected %v in diff JSON, got %v", delim, tok)
+	}
+	return nil
+}
+
+// MarshalJSON encodes d as an [op, text] pair, as DiffsEncodeJSONStream
+// does.
+func (d Diff) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	bw.WriteByte('[')
+	bw.WriteString(strconv.Itoa(int(d.Type.edit())))
+	bw.WriteByte(',')
+	writeJSONString(bw, d.Text)
+	bw.WriteByte(']')
+	err := bw.Flush()
+	return buf.Bytes(), err
+}
+
+// UnmarshalJSON decodes an [op, text] pair.
+func (d *Diff) UnmarshalJSON(data []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("Invalid diff: %s", data)
+	}
+	var op int
+	if err := json.Unmarshal(pair[0], &op); err != nil {
+		return fmt.Errorf("Invalid diff operation: %s", pair[0])
+	}
+	if op < -1 || op > 1 {
+		return fmt.Errorf("Invalid diff operation: %d", op)
+	}
+	var text string
+	if err := json.Unmarshal(pair[1], &text); err != nil {
+		return fmt.Errorf("Invalid diff text: %s", pair[1])
+	}
+	*d = Diff{Operation(op), text}
+	return nil
+}
+
+// DiffsToJSON encodes diffs as a JSON array of [op, text] pairs.
+func DiffsToJSON(diffs []Diff) ([]byte, error) {
+	var buf bytes.Buffer
+	err := DiffsEncodeJSONStream(&buf, diffs)
+	return buf.Bytes(), err
+}
+
+// DiffsFromJSON decodes the output of DiffsToJSON.
+func DiffsFromJSON(data []byte) ([]Diff, error) {
+	return DiffsDecodeJSON(bytes.NewReader(data))
+}
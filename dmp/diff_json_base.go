@@ -0,0 +1,75 @@
+package dmp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// DiffsToBaseJSON encodes diffs like DiffsToJSON, but writes equalities as
+// [0, offset, length] references to the text1 of the diffs, in bytes,
+// instead of their text.  Sync protocols where both sides hold text1 then
+// only send the changed text.
+func DiffsToBaseJSON(diffs []Diff) ([]byte, error) {
+	diffs = withoutMoves(diffs)
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	bw.WriteByte('[')
+	pos := 0
+	for i, d := range diffs {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteByte('[')
+		bw.WriteString(strconv.Itoa(int(d.Type)))
+		bw.WriteByte(',')
+		if d.Type == DiffEqual {
+			bw.WriteString(strconv.Itoa(pos))
+			bw.WriteByte(',')
+			bw.WriteString(strconv.Itoa(len(d.Text)))
+		} else {
+			writeJSONString(bw, d.Text)
+		}
+		bw.WriteByte(']')
+		if d.Type != DiffInsert {
+			pos += len(d.Text)
+		}
+	}
+	bw.WriteByte(']')
+	err := bw.Flush()
+	return buf.Bytes(), err
+}
+
+// DiffsFromBaseJSON decodes the output of DiffsToBaseJSON, taking the text
+// of equalities from base.
+func DiffsFromBaseJSON(data []byte, base string) ([]Diff, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	diffs := make([]Diff, len(items))
+	for i, item := range items {
+		var ref []int
+		if json.Unmarshal(item, &ref) != nil {
+			if err := diffs[i].UnmarshalJSON(item); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(ref) != 3 || ref[0] != int(DiffEqual) {
+			return nil, fmt.Errorf("Invalid diff reference: %s", item)
+		}
+		offset, length := ref[1], ref[2]
+		if offset < 0 || length < 0 || offset > len(base) ||
+			length > len(base)-offset {
+			return nil, fmt.Errorf(
+				"Diff reference out of the base text of %d bytes: %s",
+				len(base), item,
+			)
+		}
+		diffs[i] = Diff{DiffEqual, base[offset : offset+length]}
+	}
+	return diffs, nil
+}
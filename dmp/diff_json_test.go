@@ -0,0 +1,87 @@
+package dmp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffsJSONStream(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "The \"quick\"\n"}, {DiffDelete, "brown\t\x01\\"},
+		{DiffInsert, "red 日本 😀"}, {DiffEqual, ""},
+	}
+	var buf bytes.Buffer
+	assert.Nil(t, DiffsEncodeJSONStream(&buf, diffs), "")
+	assert.Equal(t,
+		`[[0,"The \"quick\"\n"],[-1,"brown\t\u0001\\"],[1,"red 日本 😀"],[0,""]]`,
+		buf.String(), "")
+
+	// The output is what encoding/json makes of the same data.
+	var pairs [][2]interface{}
+	for _, d := range diffs {
+		pairs = append(pairs, [2]interface{}{d.Type, d.Text})
+	}
+	want, _ := json.Marshal(pairs)
+	assert.Equal(t, string(want), buf.String(), "")
+
+	decoded, err := DiffsDecodeJSON(bytes.NewReader(buf.Bytes()))
+	assert.Nil(t, err, "")
+	assert.Equal(t, diffs, decoded, "")
+
+	// Invalid UTF-8.
+	buf.Reset()
+	DiffsEncodeJSONStream(&buf, []Diff{{DiffInsert, "a\xffb"}})
+	decoded, _ = DiffsDecodeJSON(&buf)
+	assert.Equal(t, []Diff{{DiffInsert, "a\ufffdb"}}, decoded, "")
+
+	// Decoding stops at the first error of the callback.
+	stop := errors.New("stop")
+	n := 0
+	err = DiffsDecodeJSONStream(strings.NewReader(`[[0,"a"],[1,"b"]]`),
+		func(Diff) error { n++; return stop })
+	assert.Equal(t, stop, err, "")
+	assert.Equal(t, 1, n, "")
+
+	for _, bad := range []string{
+		``, `{}`, `[[2,"a"]]`, `[[0,1]]`, `[[0,"a",1]]`, `[[0,"a"]`,
+	} {
+		_, err := DiffsDecodeJSON(strings.NewReader(bad))
+		assert.NotNil(t, err, bad)
+	}
+}
+
+func TestDiffsBaseJSON(t *testing.T) {
+	text1 := "The quick brown fox jumps."
+	diffs := []Diff{
+		{DiffEqual, "The quick "},
+		{DiffDelete, "brown"},
+		{DiffInsert, "red"},
+		{DiffEqual, " fox jumps"},
+		{DiffDelete, "."},
+		{DiffInsert, "!"},
+	}
+	data, err := DiffsToBaseJSON(diffs)
+	assert.Nil(t, err, "")
+	assert.Equal(t,
+		`[[0,0,10],[-1,"brown"],[1,"red"],[0,15,10],[-1,"."],[1,"!"]]`,
+		string(data), "")
+
+	decoded, err := DiffsFromBaseJSON(data, text1)
+	assert.Nil(t, err, "")
+	assert.Equal(t, diffs, decoded, "")
+
+	_, err = DiffsFromBaseJSON(data, "Too short")
+	assert.NotNil(t, err, "")
+	_, err = DiffsFromBaseJSON([]byte(`[[1,2,3]]`), text1)
+	assert.NotNil(t, err, "")
+	_, err = DiffsFromBaseJSON([]byte(`[[1,"x",3]]`), text1)
+	assert.NotNil(t, err, "")
+	// Lengths that overflow past the end.
+	_, err = DiffsFromBaseJSON([]byte(`[[0,1,9223372036854775807]]`), "abc")
+	assert.NotNil(t, err, "")
+}
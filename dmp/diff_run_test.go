@@ -0,0 +1,47 @@
+package dmp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffMaxDepth(t *testing.T) {
+	dmp := New()
+	dmp.DiffTimeout = 0
+	assert.Equal(t, []Diff{
+		{DiffEqual, "1"}, {DiffDelete, "a"}, {DiffInsert, "x"},
+		{DiffEqual, "2"}, {DiffDelete, "b"}, {DiffInsert, "y"},
+		{DiffEqual, "3"}, {DiffDelete, "c"}, {DiffInsert, "z"},
+		{DiffEqual, "4"}, {DiffDelete, "d"}, {DiffInsert, "w"},
+		{DiffEqual, "5"},
+	}, dmp.DiffMain("1a2b3c4d5", "1x2y3z4w5", false), "Unlimited.")
+
+	dmp.DiffMaxDepth = 1
+	assert.Equal(t, []Diff{
+		{DiffEqual, "1"}, {DiffDelete, "a2b"}, {DiffInsert, "x2y"},
+		{DiffEqual, "3"}, {DiffDelete, "c4d"}, {DiffInsert, "z4w"},
+		{DiffEqual, "5"},
+	}, dmp.DiffMain("1a2b3c4d5", "1x2y3z4w5", false), "One split.")
+}
+
+func TestDiffDeepSplits(t *testing.T) {
+	// Every other character differs, so the bisection splits the problem
+	// about once per character.
+	n := 2000
+	text1 := strings.Repeat("ab", n)
+	text2 := strings.Repeat("ac", n)
+	dmp := New()
+	dmp.DiffTimeout = 0
+	diffs := dmp.DiffMain(text1, text2, false)
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.Equal(t, 3*n, len(diffs), "")
+
+	dmp.DiffMaxDepth = 4
+	diffs = dmp.DiffMain(text1, text2, false)
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.True(t, len(diffs) < 3*n, "The budget stops the splitting.")
+}
@@ -0,0 +1,99 @@
+package dmp
+
+// Segment marks the start of a labelled region of a text, such as a
+// markdown section or a function body.  A segment runs until the start of
+// the next segment or the end of the text.  Text before the first segment
+// belongs to an implicit segment with an empty label.
+type Segment struct {
+	Label string
+	Start int
+}
+
+// SegmentDiff holds the diffs of one labelled region.
+type SegmentDiff struct {
+	Label string
+	Diffs []Diff
+}
+
+// DiffSegments diffs two segmented texts region by region.  Segments are
+// paired by label, in order; a segment only present in one text is
+// reported as a whole deletion or insertion.  Segments must be sorted by
+// Start.  Concatenating the diffs of all results yields a diff between
+// text1 and text2.
+func (dmp *DMP) DiffSegments(
+	text1 string, segs1 []Segment, text2 string, segs2 []Segment,
+) []SegmentDiff {
+	parts1 := splitSegments(text1, segs1)
+	parts2 := splitSegments(text2, segs2)
+
+	// Map each label to a rune so the label sequences can be diffed.
+	labelHash := map[string]rune{}
+	labelRunes := func(parts []segmentText) []rune {
+		runes := make([]rune, len(parts))
+		for i, p := range parts {
+			r, ok := labelHash[p.label]
+			if !ok {
+				r = rune(len(labelHash) + 1)
+				labelHash[p.label] = r
+			}
+			runes[i] = r
+		}
+		return runes
+	}
+	runes1 := labelRunes(parts1)
+	runes2 := labelRunes(parts2)
+
+	ret := []SegmentDiff{}
+	i1, i2 := 0, 0
+	for _, d := range dmp.DiffMainRunes(runes1, runes2, false) {
+		for range d.Text {
+			switch d.Type {
+			case DiffEqual:
+				p1, p2 := parts1[i1], parts2[i2]
+				ret = append(ret, SegmentDiff{
+					p1.label, dmp.DiffMain(p1.text, p2.text, false),
+				})
+				i1++
+				i2++
+			case DiffDelete:
+				p := parts1[i1]
+				ret = append(ret, SegmentDiff{
+					p.label, []Diff{{DiffDelete, p.text}},
+				})
+				i1++
+			case DiffInsert:
+				p := parts2[i2]
+				ret = append(ret, SegmentDiff{
+					p.label, []Diff{{DiffInsert, p.text}},
+				})
+				i2++
+			}
+		}
+	}
+	return ret
+}
+
+type segmentText struct {
+	label string
+	text  string
+}
+
+// splitSegments cuts text at the segment starts.  Empty regions are
+// dropped.
+func splitSegments(text string, segs []Segment) []segmentText {
+	ret := []segmentText{}
+	label := ""
+	start := 0
+	for _, seg := range segs {
+		end := max(start, min(seg.Start, len(text)))
+		if end > start {
+			ret = append(ret, segmentText{label, text[start:end]})
+		}
+		label = seg.Label
+		start = end
+	}
+	if start < len(text) {
+		ret = append(ret, segmentText{label, text[start:]})
+	}
+	return ret
+}
@@ -0,0 +1,45 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffSegments(t *testing.T) {
+	dmp := New()
+	text1 := "preface\n# A\nalpha\n# B\nbeta\n"
+	segs1 := []Segment{{"A", 8}, {"B", 18}}
+	text2 := "preface\n# A\nalpha!\n# C\ngamma\n# B\nbeta\n"
+	segs2 := []Segment{{"A", 8}, {"C", 19}, {"B", 29}}
+
+	result := dmp.DiffSegments(text1, segs1, text2, segs2)
+	assert.Equal(t, 4, len(result), "")
+	assert.Equal(t, SegmentDiff{"", []Diff{{DiffEqual, "preface\n"}}}, result[0], "")
+	assert.Equal(t, SegmentDiff{"A", []Diff{
+		{DiffEqual, "# A\nalpha"}, {DiffInsert, "!"}, {DiffEqual, "\n"},
+	}}, result[1], "")
+	assert.Equal(t, SegmentDiff{"C", []Diff{{DiffInsert, "# C\ngamma\n"}}}, result[2], "")
+	assert.Equal(t, SegmentDiff{"B", []Diff{{DiffEqual, "# B\nbeta\n"}}}, result[3], "")
+
+	var all []Diff
+	for _, r := range result {
+		all = append(all, r.Diffs...)
+	}
+	assert.Equal(t, text1, DiffText1(all), "")
+	assert.Equal(t, text2, DiffText2(all), "")
+
+	// Removed segment.
+	result = dmp.DiffSegments(text1, segs1, "preface\n# B\nbeta\n", []Segment{{"B", 8}})
+	assert.Equal(t, []SegmentDiff{
+		{"", []Diff{{DiffEqual, "preface\n"}}},
+		{"A", []Diff{{DiffDelete, "# A\nalpha\n"}}},
+		{"B", []Diff{{DiffEqual, "# B\nbeta\n"}}},
+	}, result, "")
+
+	// No segmentation at all.
+	result = dmp.DiffSegments("abc", nil, "abd", nil)
+	assert.Equal(t, []SegmentDiff{
+		{"", []Diff{{DiffEqual, "ab"}, {DiffDelete, "c"}, {DiffInsert, "d"}}},
+	}, result, "")
+}
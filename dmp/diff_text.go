@@ -8,11 +8,7 @@ import (
 // deletions).
 func DiffText1(diffs []Diff) string {
 	var ret bytes.Buffer
-	for _, d := range diffs {
-		if d.Type != DiffInsert {
-			ret.WriteString(d.Text)
-		}
-	}
+	DiffText1To(&ret, diffs)
 	return ret.String()
 }
 
@@ -20,10 +16,35 @@ func DiffText1(diffs []Diff) string {
 // insertions).
 func DiffText2(diffs []Diff) string {
 	var ret bytes.Buffer
+	DiffText2To(&ret, diffs)
+	return ret.String()
+}
+
+// DiffText1To appends the source text to buf, which can be reused across
+// calls to save allocations.
+func DiffText1To(buf *bytes.Buffer, diffs []Diff) {
+	diffTextTo(buf, diffs, DiffInsert)
+}
+
+// DiffText2To appends the destination text to buf, which can be reused
+// across calls to save allocations.
+func DiffText2To(buf *bytes.Buffer, diffs []Diff) {
+	diffTextTo(buf, diffs, DiffDelete)
+}
+
+// diffTextTo appends the text of all diffs to buf except those on the side
+// of skip: insertions and moves into place, or deletions and moves away.
+func diffTextTo(buf *bytes.Buffer, diffs []Diff, skip Operation) {
+	n := 0
 	for _, d := range diffs {
-		if d.Type != DiffDelete {
-			ret.WriteString(d.Text)
+		if d.Type*skip <= 0 {
+			n += len(d.Text)
+		}
+	}
+	buf.Grow(n)
+	for _, d := range diffs {
+		if d.Type*skip <= 0 {
+			buf.WriteString(d.Text)
 		}
 	}
-	return ret.String()
 }
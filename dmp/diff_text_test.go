@@ -0,0 +1,40 @@
+package dmp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffTextTo(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "jump"},
+		{DiffDelete, "s"},
+		{DiffInsert, "ed"},
+		{DiffEqual, " over "},
+		{DiffDelete, "the"},
+		{DiffInsert, "a"},
+		{DiffEqual, " lazy"}}
+
+	var buf bytes.Buffer
+	buf.WriteString("> ")
+	DiffText1To(&buf, diffs)
+	assert.Equal(t, "> jumps over the lazy", buf.String(), "Appends to the buffer.")
+
+	buf.Reset()
+	DiffText2To(&buf, diffs)
+	assert.Equal(t, "jumped over a lazy", buf.String(), "")
+}
+
+func Benchmark_PatchMakeApplyLarge(b *testing.B) {
+	s1 := readFile("speedtest1.txt", b)
+	s2 := readFile("speedtest2.txt", b)
+	dmp := New()
+	diffs := dmp.DiffMain(s1, s2, true)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dmp.Apply(dmp.PatchMake(s1, diffs), s1)
+	}
+}
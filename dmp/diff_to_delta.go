@@ -14,6 +14,7 @@ import (
 // Operations are tab-separated.  Inserted text is escaped using %xx
 // notation.
 func DiffToDelta(diffs []Diff) string {
+	diffs = withoutMoves(diffs)
 	var buf bytes.Buffer
 	for _, d := range diffs {
 		switch d.Type {
@@ -0,0 +1,111 @@
+package dmp
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// unifiedLine is one line of a line diff.
+type unifiedLine struct {
+	op   Operation
+	text string
+}
+
+// DiffUnified renders the line diff from text1 to text2 as the hunks of
+// diff -u, without the file header, with context unchanged lines around
+// the changed ones.  It is empty if the texts are the same.
+func (dmp *DMP) DiffUnified(text1, text2 string, context int) string {
+	var buf bytes.Buffer
+	dmp.DiffWriteUnified(&buf, text1, text2, context)
+	return buf.String()
+}
+
+// DiffWriteUnified writes the hunks of DiffUnified to w one hunk at a
+// time.  It returns the first write error.
+func (dmp *DMP) DiffWriteUnified(
+	w io.Writer, text1, text2 string, context int,
+) error {
+	ids1, ids2, lines := DiffLinesToTokens(text1, text2)
+	ls := []unifiedLine{}
+	for _, d := range DiffSlicesWith(dmp, ids1, ids2) {
+		for _, id := range d.Items {
+			ls = append(ls, unifiedLine{d.Type, lines[id]})
+		}
+	}
+	// num1[k] and num2[k] count the lines of text1 and text2 before ls[k].
+	num1 := make([]int, len(ls)+1)
+	num2 := make([]int, len(ls)+1)
+	for k, l := range ls {
+		num1[k+1], num2[k+1] = num1[k], num2[k]
+		if l.op != DiffInsert {
+			num1[k+1]++
+		}
+		if l.op != DiffDelete {
+			num2[k+1]++
+		}
+	}
+
+	var hunk bytes.Buffer
+	// End of the previous hunk, which the next one may not overlap.
+	floor := 0
+	for k := 0; k < len(ls); {
+		if ls[k].op == DiffEqual {
+			k++
+			continue
+		}
+		start, end := max(floor, k-context), k
+		for end < len(ls) {
+			if ls[end].op != DiffEqual {
+				end++
+				continue
+			}
+			run := 0
+			for end+run < len(ls) && ls[end+run].op == DiffEqual {
+				run++
+			}
+			if end+run < len(ls) && run <= 2*context {
+				end += run
+				continue
+			}
+			end += min(run, context)
+			break
+		}
+		hunk.Reset()
+		hunk.WriteString("@@ -" +
+			unifiedRange(num1[start], num1[end]-num1[start]) + " +" +
+			unifiedRange(num2[start], num2[end]-num2[start]) + " @@\n")
+		for _, l := range ls[start:end] {
+			switch l.op {
+			case DiffDelete:
+				hunk.WriteString("-")
+			case DiffInsert:
+				hunk.WriteString("+")
+			default:
+				hunk.WriteString(" ")
+			}
+			hunk.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				hunk.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		if _, err := hunk.WriteTo(w); err != nil {
+			return err
+		}
+		floor, k = end, end
+	}
+	return nil
+}
+
+// unifiedRange formats the range of n lines after line start of a hunk
+// header, as diff -u does.
+func unifiedRange(start, n int) string {
+	if n == 0 {
+		return strconv.Itoa(start) + ",0"
+	}
+	if n == 1 {
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(n)
+}
@@ -1,9 +1,16 @@
 package dmp
 
+import (
+	"sort"
+	"unicode/utf8"
+)
+
 // DiffXIndex. loc is a location in text1, comAdde and return the equivalent
-// location in text2.
+// location in text2.  Locations are byte offsets, as everywhere in this
+// package; see DiffXIndexRunes for offsets in runes.
 // e.g. "The cat" vs "The big cat", 1->1, 5->8
 func DiffXIndex(diffs []Diff, loc int) int {
+	diffs = withoutMoves(diffs)
 	chars1 := 0
 	chars2 := 0
 	lastChars1 := 0
@@ -34,3 +41,71 @@ func DiffXIndex(diffs []Diff, loc int) int {
 	// Add the remaining character length.
 	return lastChars2 + (loc - lastChars1)
 }
+
+// DiffXIndexRunes is DiffXIndex with locations counted in runes rather
+// than bytes, as by editors that index text by character.
+func DiffXIndexRunes(diffs []Diff, loc int) int {
+	return diffXIndexAll(diffs, []int{loc}, utf8.RuneCountInString)[0]
+}
+
+// DiffXIndexAll returns the DiffXIndex of each of locs, in one pass over
+// the diffs, for editors moving all their cursors and selections at once.
+// Locations in ascending order are mapped in linear time; others are
+// sorted first.
+func DiffXIndexAll(diffs []Diff, locs []int) []int {
+	return diffXIndexAll(diffs, locs, func(s string) int { return len(s) })
+}
+
+// DiffXIndexAllRunes is DiffXIndexAll with locations counted in runes.
+func DiffXIndexAllRunes(diffs []Diff, locs []int) []int {
+	return diffXIndexAll(diffs, locs, utf8.RuneCountInString)
+}
+
+// diffXIndexAll maps locs like DiffXIndex, measuring texts with length.
+func diffXIndexAll(
+	diffs []Diff, locs []int, length func(string) int,
+) []int {
+	diffs = withoutMoves(diffs)
+	order := make([]int, len(locs))
+	for i := range order {
+		order[i] = i
+	}
+	if !sort.IntsAreSorted(locs) {
+		sort.SliceStable(order, func(a, b int) bool {
+			return locs[order[a]] < locs[order[b]]
+		})
+	}
+
+	ret := make([]int, len(locs))
+	chars1, chars2 := 0, 0 // Up to diff i.
+	i := 0
+	n := -1 // The length of diff i, once measured.
+	for _, j := range order {
+		loc := locs[j]
+		for ; i < len(diffs); i++ {
+			if n < 0 {
+				n = length(diffs[i].Text)
+			}
+			end1 := chars1
+			if diffs[i].Type != DiffInsert {
+				end1 += n
+			}
+			if end1 > loc {
+				// Diff i overshoots the location.
+				break
+			}
+			chars1 = end1
+			if diffs[i].Type != DiffDelete {
+				chars2 += n
+			}
+			n = -1
+		}
+		if i < len(diffs) && diffs[i].Type == DiffDelete {
+			// The location was deleted.
+			ret[j] = chars2
+		} else {
+			ret[j] = chars2 + (loc - chars1)
+		}
+	}
+	return ret
+}
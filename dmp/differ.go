@@ -0,0 +1,28 @@
+package dmp
+
+import (
+	"time"
+)
+
+// Differ is a diff backend.  DiffRunes returns the differences between two
+// rune sequences, giving up on optimality once the deadline has passed.
+// The result must reproduce a as its source text and b as its destination
+// text.
+type Differ interface {
+	DiffRunes(a, b []rune, deadline time.Time) []Diff
+}
+
+// DiffRunes runs the built-in engine, ignoring any Differ set on dmp.  It
+// makes *DMP a Differ.
+func (dmp *DMP) DiffRunes(a, b []rune, deadline time.Time) []Diff {
+	return dmp.diffMainRunes(a, b, false, deadline)
+}
+
+// WithDiffer returns a copy of dmp whose DiffMain, and therefore PatchMake
+// and Apply, use the given backend.  A nil Differ selects the built-in
+// engine.
+func (dmp *DMP) WithDiffer(d Differ) *DMP {
+	ret := *dmp
+	ret.Differ = d
+	return &ret
+}
@@ -0,0 +1,43 @@
+package dmp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+// replaceDiffer is a trivial backend that replaces the whole text.
+type replaceDiffer struct {
+	calls int
+}
+
+func (r *replaceDiffer) DiffRunes(a, b []rune, deadline time.Time) []Diff {
+	r.calls++
+	return []Diff{{DiffDelete, string(a)}, {DiffInsert, string(b)}}
+}
+
+func TestDiffer(t *testing.T) {
+	var _ Differ = New()
+
+	dmp := New()
+	assertDiffEqual(t, []Diff{{DiffEqual, "ab"}, {DiffInsert, "c"}},
+		dmp.DiffRunes([]rune("ab"), []rune("abc"), deadline(time.Second)))
+
+	backend := &replaceDiffer{}
+	alt := dmp.WithDiffer(backend)
+	assert.True(t, dmp.Differ == nil, "WithDiffer should not modify the original.")
+
+	assertDiffEqual(t, []Diff{{DiffDelete, "ab"}, {DiffInsert, "abc"}},
+		alt.DiffMain("ab", "abc", false))
+	assertDiffEqual(t, []Diff{{DiffDelete, "ab"}, {DiffInsert, "abc"}},
+		alt.DiffMainRunes([]rune("ab"), []rune("abc"), false))
+	assert.Equal(t, 2, backend.calls, "")
+
+	// The patch layer goes through the backend too.
+	patches := alt.PatchMake("abc", "abd")
+	assert.Equal(t, 3, backend.calls, "")
+	assert.Equal(t, "@@ -1,3 +1,3 @@\n-abc\n+abd\n", PatchToText(patches), "")
+	text, _ := alt.Apply(patches, "abc")
+	assert.Equal(t, "abd", text, "")
+}
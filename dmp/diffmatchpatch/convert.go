@@ -0,0 +1,49 @@
+package diffmatchpatch
+
+import (
+	"github.com/sergi/go-diff/dmp"
+)
+
+// The types of this package are those of the dmp package, so values need
+// no conversion.  The converters below copy them, for code that keeps the
+// values of both packages apart, and to mark where it crosses over.
+
+// FromDMPDiff returns diff of the dmp package as a Diff of this package.
+func FromDMPDiff(diff dmp.Diff) Diff {
+	return diff
+}
+
+// ToDMPDiff returns diff as a Diff of the dmp package.
+func ToDMPDiff(diff Diff) dmp.Diff {
+	return diff
+}
+
+// FromDMPPatch returns a deep copy of patch of the dmp package.
+func FromDMPPatch(patch dmp.Patch) Patch {
+	return dmp.PatchDeepCopy([]dmp.Patch{patch})[0]
+}
+
+// ToDMPPatch returns a deep copy of patch for the dmp package.
+func ToDMPPatch(patch Patch) dmp.Patch {
+	return dmp.PatchDeepCopy([]Patch{patch})[0]
+}
+
+// FromDMPDiffs returns a copy of diffs of the dmp package.
+func FromDMPDiffs(diffs []dmp.Diff) []Diff {
+	return append([]Diff{}, diffs...)
+}
+
+// ToDMPDiffs returns a copy of diffs for the dmp package.
+func ToDMPDiffs(diffs []Diff) []dmp.Diff {
+	return append([]dmp.Diff{}, diffs...)
+}
+
+// FromDMPPatches returns a deep copy of patches of the dmp package.
+func FromDMPPatches(patches []dmp.Patch) []Patch {
+	return dmp.PatchDeepCopy(patches)
+}
+
+// ToDMPPatches returns a deep copy of patches for the dmp package.
+func ToDMPPatches(patches []Patch) []dmp.Patch {
+	return dmp.PatchDeepCopy(patches)
+}
@@ -0,0 +1,149 @@
+package diffmatchpatch
+
+import (
+	"time"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+// DiffMain finds the differences between two texts.
+func (d *DiffMatchPatch) DiffMain(
+	text1, text2 string, checklines bool,
+) []Diff {
+	return d.dmp().DiffMain(text1, text2, checklines)
+}
+
+// DiffMainRunes finds the differences between two rune sequences.
+func (d *DiffMatchPatch) DiffMainRunes(
+	text1, text2 []rune, checklines bool,
+) []Diff {
+	return d.dmp().DiffMainRunes(text1, text2, checklines)
+}
+
+// DiffBisect finds the 'middle snake' of a diff, splits the problem in two
+// and returns the recursively constructed diff.
+func (d *DiffMatchPatch) DiffBisect(
+	text1, text2 string, deadline time.Time,
+) []Diff {
+	return d.dmp().DiffBisect(text1, text2, deadline)
+}
+
+// DiffLinesToChars splits two texts into a list of strings, and reduces the
+// texts to a string of hashes where each Unicode character represents one
+// line.
+func (d *DiffMatchPatch) DiffLinesToChars(
+	text1, text2 string,
+) (string, string, []string) {
+	return dmp.DiffLinesToChars(text1, text2)
+}
+
+// DiffLinesToRunes splits two texts into a list of runes.
+func (d *DiffMatchPatch) DiffLinesToRunes(
+	text1, text2 string,
+) ([]rune, []rune, []string) {
+	return dmp.DiffLinesToRunes(text1, text2)
+}
+
+// DiffCharsToLines rehydrates the text in a diff from a string of line
+// hashes to real lines of text.
+func (d *DiffMatchPatch) DiffCharsToLines(
+	diffs []Diff, lineArray []string,
+) []Diff {
+	return dmp.DiffCharsToLines(diffs, lineArray)
+}
+
+// DiffCommonPrefix determines the common prefix length of two strings.
+func (d *DiffMatchPatch) DiffCommonPrefix(text1, text2 string) int {
+	return dmp.DiffCommonPrefix(text1, text2)
+}
+
+// DiffCommonSuffix determines the common suffix length of two strings.
+func (d *DiffMatchPatch) DiffCommonSuffix(text1, text2 string) int {
+	return dmp.DiffCommonSuffix(text1, text2)
+}
+
+// DiffCommonOverlap determines if the suffix of one string is the prefix
+// of another.
+func (d *DiffMatchPatch) DiffCommonOverlap(text1, text2 string) int {
+	return dmp.DiffCommonOverlap(text1, text2)
+}
+
+// DiffHalfMatch checks whether the two texts share a substring which is at
+// least half the length of the longer text.
+func (d *DiffMatchPatch) DiffHalfMatch(text1, text2 string) []string {
+	return d.dmp().DiffHalfMatch(text1, text2)
+}
+
+// DiffCleanupSemantic reduces the number of edits by eliminating
+// semantically trivial equalities.
+func (d *DiffMatchPatch) DiffCleanupSemantic(diffs []Diff) []Diff {
+	return d.dmp().DiffCleanupSemantic(diffs)
+}
+
+// DiffCleanupSemanticLossless looks for single edits surrounded on both
+// sides by equalities which can be shifted sideways to align the edit to a
+// word boundary.
+func (d *DiffMatchPatch) DiffCleanupSemanticLossless(diffs []Diff) []Diff {
+	return d.dmp().DiffCleanupSemanticLossless(diffs)
+}
+
+// DiffCleanupEfficiency reduces the number of edits by eliminating
+// operationally trivial equalities.
+func (d *DiffMatchPatch) DiffCleanupEfficiency(diffs []Diff) []Diff {
+	return d.dmp().DiffCleanupEfficiency(diffs)
+}
+
+// DiffCleanupMerge reorders and merges like edit sections.  Any edit
+// section can move as long as it doesn't cross an equality.
+func (d *DiffMatchPatch) DiffCleanupMerge(diffs []Diff) []Diff {
+	return d.dmp().DiffCleanupMerge(diffs)
+}
+
+// DiffXIndex returns the equivalent location in text2 of the location loc
+// in text1.
+func (d *DiffMatchPatch) DiffXIndex(diffs []Diff, loc int) int {
+	return dmp.DiffXIndex(diffs, loc)
+}
+
+// DiffPrettyHtml converts a []Diff into a pretty HTML report.
+func (d *DiffMatchPatch) DiffPrettyHtml(diffs []Diff) string {
+	return dmp.DiffPrettyHtml(diffs)
+}
+
+// DiffPrettyText converts a []Diff into a colored text report.
+func (d *DiffMatchPatch) DiffPrettyText(diffs []Diff) string {
+	return dmp.DiffPrettyText(diffs)
+}
+
+// DiffText1 computes and returns the source text (all equalities and
+// deletions).
+func (d *DiffMatchPatch) DiffText1(diffs []Diff) string {
+	return dmp.DiffText1(diffs)
+}
+
+// DiffText2 computes and returns the destination text (all equalities and
+// insertions).
+func (d *DiffMatchPatch) DiffText2(diffs []Diff) string {
+	return dmp.DiffText2(diffs)
+}
+
+// DiffLevenshtein computes the Levenshtein distance that is the number of
+// inserted, deleted or substituted characters.
+func (d *DiffMatchPatch) DiffLevenshtein(diffs []Diff) int {
+	return dmp.DiffLevenshtein(diffs)
+}
+
+// DiffToDelta crushes the diff into an encoded string which describes the
+// operations required to transform text1 into text2.
+func (d *DiffMatchPatch) DiffToDelta(diffs []Diff) string {
+	return dmp.DiffToDelta(diffs)
+}
+
+// DiffFromDelta given the original text1, and an encoded string which
+// describes the operations required to transform text1 into text2,
+// computes the full diff.
+func (d *DiffMatchPatch) DiffFromDelta(
+	text1, delta string,
+) ([]Diff, error) {
+	return dmp.DiffFromDelta(text1, delta)
+}
@@ -0,0 +1,84 @@
+// Package diffmatchpatch mirrors the API of the upstream
+// github.com/sergi/go-diff/diffmatchpatch package on top of the dmp
+// package, so that code written against upstream can switch to this
+// implementation by changing its import path only:
+//
+//	import "github.com/sergi/go-diff/dmp/diffmatchpatch"
+//
+// Diff, Operation and Patch are the types of the dmp package, so values
+// can be passed between the two APIs freely.  Unlike upstream, Patch has
+// no Start1, Start2, Length1 and Length2 fields but methods of these
+// names, so code that reads the coordinates of a patch must call them,
+// and code that sets them must use dmp.NewPatch.
+package diffmatchpatch
+
+import (
+	"time"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+// Diff is one operation of a diff.
+type Diff = dmp.Diff
+
+// Operation is the type of a Diff.
+type Operation = dmp.Operation
+
+// Patch is a change to a text, with its context.
+type Patch = dmp.Patch
+
+// The operations of a Diff.
+const (
+	DiffDelete = dmp.DiffDelete
+	DiffInsert = dmp.DiffInsert
+	DiffEqual  = dmp.DiffEqual
+)
+
+// DiffMatchPatch holds the settings of upstream, with the same meaning and
+// defaults.  The other settings of dmp.DMP keep their defaults.
+type DiffMatchPatch struct {
+	// Time to map a diff before giving up (0 for infinity).
+	DiffTimeout time.Duration
+	// Cost of an empty edit operation in terms of edit characters.
+	DiffEditCost int
+	// How far to search for a match (0 = exact location, 1000+= broad
+	// match).
+	MatchDistance int
+	// How close the contents of a large deletion have to be to match
+	// (0.0 = perfection, 1.0 = very loose).
+	PatchDeleteThreshold float64
+	// Chunk size for context length.
+	PatchMargin int
+	// The number of bits in an int.
+	MatchMaxBits int
+	// At what point is no match declared (0.0 = perfection, 1.0 = very
+	// loose).
+	MatchThreshold float64
+}
+
+// New creates a new DiffMatchPatch with the default settings.
+func New() *DiffMatchPatch {
+	d := dmp.New()
+	return &DiffMatchPatch{
+		DiffTimeout:          d.DiffTimeout,
+		DiffEditCost:         d.DiffEditCost,
+		MatchDistance:        d.MatchDistance,
+		PatchDeleteThreshold: d.PatchDeleteThreshold,
+		PatchMargin:          d.PatchMargin,
+		MatchMaxBits:         d.MatchMaxBits,
+		MatchThreshold:       d.MatchThreshold,
+	}
+}
+
+// dmp returns a dmp.DMP with the settings of d.
+func (d *DiffMatchPatch) dmp() *dmp.DMP {
+	e := dmp.New()
+	e.DiffTimeout = d.DiffTimeout
+	e.DiffEditCost = d.DiffEditCost
+	e.MatchDistance = d.MatchDistance
+	e.PatchDeleteThreshold = d.PatchDeleteThreshold
+	e.PatchMargin = d.PatchMargin
+	e.MatchMaxBits = d.MatchMaxBits
+	e.MatchThreshold = d.MatchThreshold
+	return e
+}
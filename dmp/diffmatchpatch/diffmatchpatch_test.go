@@ -0,0 +1,71 @@
+package diffmatchpatch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sergi/go-diff/dmp"
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffMatchPatch(t *testing.T) {
+	d := New()
+	assert.Equal(t, time.Second, d.DiffTimeout, "")
+	assert.Equal(t, 32, d.MatchMaxBits, "")
+
+	diffs := d.DiffMain("The cat sat.", "The hat sat.", false)
+	assert.Equal(t, []Diff{
+		{Type: DiffEqual, Text: "The "},
+		{Type: DiffDelete, Text: "c"},
+		{Type: DiffInsert, Text: "h"},
+		{Type: DiffEqual, Text: "at sat."},
+	}, diffs, "")
+	assert.Equal(t, "The hat sat.", d.DiffText2(diffs), "")
+	assert.Equal(t, 1, d.DiffLevenshtein(diffs), "")
+	delta := d.DiffToDelta(diffs)
+	back, err := d.DiffFromDelta("The cat sat.", delta)
+	assert.Nil(t, err, "")
+	assert.Equal(t, diffs, back, "")
+
+	// The values are those of the dmp package.
+	assert.Equal(t, dmp.New().DiffMain("abc", "abd", false),
+		d.DiffMain("abc", "abd", false), "")
+
+	patches := d.PatchMake("The cat sat.", "The hat sat.")
+	text := d.PatchToText(patches)
+	parsed, err := d.PatchFromText(text)
+	assert.Nil(t, err, "")
+	result, applied := d.PatchApply(parsed, "The cat sat down.")
+	assert.Equal(t, "The hat sat down.", result, "")
+	assert.Equal(t, []bool{true}, applied, "")
+
+	// The settings are passed on.
+	assert.Equal(t, 2, d.MatchMain("abcdef", "cxe", 0), "")
+	d.MatchThreshold = 0
+	assert.Equal(t, -1, d.MatchMain("abcdef", "cxe", 0), "")
+}
+
+func TestConvert(t *testing.T) {
+	diffs := dmp.New().DiffMain("The cat sat.", "The hat sat.", false)
+	converted := FromDMPDiffs(diffs)
+	assert.Equal(t, diffs, converted, "")
+	converted[0].Text = "A "
+	assert.Equal(t, "The ", diffs[0].Text, "Copied.")
+	assert.Equal(t, converted, ToDMPDiffs(converted), "")
+	assert.Equal(t, []dmp.Diff{}, ToDMPDiffs(nil), "")
+
+	patches := dmp.New().PatchMake("The cat sat.", "The hat sat.")
+	back := ToDMPPatches(FromDMPPatches(patches))
+	assert.Equal(t, dmp.PatchToText(patches), dmp.PatchToText(back), "")
+	result, applied := New().PatchApply(FromDMPPatches(patches),
+		"The cat sat.")
+	assert.Equal(t, "The hat sat.", result, "")
+	assert.Equal(t, []bool{true}, applied, "")
+	assert.Equal(t, []Patch{}, FromDMPPatches(nil), "")
+
+	assert.Equal(t, diffs[1], FromDMPDiff(diffs[1]), "")
+	assert.Equal(t, diffs[1], ToDMPDiff(diffs[1]), "")
+	patch := FromDMPPatch(patches[0])
+	back1 := ToDMPPatch(patch)
+	assert.Equal(t, patches[0].String(), back1.String(), "")
+}
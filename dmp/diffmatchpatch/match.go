@@ -0,0 +1,22 @@
+package diffmatchpatch
+
+import (
+	"github.com/sergi/go-diff/dmp"
+)
+
+// MatchMain locates the best instance of pattern in text near loc, or
+// returns -1.
+func (d *DiffMatchPatch) MatchMain(text, pattern string, loc int) int {
+	return d.dmp().MatchMain(text, pattern, loc)
+}
+
+// MatchBitap locates the best instance of pattern in text near loc using
+// the Bitap algorithm, or returns -1.
+func (d *DiffMatchPatch) MatchBitap(text, pattern string, loc int) int {
+	return d.dmp().MatchBitap(text, pattern, loc)
+}
+
+// MatchAlphabet initialises the alphabet for the Bitap algorithm.
+func (d *DiffMatchPatch) MatchAlphabet(pattern string) map[byte]int {
+	return dmp.MatchAlphabet(pattern)
+}
@@ -0,0 +1,56 @@
+package diffmatchpatch
+
+import (
+	"github.com/sergi/go-diff/dmp"
+)
+
+// PatchAddContext increases the context of patch until it is unique in
+// text, but doesn't let the pattern expand beyond MatchMaxBits.
+func (d *DiffMatchPatch) PatchAddContext(patch Patch, text string) Patch {
+	return d.dmp().PatchAddContext(patch, text)
+}
+
+// PatchMake computes a list of patches to turn text1 into text2, from
+// (text1, text2), (diffs), (text1, diffs) or (text1, text2, diffs).
+func (d *DiffMatchPatch) PatchMake(opt ...interface{}) []Patch {
+	return d.dmp().PatchMake(opt...)
+}
+
+// PatchDeepCopy returns an array that is identical to a given array of
+// patches.
+func (d *DiffMatchPatch) PatchDeepCopy(patches []Patch) []Patch {
+	return dmp.PatchDeepCopy(patches)
+}
+
+// PatchApply merges a set of patches onto the text.  Returns a patched
+// text, as well as an array of true/false values indicating which patches
+// were applied.
+func (d *DiffMatchPatch) PatchApply(
+	patches []Patch, text string,
+) (string, []bool) {
+	return d.dmp().Apply(patches, text)
+}
+
+// PatchAddPadding adds some padding on text start and end so that edges
+// can match something.
+func (d *DiffMatchPatch) PatchAddPadding(patches []Patch) string {
+	return d.dmp().PatchAddPadding(patches)
+}
+
+// PatchSplitMax looks through the patches and breaks up any which are
+// longer than the maximum limit of the match algorithm.
+func (d *DiffMatchPatch) PatchSplitMax(patches []Patch) []Patch {
+	return d.dmp().PatchSplitMax(patches)
+}
+
+// PatchToText takes a list of patches and returns a textual
+// representation.
+func (d *DiffMatchPatch) PatchToText(patches []Patch) string {
+	return dmp.PatchToText(patches)
+}
+
+// PatchFromText parses a textual representation of patches and returns a
+// list of Patch objects.
+func (d *DiffMatchPatch) PatchFromText(textline string) ([]Patch, error) {
+	return dmp.PatchFromText(textline)
+}
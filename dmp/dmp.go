@@ -11,67 +11,216 @@ import (
 
 // DiffMain finds the differences between two texts.
 func (dmp *DMP) DiffMain(s1, s2 string, checkLines bool) []Diff {
-	return dmp.diffMain(s1, s2, checkLines, deadline(dmp.DiffTimeout))
+	return dmp.diffDispatch(s1, s2, checkLines, deadline(dmp.DiffTimeout))
+}
+
+// diffDispatch is DiffMain with the given deadline, which DiffMainContext
+// and DiffMainBudget share: it applies the settings that rewrite the texts
+// and picks the engine.
+func (dmp *DMP) diffDispatch(
+	s1, s2 string, checkLines bool, end time.Time,
+) []Diff {
+	s1, s2 = dmp.eolTexts(s1, s2)
+	switch {
+	case dmp.ignoring():
+		return dmp.diffIgnoring(s1, s2, checkLines, end)
+	case dmp.DiffBytes:
+		return dmp.diffBytes(s1, s2, checkLines, end)
+	case dmp.Differ != nil:
+		return dmp.Differ.DiffRunes([]rune(s1), []rune(s2), end)
+	}
+	return dmp.diffMain(s1, s2, checkLines, end)
 }
 
 func (dmp *DMP) diffMain(
 	s1, s2 string, checkLines bool, deadline time.Time,
 ) []Diff {
+	if TextEqual(s1, s2) {
+		// Spare the conversion of large unchanged texts to runes.
+		diffs := []Diff{}
+		if len(s1) > 0 {
+			diffs = append(diffs, Diff{DiffEqual, s1})
+		}
+		return diffs
+	}
 	return dmp.diffMainRunes([]rune(s1), []rune(s2), checkLines, deadline)
 }
 
-// DiffMainRunes finds the differences between two rune sequences.
+// DiffMainRunes finds the differences between two rune sequences.  The
+// slices are neither modified nor retained; a Differ is handed copies
+// unless BorrowInputs is set.
 func (dmp *DMP) DiffMainRunes(s1, s2 []rune, checkLines bool) []Diff {
+	if dmp.Differ != nil {
+		if !dmp.BorrowInputs {
+			s1 = append([]rune(nil), s1...)
+			s2 = append([]rune(nil), s2...)
+		}
+		return dmp.Differ.DiffRunes(s1, s2, deadline(dmp.DiffTimeout))
+	}
 	return dmp.diffMainRunes(s1, s2, checkLines, deadline(dmp.DiffTimeout))
 }
 
 func (dmp *DMP) diffMainRunes(
 	s1, s2 []rune, checkLines bool, deadline time.Time,
 ) []Diff {
-	if runesEqual(s1, s2) {
-		var diffs []Diff
-		if len(s1) > 0 {
-			diffs = append(diffs, Diff{DiffEqual, string(s1)})
+	if dmp.DiffParallelism > 1 && dmp.sem == nil && dmp.budget == nil {
+		e := *dmp
+		e.sem = make(chan struct{}, dmp.DiffParallelism-1)
+		dmp = &e
+	}
+	diffs := dmp.diffRun(checkLines, deadline, diffTask{text1: s1, text2: s2})
+	return diffCleanupMerge(diffs)
+}
+
+// diffTask is a pending piece of work of diffRun: either two texts to diff,
+// diffs that are ready to be emitted, or diffs being computed by another
+// goroutine.
+type diffTask struct {
+	text1, text2 []rune
+	diffs        []Diff
+	pending      <-chan []Diff
+	// Number of splits that led to this task.
+	depth int
+}
+
+// diffSplit is a problem cut in two around a common middle.
+type diffSplit struct {
+	text1a, text2a []rune
+	text1b, text2b []rune
+	mid            []rune
+}
+
+// diffRun diffs the texts of tasks and concatenates the results.  Problems
+// that split in two are pushed back on an explicit stack instead of being
+// solved recursively, so adversarial inputs can not exhaust the goroutine
+// stack.  Once a task is DiffMaxDepth splits deep, it is reported as a
+// plain deletion and insertion.
+func (dmp *DMP) diffRun(
+	checkLines bool, deadline time.Time, tasks ...diffTask,
+) []Diff {
+	diffs := []Diff{}
+	stack := make([]diffTask, 0, len(tasks))
+	for i := len(tasks) - 1; i >= 0; i-- {
+		stack = append(stack, tasks[i])
+	}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if t.diffs != nil {
+			diffs = append(diffs, t.diffs...)
+			continue
 		}
-		return diffs
+		if t.pending != nil {
+			diffs = append(diffs, <-t.pending...)
+			continue
+		}
+
+		s1, s2 := t.text1, t.text2
+		if runesEqual(s1, s2) {
+			if len(s1) > 0 {
+				diffs = append(diffs, Diff{DiffEqual, string(s1)})
+			}
+			continue
+		}
+		// Trim off common prefix (speedup).
+		n := commonPrefixLength(s1, s2)
+		if n != 0 {
+			diffs = append(diffs, diffEq(string(s1[:n])))
+		}
+		s1 = s1[n:]
+		s2 = s2[n:]
+
+		// Trim off common suffix (speedup).  It is emitted after the middle
+		// block.
+		n = commonSuffixLength(s1, s2)
+		if n != 0 {
+			suffix := []Diff{diffEq(string(s1[len(s1)-n:]))}
+			stack = append(stack, diffTask{diffs: suffix})
+		}
+		s1 = s1[:len(s1)-n]
+		s2 = s2[:len(s2)-n]
+
+		if dmp.DiffMaxDepth > 0 && t.depth >= dmp.DiffMaxDepth {
+			diffs = appendReplace(diffs, s1, s2)
+			continue
+		}
+
+		// Compute the diff on the middle block.
+		middle, split := dmp.diffCompute(s1, s2, checkLines, deadline)
+		if split == nil {
+			diffs = append(diffs, middle...)
+			continue
+		}
+		// Send both halves off for separate processing, the first one on
+		// top of the stack.  The second one may go to another goroutine.
+		second := diffTask{
+			text1: split.text1b, text2: split.text2b, depth: t.depth + 1,
+		}
+		if pending := dmp.spawn(checkLines, deadline, second); pending != nil {
+			second = diffTask{pending: pending}
+		}
+		stack = append(stack, second)
+		if len(split.mid) != 0 {
+			mid := []Diff{{DiffEqual, string(split.mid)}}
+			stack = append(stack, diffTask{diffs: mid})
+		}
+		stack = append(stack, diffTask{
+			text1: split.text1a, text2: split.text2a, depth: t.depth + 1,
+		})
 	}
-	// Trim off common prefix (speedup).
-	n := commonPrefixLength(s1, s2)
-	prefix := s1[:n]
-	s1 = s1[n:]
-	s2 = s2[n:]
-
-	// Trim off common suffix (speedup).
-	n = commonSuffixLength(s1, s2)
-	suffix := s1[len(s1)-n:]
-	s1 = s1[:len(s1)-n]
-	s2 = s2[:len(s2)-n]
-
-	// Compute the diff on the middle block.
-	diffs := dmp.diffCompute(s1, s2, checkLines, deadline)
-
-	// Restore the prefix and suffix.
-	if len(prefix) != 0 {
-		diffs = diffPrepend(diffEq(string(prefix)), diffs)
+	return diffs
+}
+
+// Number of runes below which a problem is not worth a goroutine.
+const parallelMinRunes = 4096
+
+// spawn diffs t on a new goroutine if DiffParallelism leaves room for one
+// and t is large enough, and returns the channel of its diffs; otherwise
+// it returns nil.
+func (dmp *DMP) spawn(
+	checkLines bool, deadline time.Time, t diffTask,
+) <-chan []Diff {
+	if dmp.sem == nil || len(t.text1)+len(t.text2) < parallelMinRunes {
+		return nil
 	}
-	if len(suffix) != 0 {
-		diffs = diffAppend(diffs, diffEq(string(suffix)))
+	select {
+	case dmp.sem <- struct{}{}:
+	default:
+		return nil
 	}
-	return DiffCleanupMerge(diffs)
+	ch := make(chan []Diff, 1)
+	go func() {
+		ch <- dmp.diffRun(checkLines, deadline, t)
+		<-dmp.sem
+	}()
+	return ch
+}
+
+// appendReplace appends the deletion of text1 and the insertion of text2,
+// skipping empty texts.
+func appendReplace(diffs []Diff, text1, text2 []rune) []Diff {
+	if len(text1) != 0 {
+		diffs = append(diffs, Diff{DiffDelete, string(text1)})
+	}
+	if len(text2) != 0 {
+		diffs = append(diffs, Diff{DiffInsert, string(text2)})
+	}
+	return diffs
 }
 
 // diffCompute finds the differences between two rune slices.  Assumes that
-// the texts do not have any common prefix or suffix.
+// the texts do not have any common prefix or suffix.  If the problem is
+// best solved in two halves, it returns them instead.
 func (dmp *DMP) diffCompute(
 	text1, text2 []rune, checkLines bool, deadline time.Time,
-) []Diff {
+) ([]Diff, *diffSplit) {
 	diffs := []Diff{}
 	if len(text1) == 0 {
 		// Just add some text (speedup).
-		return append(diffs, Diff{DiffInsert, string(text2)})
+		return append(diffs, Diff{DiffInsert, string(text2)}), nil
 	} else if len(text2) == 0 {
 		// Just delete some text (speedup).
-		return append(diffs, Diff{DiffDelete, string(text1)})
+		return append(diffs, Diff{DiffDelete, string(text1)}), nil
 	}
 
 	var longtext, shorttext []rune
@@ -83,7 +232,7 @@ func (dmp *DMP) diffCompute(
 		shorttext = text1
 	}
 
-	if i := runesIndex(longtext, shorttext); i != -1 {
+	if i := dmp.runesIndex(longtext, shorttext); i != -1 {
 		op := DiffInsert
 		// Swap insertions for deletions if diff is reversed.
 		if len(text1) > len(text2) {
@@ -94,47 +243,46 @@ func (dmp *DMP) diffCompute(
 			{op, string(longtext[:i])},
 			{DiffEqual, string(shorttext)},
 			{op, string(longtext[i+len(shorttext):])},
-		}
+		}, nil
 	} else if len(shorttext) == 1 {
 		// Single character string.
 		// After the previous speedup, the character can't be an equality.
 		return []Diff{
 			{DiffDelete, string(text1)},
 			{DiffInsert, string(text2)},
-		}
+		}, nil
 		// Check to see if the problem can be split in two.
 	} else if hm := diffHalfMatch(dmp, text1, text2); hm != nil {
 		// A half-match was found, sort out the return data.
-		text1_a := hm[0]
-		text1_b := hm[1]
-		text2_a := hm[2]
-		text2_b := hm[3]
-		mid_common := hm[4]
-		// Send both pairs off for separate processing.
-		diffs_a := dmp.diffMainRunes(text1_a, text2_a, checkLines, deadline)
-		diffs_b := dmp.diffMainRunes(text1_b, text2_b, checkLines, deadline)
-		// Merge the results.
-		return append(diffs_a, append(
-			[]Diff{{DiffEqual, string(mid_common)}}, diffs_b...,
-		)...)
+		return nil, &diffSplit{
+			text1a: hm[0], text1b: hm[1],
+			text2a: hm[2], text2b: hm[3],
+			mid: hm[4],
+		}
 	} else if checkLines && len(text1) > 100 && len(text2) > 100 {
-		return dmp.diffLineMode(text1, text2, deadline)
+		return dmp.diffLineMode(text1, text2, deadline), nil
 	}
-	return dmp.diffBisect(text1, text2, deadline)
+	if x, y, ok := diffSplitPoint(dmp, text1, text2, deadline); ok {
+		return nil, &diffSplit{
+			text1a: text1[:x], text2a: text2[:y],
+			text1b: text1[x:], text2b: text2[y:],
+		}
+	}
+	return []Diff{
+		{DiffDelete, string(text1)},
+		{DiffInsert, string(text2)},
+	}, nil
 }
 
 // diffLineMode does a quick line-level diff on both []runes, then rediff the
 // parts for greater accuracy. This speedup can produce non-minimal diffs.
 func (dmp *DMP) diffLineMode(text1, text2 []rune, deadline time.Time) []Diff {
 	// Scan the text on a line-by-line basis first.
-	text1, text2, linearray := diffLinesToRunes(text1, text2)
-
-	diffs := dmp.diffMainRunes(text1, text2, false, deadline)
-
-	// Convert the diff back to original text.
-	diffs = DiffCharsToLines(diffs, linearray)
+	diffs := dmp.diffLines(
+		string(text1), string(text2), dmp.DiffMaxLineLength, deadline,
+	)
 	// Eliminate freak matches (e.g. blank lines)
-	diffs = DiffCleanupSemantic(diffs)
+	diffs = diffCleanupSemantic(diffs, semanticOptions{})
 
 	// Rediff any replacement blocks, this time character-by-character.
 	// Add a dummy entry at the end.
@@ -162,7 +310,7 @@ func (dmp *DMP) diffLineMode(text1, text2 []rune, deadline time.Time) []Diff {
 					count_delete+count_insert)
 
 				pointer = pointer - count_delete - count_insert
-				a := dmp.diffMain(text_delete, text_insert, false, deadline)
+				a := dmp.diffReplacedLines(text_delete, text_insert, deadline)
 				for j := len(a) - 1; j >= 0; j-- {
 					diffs = splice(diffs, pointer, 0, a[j])
 				}
@@ -192,12 +340,53 @@ func (dmp *DMP) DiffBisect(s1, s2 string, deadline time.Time) []Diff {
 // and returns the recursively constructed diff.
 // See Myers's 1986 paper: An O(ND) Difference Algorithm and Its Variations.
 func (dmp *DMP) diffBisect(s1, s2 []rune, deadline time.Time) []Diff {
+	if x, y, ok := diffSplitPoint(dmp, s1, s2, deadline); ok {
+		return dmp.diffBisectSplit(s1, s2, x, y, deadline)
+	}
+	// Diff took too long and hit the deadline or
+	// number of diffs equals number of characters, no commonality at all.
+	return []Diff{
+		{DiffDelete, string(s1)},
+		{DiffInsert, string(s2)},
+	}
+}
+
+// diffMiddleSnake returns the point where the 'middle snake' of a diff
+// splits s1 and s2, or false if the deadline was reached, the context or
+// budget of dmp ran out or the texts have nothing in common.
+func diffMiddleSnake[T comparable](
+	dmp *DMP, s1, s2 []T, deadline time.Time,
+) (int, int, bool) {
+	dmax := (len(s1) + len(s2) + 1) / 2
+	// Smaller bands can not hold the starting points of the paths.
+	band := max(dmp.DiffBand, 2)
+	if dmp.DiffBand > 0 && band < dmax && abs(len(s1)-len(s2)) <= 2*band {
+		x, y, ok, exceeded := diffBandedSnake(dmp, s1, s2, deadline, band)
+		if !exceeded {
+			return x, y, ok
+		}
+	}
+	x, y, ok, _ := diffBandedSnake(dmp, s1, s2, deadline, dmax)
+	return x, y, ok
+}
+
+// diffBandedSnake searches the middle snake of s1 and s2 along the paths of
+// at most band edits from either end, which keeps to the diagonals within
+// band of both corners.  It allocates arrays of the size of the band rather
+// than of the texts.  exceeded tells that the paths did not meet within the
+// band, and that the search should be made again with a wider one.
+func diffBandedSnake[T comparable](
+	dmp *DMP, s1, s2 []T, deadline time.Time, band int,
+) (x, y int, ok, exceeded bool) {
 	// Cache the text lengths to prevent multiple calls.
 	len1, len2 := len(s1), len(s2)
 
 	dmax := (len1 + len2 + 1) / 2
-	offset := dmax
-	vlen := 2 * dmax
+	offset := band
+	vlen := 2 * band
+	if !dmp.budget.alloc(2 * vlen * intSize) {
+		return 0, 0, false, false
+	}
 
 	v1 := make([]int, vlen)
 	v2 := make([]int, vlen)
@@ -218,10 +407,12 @@ func (dmp *DMP) diffBisect(s1, s2 []rune, deadline time.Time) []Diff {
 	k1end := 0
 	k2start := 0
 	k2end := 0
-	for d := 0; d < dmax; d++ {
+	for d := 0; d < band; d++ {
 		// Bail out if deadline is reached.
-		if time.Now().After(deadline) {
-			break
+		if time.Now().After(deadline) || isDone(dmp.done) ||
+			!dmp.budget.step() {
+			dmp.budget.cut()
+			return 0, 0, false, false
 		}
 
 		// Walk the front path one step.
@@ -258,9 +449,7 @@ func (dmp *DMP) diffBisect(s1, s2 []rune, deadline time.Time) []Diff {
 					x2 := len1 - v2[k2_offset]
 					if x1 >= x2 {
 						// Overlap detected.
-						return dmp.diffBisectSplit(
-							s1, s2, x1, y1, deadline,
-						)
+						return x1, y1, true, false
 					}
 				}
 			}
@@ -299,39 +488,26 @@ func (dmp *DMP) diffBisect(s1, s2 []rune, deadline time.Time) []Diff {
 					x2 = len1 - x2
 					if x1 >= x2 {
 						// Overlap detected.
-						return dmp.diffBisectSplit(
-							s1, s2, x1, y1, deadline,
-						)
+						return x1, y1, true, false
 					}
 				}
 			}
 		}
 	}
-	// Diff took too long and hit the deadline or
-	// number of diffs equals number of characters, no commonality at all.
-	return []Diff{
-		{DiffDelete, string(s1)},
-		{DiffInsert, string(s2)},
-	}
+	return 0, 0, false, band < dmax
 }
 
 func (dmp *DMP) diffBisectSplit(runes1, runes2 []rune, x, y int,
 	deadline time.Time) []Diff {
-	runes1a := runes1[:x]
-	runes2a := runes2[:y]
-	runes1b := runes1[x:]
-	runes2b := runes2[y:]
-
-	// Compute both diffs serially.
-	diffs := dmp.diffMainRunes(runes1a, runes2a, false, deadline)
-	diffsb := dmp.diffMainRunes(runes1b, runes2b, false, deadline)
-
-	return append(diffs, diffsb...)
+	return diffCleanupMerge(dmp.diffRun(false, deadline,
+		diffTask{text1: runes1[:x], text2: runes2[:y]},
+		diffTask{text1: runes1[x:], text2: runes2[y:]},
+	))
 }
 
 // DiffHalfMatch checks whether the two texts share a substring which is at
-// least half the length of the longer text. This speedup can produce
-// non-minimal diffs.
+// least half (DiffHalfMatchRatio) the length of the longer text. This
+// speedup can produce non-minimal diffs.
 func (dmp *DMP) DiffHalfMatch(text1, text2 string) []string {
 	// Unused in this code, but retained for interface compatibility.
 	rs := diffHalfMatch(dmp, []rune(text1), []rune(text2))
@@ -347,9 +523,50 @@ func (dmp *DMP) DiffHalfMatch(text1, text2 string) []string {
 }
 
 // DiffCleanupEfficiency reduces the number of edits by eliminating
-// operationally trivial equalities.
+// operationally trivial equalities.  The given slice is only modified if
+// BorrowInputs is set.
 func (dmp *DMP) DiffCleanupEfficiency(diffs []Diff) []Diff {
-	return diffCleanupEfficiency(diffs, dmp.DiffEditCost)
+	diffs = withoutMoves(diffs)
+	return diffCleanupEfficiency(dmp.ownDiffs(diffs), dmp.DiffEditCost)
+}
+
+// DiffCleanupMerge is the function of the same name, working in place if
+// BorrowInputs is set.
+func (dmp *DMP) DiffCleanupMerge(diffs []Diff) []Diff {
+	diffs = withoutMoves(diffs)
+	return diffCleanupMerge(dmp.ownDiffs(diffs))
+}
+
+// DiffCleanupSemantic is the function of the same name, working in place if
+// BorrowInputs is set, with the DiffSemantic... settings of dmp.
+func (dmp *DMP) DiffCleanupSemantic(diffs []Diff) []Diff {
+	diffs = withoutMoves(diffs)
+	return diffCleanupSemantic(dmp.ownDiffs(diffs), dmp.semanticOptions())
+}
+
+// DiffCleanupSemanticLossless is the function of the same name, working in
+// place if BorrowInputs is set, with the DiffSemanticScore of dmp.
+func (dmp *DMP) DiffCleanupSemanticLossless(diffs []Diff) []Diff {
+	diffs = withoutMoves(diffs)
+	return diffCleanupSemanticLossless(
+		dmp.ownDiffs(diffs), dmp.DiffSemanticScore,
+	)
+}
+
+// ownDiffs returns diffs, or a copy of it unless BorrowInputs is set.
+func (dmp *DMP) ownDiffs(diffs []Diff) []Diff {
+	if dmp.BorrowInputs {
+		return diffs
+	}
+	return copyDiffs(diffs)
+}
+
+// DiffCleanupFragments merges micro-edits separated by tiny equalities
+// into larger replacements.  The given slice is only returned as is if
+// BorrowInputs is set.
+func (dmp *DMP) DiffCleanupFragments(diffs []Diff) []Diff {
+	diffs = withoutMoves(diffs)
+	return diffCleanupFragments(dmp.ownDiffs(diffs), dmp.DiffFragmentGap)
 }
 
 //  MATCH FUNCTIONS
@@ -357,6 +574,10 @@ func (dmp *DMP) DiffCleanupEfficiency(diffs []Diff) []Diff {
 // MatchMain locates the best instance of 'pattern' in 'text' near 'loc'.
 // Returns -1 if no match found.
 func (dmp *DMP) MatchMain(s, pattern string, loc int) int {
+	return matchMain(dmp, s, pattern, loc, nil)
+}
+
+func matchMain(dmp *DMP, s, pattern string, loc int, mi *matchIndex) int {
 	// Check for null inputs not needed since null can't be passed in C#.
 
 	loc = int(math.Max(0, math.Min(float64(loc), float64(len(s)))))
@@ -372,13 +593,13 @@ func (dmp *DMP) MatchMain(s, pattern string, loc int) int {
 		return loc
 	}
 	// Do a fuzzy compare.
-	return dmp.MatchBitap(s, pattern, loc)
+	return matchBitap(dmp, s, pattern, loc, mi)
 }
 
 // MatchBitap locates the best instance of 'pattern' in 'text' near 'loc'
 // using the Bitap algorithm.  Returns -1 if no match found.
 func (dmp *DMP) MatchBitap(text, pattern string, loc int) int {
-	return matchBitap(dmp, text, pattern, loc)
+	return matchBitap(dmp, text, pattern, loc, nil)
 }
 
 //  PATCH FUNCTIONS
@@ -393,6 +614,7 @@ func (dmp *DMP) PatchMake(opt ...interface{}) []Patch {
 	switch len(opt) {
 	case 1:
 		diffs, _ := opt[0].([]Diff)
+		diffs = withoutMoves(diffs)
 		text1 := DiffText1(diffs)
 		return dmp.PatchMake(text1, diffs)
 
@@ -400,14 +622,14 @@ func (dmp *DMP) PatchMake(opt ...interface{}) []Patch {
 		text1 := opt[0].(string)
 		switch t := opt[1].(type) {
 		case string:
-			diffs := dmp.DiffMain(text1, t, true)
+			diffs := dmp.exact().DiffMain(text1, t, true)
 			if len(diffs) > 2 {
-				diffs = DiffCleanupSemantic(diffs)
-				diffs = dmp.DiffCleanupEfficiency(diffs)
+				diffs = diffCleanupSemantic(diffs, dmp.semanticOptions())
+				diffs = diffCleanupEfficiency(diffs, dmp.DiffEditCost)
 			}
 			return dmp.PatchMake(text1, diffs)
 		case []Diff:
-			return patchMake2(dmp, text1, t)
+			return patchMake2(dmp, text1, withoutMoves(t))
 		}
 
 	case 3:
@@ -420,118 +642,68 @@ func (dmp *DMP) PatchMake(opt ...interface{}) []Patch {
 // as well as an array of true/false values indicating which patches were
 // applied.
 func (dmp *DMP) Apply(ps []Patch, s string) (string, []bool) {
-	if len(ps) == 0 {
-		return s, []bool{}
+	s, results, _ := patchApply(dmp, ps, s, applyOptions{})
+	applied := make([]bool, len(results))
+	for i, r := range results {
+		applied[i] = r.Applied
 	}
+	return s, applied
+}
 
-	// Deep copy the patches so that no changes are made to originals.
-	ps = PatchDeepCopy(ps)
-
-	nullPadding := patchAddPadding(ps, dmp.PatchMargin)
-	s = nullPadding + s + nullPadding
-	ps = patchSplitMax(ps, dmp.MatchMaxBits, dmp.PatchMargin)
-
-	x := 0
-	// delta keeps track of the offset between the expected and actual
-	// location of the previous patch.  If there are patches expected at
-	// positions 10 and 20, but the first patch was found at 12, delta is 2
-	// and the second patch has an effective expected position of 22.
-	delta := 0
-	results := make([]bool, len(ps))
-	for _, p := range ps {
-		expected_loc := p.start2 + delta
-		text1 := DiffText1(p.diffs)
-		var startLoc int
-		endLoc := -1
-		if len(text1) > dmp.MatchMaxBits {
-			// PatchSplitMax will only provide an oversized pattern
-			// in the case of a monster delete.
-			startLoc = dmp.MatchMain(
-				s, text1[:dmp.MatchMaxBits], expected_loc,
-			)
-			if startLoc != -1 {
-				endLoc = dmp.MatchMain(
-					s, text1[len(text1)-dmp.MatchMaxBits:],
-					expected_loc+len(text1)-dmp.MatchMaxBits,
-				)
-				if endLoc == -1 || startLoc >= endLoc {
-					// Can't find valid trailing context.  Drop this patch.
-					startLoc = -1
-				}
-			}
-		} else {
-			startLoc = dmp.MatchMain(s, text1, expected_loc)
-		}
-		if startLoc == -1 {
-			// No match found.  :(
-			results[x] = false
-			// Subtract the delta for this failed patch from subsequent
-			// patches.
-			delta -= p.length2 - p.length1
-		} else {
-			// Found a match.  :)
-			results[x] = true
-			delta = startLoc - expected_loc
-			var text2 string
-			if endLoc == -1 {
-				text2 = s[startLoc:int(math.Min(float64(startLoc+len(text1)),
-					float64(len(s))))]
-			} else {
-				text2 = s[startLoc:int(math.Min(float64(endLoc+dmp.MatchMaxBits),
-					float64(len(s))))]
-			}
-			if text1 == text2 {
-				// Perfect match, just shove the Replacement text in.
-				s = s[:startLoc] + DiffText2(p.diffs) +
-					s[startLoc+len(text1):]
-			} else {
-				// Imperfect match.  Run a diff to get a framework of
-				// equivalent indices.
-				diffs := dmp.DiffMain(text1, text2, false)
-				if len(text1) > dmp.MatchMaxBits &&
-					float64(DiffLevenshtein(diffs))/float64(len(text1)) >
-						dmp.PatchDeleteThreshold {
-					// The end points match, but the content is unacceptably
-					// bad.
-					results[x] = false
-				} else {
-					diffs = DiffCleanupSemanticLossless(diffs)
-					index1 := 0
-					for _, d := range p.diffs {
-						if d.Type != DiffEqual {
-							index2 := DiffXIndex(diffs, index1)
-							if d.Type == DiffInsert {
-								// Insertion
-								s = s[:startLoc+index2] +
-									d.Text + s[startLoc+index2:]
-							} else if d.Type == DiffDelete {
-								// Deletion
-								startIndex := startLoc + index2
-								s = s[:startIndex] +
-									s[startIndex+DiffXIndex(
-										diffs,
-										index1+len(d.Text),
-									)-index2:]
-							}
-						}
-						if d.Type != DiffDelete {
-							index1 += len(d.Text)
-						}
-					}
-				}
-			}
-		}
-		x++
-	}
-	// Strip the padding off.
-	s = s[len(nullPadding) : len(nullPadding)+(len(s)-2*len(nullPadding))]
+// ApplyDetailed merges a set of patches onto the text like Apply, but
+// reports a PatchResult for each patch, including how confident the match
+// was.
+func (dmp *DMP) ApplyDetailed(ps []Patch, s string) (string, []PatchResult) {
+	s, results, _ := patchApply(dmp, ps, s, applyOptions{})
 	return s, results
 }
 
+// ApplyWithStats merges a set of patches onto the text like ApplyDetailed,
+// and also reports how much padding, splitting and drift the patches
+// needed.
+func (dmp *DMP) ApplyWithStats(ps []Patch, s string) (
+	string, []PatchResult, ApplyStats,
+) {
+	return patchApply(dmp, ps, s, applyOptions{})
+}
+
+// ApplyProtected merges a set of patches onto the text like ApplyDetailed,
+// but skips any patch that would modify one of the protected byte ranges
+// of s, such as a license header.  Skipped patches are reported as
+// Blocked.
+func (dmp *DMP) ApplyProtected(ps []Patch, s string, protected []Range) (
+	string, []PatchResult,
+) {
+	s, results, _ := patchApply(
+		dmp, ps, s, applyOptions{protected: protected},
+	)
+	return s, results
+}
+
+// ApplyWithOptions merges a set of patches onto the text like ApplyDetailed,
+// with the settings of opts.
+func (dmp *DMP) ApplyWithOptions(ps []Patch, s string, opts ApplyOptions) (
+	string, []PatchResult,
+) {
+	limit := opts.ExactContext || opts.MaxFuzz > 0
+	if opts.ExactContext {
+		opts.MaxFuzz = 0
+	}
+	patched, results, _ := patchApply(dmp, ps, s, applyOptions{
+		ApplyOptions: opts,
+		limitFuzz:    limit,
+	})
+	if opts.DryRun {
+		return s, results
+	}
+	return patched, results
+}
+
 // PatchAddPadding adds some padding on text start and end so that edges can
-// match something.  Intended to be called only from within patch_apply.
+// match something, and returns the padding, which Apply adds to both ends
+// of the target text.  Intended to be called only from within patch_apply.
 func (dmp *DMP) PatchAddPadding(ps []Patch) string {
-	return patchAddPadding(ps, dmp.PatchMargin)
+	return patchAddPadding(ps, patchPadding(dmp))
 }
 
 // PatchSplitMax looks through the patches and breaks up any which are longer
@@ -11,6 +11,10 @@ type DMP struct {
 	// Cost of an empty edit operation in terms of edit characters.
 	DiffEditCost int
 
+	// Equalities of at most this many characters between two edits are
+	// merged into the edits by DiffCleanupFragments (0 to disable).
+	DiffFragmentGap int
+
 	// How far to search for a match (0 = exact location, 1000+= broad match).
 	// A match this many characters away from the expected location will add
 	// 1.0 to the score (0.0 is a perfect match).
@@ -25,12 +29,140 @@ type DMP struct {
 	// Chunk size for context length.
 	PatchMargin int
 
+	// PatchMake starts a new patch at equalities of at least this many
+	// bytes; shorter ones are kept inside the current patch (0 for twice
+	// PatchMargin).  1 gives one patch per change, large values fewer,
+	// larger patches.
+	PatchHunkBreakThreshold int
+
+	// Text added around the target text by Apply so that patches at the
+	// edges have context to match (empty for PatchMargin control
+	// characters \x01, \x02, ...).  It should not occur in the texts.
+	PatchPadding string
+
+	// Minimum length of the common substring, relative to the longer text,
+	// for the half-match speedup to split the problem (0.5 by default).
+	// Lower values split more often, trading minimality for speed.
+	DiffHalfMatchRatio float64
+
+	// Lines longer than this many bytes are split into smaller tokens in
+	// line mode, after punctuation such as commas and braces or else at
+	// this length (0 for no limit).  This keeps line mode effective on
+	// minified content that has few, very long lines.
+	DiffMaxLineLength int
+
+	// In line mode, pair each replaced line with the most similar
+	// replacing line before diffing them character by character, so that
+	// the edits of one line are not smeared over its neighbours.
+	DiffPairLines bool
+
+	// Maximum number of times a diff is split in two, by the half-match
+	// speedup or the bisection, before the remaining pieces are reported
+	// as plain replacements (0 for no limit).  Bounds the work spent on
+	// adversarial inputs.
+	DiffMaxDepth int
+
+	// How the bisection splits a diff in two.  AlgorithmHirschberg needs
+	// less memory than the default on very large texts, but more time.
+	DiffAlgorithm Algorithm
+
+	// Equalities of at least this many bytes are kept by the semantic
+	// cleanup, however large the edits around them (0 for no minimum).
+	DiffSemanticMinEquality int
+
+	// Scores the boundaries the semantic cleanup shifts edits to, from
+	// the text before to the text after (nil for SemanticBoundaryScore).
+	// Scripts without spaces between words, such as Chinese and
+	// Japanese, may need their own notion of a boundary.
+	DiffSemanticScore func(one, two string) int
+
+	// Keeps the semantic cleanup from turning the overlap of a deletion
+	// and an insertion into an equality.
+	DiffSemanticNoOverlaps bool
+
+	// Maximum number of edits the bisection first looks for between the
+	// ends and the middle of a diff, searching only the diagonals within
+	// that distance of the corners (0 for no limit).  Near-identical large
+	// texts are then diffed in memory and time proportional to the band
+	// rather than to their length; texts that differ more are searched
+	// again in full.
+	DiffBand int
+
+	// Maximum number of goroutines DiffMain may use (0 or 1 to diff on
+	// the calling goroutine only).  Once split in two, large problems are
+	// diffed concurrently.  Diffs with a Budget stay serial.
+	DiffParallelism int
+
+	// Lets the DiffCleanup methods work in place on the slice they are
+	// given, and DiffMainRunes hand its slices to a Differ without copying
+	// them.  By default the caller's slices are never modified or
+	// retained.
+	BorrowInputs bool
+
+	// Diff backend used by DiffMain and DiffMainRunes (nil for the built-in
+	// engine).
+	Differ Differ
+
+	// What Apply does when the text of a patch occurs more than once in
+	// the target text.
+	PatchAmbiguity AmbiguityPolicy
+
+	// Whether Apply re-indents a patch whose text is not found as is, but
+	// is once the leading indentation of its lines is uniformly changed,
+	// as in a file whose code was moved into or out of a block.  The
+	// lines it inserts are then indented the same way, and the change is
+	// reported in PatchResult.
+	PatchAdaptIndent bool
+
 	// The number of bits in an int.
 	MatchMaxBits int
 
 	// At what point is no match declared (0.0 = perfection, 1.0 = very
 	// loose).
 	MatchThreshold float64
+
+	// Whether DiffMain compares texts byte by byte instead of rune by
+	// rune.  The diffs then reproduce the texts byte for byte even if they
+	// are not valid UTF-8, as legacy files of mixed encodings are not, at
+	// the cost of edits that may cut multi-byte characters.
+	DiffBytes bool
+
+	// Whether DiffMainChecked, PatchMakeChecked, ApplyChecked and the
+	// ...Context methods reject texts that are not valid UTF-8 with an
+	// *InvalidUTF8Error.  Diffs work on runes and turn invalid bytes into
+	// U+FFFD, so such texts do not survive a round trip.
+	StrictUTF8 bool
+
+	// Whether DiffMain, DiffMainContext and DiffMainBudget convert CRLF
+	// and CR line endings to LF before diffing, so that differences of
+	// line endings alone vanish.  The diffs are then of the converted
+	// texts.  PatchMake, Apply, PatchRebase, ReDiff and Merge3, which
+	// must rebuild the texts from the diffs, diff the texts as they are.
+	NormalizeEOL bool
+
+	// Whether DiffMain, DiffMainContext and DiffMainBudget ignore
+	// differences of case, of whitespace other than line breaks, as
+	// diff -w does, and of blank lines.  The texts are compared
+	// normalized, and the diffs mapped back onto them: deletions and
+	// equalities reproduce text1 and insertions hold the text of text2,
+	// but the ignored differences of text2 within equalities are lost.
+	// PatchMake, Apply, PatchRebase, ReDiff and Merge3, which must
+	// rebuild the texts from the diffs, diff the texts as they are.
+	DiffIgnoreCase       bool
+	DiffIgnoreWhitespace bool
+	DiffIgnoreBlankLines bool
+
+	// Closed when the context of a ...Context method is done.
+	done <-chan struct{}
+
+	// The base text of DiffPrepared.
+	base *PreparedBase
+
+	// The budget of DiffMainBudget.
+	budget *budgetState
+
+	// Slots of the goroutines beyond the first, with DiffParallelism.
+	sem chan struct{}
 }
 
 // New creates a new DMP object with default parameters.
@@ -39,6 +171,7 @@ func New() *DMP {
 	return &DMP{
 		DiffTimeout:          time.Second,
 		DiffEditCost:         4,
+		DiffHalfMatchRatio:   0.5,
 		MatchThreshold:       0.5,
 		MatchDistance:        1000,
 		PatchDeleteThreshold: 0.5,
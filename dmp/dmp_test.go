@@ -633,6 +633,16 @@ func TestDiffCleanupSemantic(t *testing.T) {
 		{DiffEqual, "xxx"},
 		{DiffDelete, "abc"}}, diffs)
 
+	// Reverse overlap where the overlap is not a suffix of the insertion.
+	diffs = []Diff{
+		{DiffDelete, "a0"},
+		{DiffInsert, "10a"}}
+	diffs = DiffCleanupSemantic(diffs)
+	assertDiffEqual(t, []Diff{
+		{DiffInsert, "10"},
+		{DiffEqual, "a"},
+		{DiffDelete, "0"}}, diffs)
+
 	// Two overlap eliminations.
 	diffs = []Diff{
 		{DiffDelete, "abcd1212"},
@@ -844,6 +854,40 @@ func TestDiffXIndex(t *testing.T) {
 	assert.Equal(t, 1, DiffXIndex(diffs, 3), "diff_xIndex: Translation on deletion.")
 }
 
+func TestDiffXIndexAll(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "The "},
+		{DiffDelete, "cat"},
+		{DiffInsert, "big dog"},
+		{DiffEqual, " sat"},
+		{DiffInsert, "!"}}
+	locs := []int{0, 3, 4, 5, 7, 8, 11, 20}
+	want := make([]int, len(locs))
+	for i, loc := range locs {
+		want[i] = DiffXIndex(diffs, loc)
+	}
+	assert.Equal(t, []int{0, 3, 4, 4, 11, 12, 16, 25}, want, "")
+	assert.Equal(t, want, DiffXIndexAll(diffs, locs), "Sorted.")
+	assert.Equal(t, []int{25, 4, 0, 12},
+		DiffXIndexAll(diffs, []int{20, 5, 0, 8}), "Unsorted.")
+	assert.Equal(t, []int{}, DiffXIndexAll(diffs, nil), "")
+	assert.Equal(t, []int{3}, DiffXIndexAll(nil, []int{3}), "")
+}
+
+func TestDiffXIndexRunes(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "Ünï "},
+		{DiffDelete, "cät"},
+		{DiffInsert, "bïg dög"},
+		{DiffEqual, " sät"}}
+	assert.Equal(t, 12, DiffXIndexRunes(diffs, 8), "")
+	assert.Equal(t, 4, DiffXIndexRunes(diffs, 5), "Translation on deletion.")
+	assert.Equal(t, []int{12, 2, 4},
+		DiffXIndexAllRunes(diffs, []int{8, 2, 5}), "")
+	// In bytes, the same location falls in the deletion.
+	assert.Equal(t, 6, DiffXIndex(diffs, 8), "")
+}
+
 func TestDiffLevenshtein(t *testing.T) {
 	diffs := []Diff{
 		{DiffDelete, "abc"},
@@ -1109,6 +1153,39 @@ func TestPatchObj(t *testing.T) {
 	assert.Equal(t, strp, p.String(), "Patch: toString.")
 }
 
+func TestNewPatch(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "jump"},
+		{DiffDelete, "s"},
+		{DiffInsert, "ed"},
+		{DiffEqual, " over "}}
+	p, err := NewPatch(diffs, 20, 21)
+	assert.Nil(t, err, "")
+	assert.Equal(t, "@@ -21,11 +22,12 @@\n jump\n-s\n+ed\n  over \n",
+		p.String(), "")
+	assert.Equal(t, 20, p.Start1(), "")
+	assert.Equal(t, 21, p.Start2(), "")
+	assert.Equal(t, 11, p.Length1(), "")
+	assert.Equal(t, 12, p.Length2(), "")
+	assert.Equal(t, diffs, p.Diffs(), "")
+
+	// The diffs are copied.
+	diffs[0].Text = "JUMP"
+	p.Diffs()[1].Text = "S"
+	assert.Equal(t, "jump", p.Diffs()[0].Text, "")
+	assert.Equal(t, "s", p.Diffs()[1].Text, "")
+
+	// Null case.
+	p, err = NewPatch(nil, 0, 0)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []Diff{}, p.Diffs(), "")
+
+	_, err = NewPatch(diffs, -1, 0)
+	assert.NotNil(t, err, "Negative start.")
+	_, err = NewPatch([]Diff{{Operation(7), "x"}}, 0, 0)
+	assert.NotNil(t, err, "Invalid operation.")
+}
+
 func TestPatchFromText(t *testing.T) {
 	_v1, _ := PatchFromText("")
 	assert.True(t, len(_v1) == 0, "patch_fromText: #0.")
@@ -1141,6 +1218,13 @@ func TestPatchToText(t *testing.T) {
 	patches, _ = PatchFromText(strp)
 	result = PatchToText(patches)
 	assert.Equal(t, strp, result)
+
+	var buf bytes.Buffer
+	assert.Nil(t, PatchWriteText(&buf, patches), "")
+	assert.Equal(t, strp, buf.String(), "")
+	w := &failWriter{1}
+	assert.NotNil(t, PatchWriteText(w, patches), "")
+	assert.Equal(t, 0, w.n, "Stops at the first error.")
 }
 
 func TestPatchAddContext(t *testing.T) {
@@ -1481,6 +1565,17 @@ func Benchmark_DiffMainLarge(b *testing.B) {
 	}
 }
 
+func Benchmark_DiffMainLargeHirschberg(b *testing.B) {
+	s1 := readFile("speedtest1.txt", b)
+	s2 := readFile("speedtest2.txt", b)
+	dmp := New()
+	dmp.DiffAlgorithm = AlgorithmHirschberg
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dmp.DiffMain(s1, s2, true)
+	}
+}
+
 func Benchmark_DiffMainLargeLines(b *testing.B) {
 	s1 := readFile("speedtest1.txt", b)
 	s2 := readFile("speedtest2.txt", b)
@@ -1493,7 +1588,7 @@ func Benchmark_DiffMainLargeLines(b *testing.B) {
 	}
 }
 
-func readFile(filename string, b *testing.B) string {
+func readFile(filename string, b testing.TB) string {
 	bytes, err := ioutil.ReadFile(filename)
 	if err != nil {
 		b.Fatal(err)
@@ -0,0 +1,128 @@
+// Package dmptest holds a regression corpus of tricky inputs for the dmp
+// package, and a helper running it against any engine with the dmp API, so
+// forks and wrappers can check that they behave the same.
+package dmptest
+
+import (
+	"testing"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+// Engine is the part of the dmp API exercised by the corpus.  *dmp.DMP
+// implements it.
+type Engine interface {
+	DiffMain(s1, s2 string, checkLines bool) []dmp.Diff
+	PatchMake(opt ...interface{}) []dmp.Patch
+	Apply(ps []dmp.Patch, s string) (string, []bool)
+	MatchMain(s, pattern string, loc int) int
+}
+
+// Case is one entry of the corpus.
+type Case struct {
+	Name  string
+	Text1 string
+	Text2 string
+	// Want, if not nil, is the exact diff expected from DiffMain without
+	// line mode.
+	Want []dmp.Diff
+}
+
+// Corpus lists inputs that broke, or nearly broke, earlier versions of the
+// algorithms.
+var Corpus = []Case{
+	{Name: "empty", Text1: "", Text2: ""},
+	{Name: "insert into empty", Text1: "", Text2: "abc",
+		Want: []dmp.Diff{ins("abc")}},
+	{Name: "multi-byte edit", Text1: "日本語", Text2: "日本人",
+		Want: []dmp.Diff{eq("日本"), del("語"), ins("人")}},
+	{Name: "shared lead byte", Text1: "ā", Text2: "Ă",
+		Want: []dmp.Diff{del("ā"), ins("Ă")}},
+	{Name: "invalid utf-8 split", Text1: "ab\xe6\x97cd", Text2: "ab\xe6cd"},
+	{Name: "truncated sequence at end", Text1: "xyz\xe6\x97", Text2: "xyz"},
+	{Name: "ligature", Text1: "ﬁnd the ﬂag", Text2: "find the flag"},
+	{Name: "combining marks", Text1: "café", Text2: "café",
+		Want: []dmp.Diff{eq("caf"), del("é"), ins("é")}},
+	{Name: "emoji zwj sequence",
+		Text1: "👩‍💻 at work", Text2: "👨‍💻 at work"},
+	{Name: "overlapping repeats", Text1: "abababab", Text2: "bababa",
+		Want: []dmp.Diff{del("a"), eq("bababa"), del("b")}},
+	{Name: "self overlap", Text1: "aaaaaaaaaa", Text2: "aaaaaaaaaaaaaaa",
+		Want: []dmp.Diff{eq("aaaaaaaaaa"), ins("aaaaa")}},
+	{Name: "reverse overlap", Text1: "a0a", Text2: "10aa",
+		Want: []dmp.Diff{ins("10"), eq("a"), del("0"), eq("a")}},
+	{Name: "percent and plus", Text1: "a+b=%20", Text2: "a b=%2B"},
+	{Name: "null bytes", Text1: "a\x00b\x00c", Text2: "a\x00c"},
+	{Name: "crlf", Text1: "one\r\ntwo\r\n", Text2: "one\ntwo\r\n"},
+}
+
+// normalize returns s the way the engine sees it: invalid UTF-8 becomes
+// U+FFFD.
+func normalize(s string) string {
+	return string([]rune(s))
+}
+
+func eq(s string) dmp.Diff  { return dmp.Diff{Type: dmp.DiffEqual, Text: s} }
+func ins(s string) dmp.Diff { return dmp.Diff{Type: dmp.DiffInsert, Text: s} }
+func del(s string) dmp.Diff { return dmp.Diff{Type: dmp.DiffDelete, Text: s} }
+
+// RunAll runs every corpus case against e as a subtest.  Each case checks
+// that diffs reproduce both texts, that patches turn one text into the
+// other, and that every text matches itself.
+func RunAll(t *testing.T, e Engine) {
+	for _, c := range Corpus {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			Run(t, e, c)
+		})
+	}
+}
+
+// Run checks a single case against e.
+func Run(t *testing.T, e Engine, c Case) {
+	t1, t2 := normalize(c.Text1), normalize(c.Text2)
+	for _, checkLines := range []bool{false, true} {
+		diffs := e.DiffMain(c.Text1, c.Text2, checkLines)
+		if got := dmp.DiffText1(diffs); got != t1 {
+			t.Errorf("DiffText1 = %q, want %q", got, t1)
+		}
+		if got := dmp.DiffText2(diffs); got != t2 {
+			t.Errorf("DiffText2 = %q, want %q", got, t2)
+		}
+		if c.Want != nil && !checkLines && !diffsEqual(diffs, c.Want) {
+			t.Errorf("DiffMain = %v, want %v", diffs, c.Want)
+		}
+	}
+
+	patches := e.PatchMake(t1, t2)
+	got, applied := e.Apply(patches, t1)
+	if got != t2 {
+		t.Errorf("Apply = %q, want %q", got, t2)
+	}
+	for i, ok := range applied {
+		if !ok {
+			t.Errorf("patch %d did not apply", i)
+		}
+	}
+
+	for _, s := range []string{t1, t2} {
+		if s == "" {
+			continue
+		}
+		if loc := e.MatchMain(s, s, 0); loc != 0 {
+			t.Errorf("MatchMain(%q, %q, 0) = %d, want 0", s, s, loc)
+		}
+	}
+}
+
+func diffsEqual(a, b []dmp.Diff) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
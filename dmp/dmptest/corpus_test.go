@@ -0,0 +1,25 @@
+package dmptest
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+func TestRunAll(t *testing.T) {
+	RunAll(t, dmp.New())
+}
+
+func FuzzDiffMain(f *testing.F) {
+	for _, c := range Corpus {
+		f.Add(c.Text1, c.Text2)
+	}
+	e := dmp.New()
+	f.Fuzz(func(t *testing.T, s1, s2 string) {
+		if !utf8.ValidString(s1) || !utf8.ValidString(s2) {
+			t.Skip()
+		}
+		Run(t, e, Case{Name: "fuzz", Text1: s1, Text2: s2})
+	})
+}
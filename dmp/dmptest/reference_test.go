@@ -0,0 +1,162 @@
+//go:build refcheck
+// +build refcheck
+
+package dmptest
+
+// Differential test against another implementation of diff-match-patch.
+// Run with
+//
+//	DMP_REFERENCE="python3 testdata/reference.py" go test -tags refcheck
+//
+// The command must speak the line protocol of testdata/reference.py.
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+type reference struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	out *bufio.Scanner
+}
+
+type refRequest struct {
+	Op    string `json:"op"`
+	Text1 string `json:"text1"`
+	Text2 string `json:"text2"`
+	Loc   int    `json:"loc"`
+}
+
+func startReference(t *testing.T) *reference {
+	command := os.Getenv("DMP_REFERENCE")
+	if command == "" {
+		t.Skip("DMP_REFERENCE is not set")
+	}
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(nil, 64<<20)
+	return &reference{cmd, in, scanner}
+}
+
+func (r *reference) close() {
+	r.in.Close()
+	r.cmd.Wait()
+}
+
+func (r *reference) call(t *testing.T, req refRequest, resp interface{}) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.in.Write(append(b, '\n')); err != nil {
+		t.Fatal(err)
+	}
+	if !r.out.Scan() {
+		t.Fatalf("reference died: %v", r.out.Err())
+	}
+	if err := json.Unmarshal(r.out.Bytes(), resp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (r *reference) diffs(t *testing.T, req refRequest) []dmp.Diff {
+	var raw [][2]interface{}
+	r.call(t, req, &raw)
+	diffs := make([]dmp.Diff, len(raw))
+	for i, d := range raw {
+		diffs[i] = dmp.Diff{
+			Type: dmp.Operation(d[0].(float64)),
+			Text: d[1].(string),
+		}
+	}
+	return diffs
+}
+
+// randomText returns a short text over a small alphabet, so that random
+// pairs have plenty in common.
+func randomText(rnd *rand.Rand) string {
+	alphabet := []rune("ab c\né日")
+	n := rnd.Intn(40)
+	runes := make([]rune, n)
+	for i := range runes {
+		runes[i] = alphabet[rnd.Intn(len(alphabet))]
+	}
+	return string(runes)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestReference(t *testing.T) {
+	ref := startReference(t)
+	defer ref.close()
+
+	e := dmp.New()
+	e.DiffTimeout = 0
+	cases := []Case{}
+	for _, c := range Corpus {
+		c.Text1, c.Text2 = normalize(c.Text1), normalize(c.Text2)
+		cases = append(cases, c)
+	}
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		cases = append(cases, Case{
+			Name: "random", Text1: randomText(rnd), Text2: randomText(rnd),
+		})
+	}
+
+	for _, c := range cases {
+		req := refRequest{Op: "diff", Text1: c.Text1, Text2: c.Text2}
+		got := e.DiffMain(c.Text1, c.Text2, false)
+		if want := ref.diffs(t, req); !diffsEqual(got, want) {
+			t.Errorf("%s: DiffMain(%q, %q) = %v, reference %v",
+				c.Name, c.Text1, c.Text2, got, want)
+		}
+
+		req.Op = "semantic"
+		got = dmp.DiffCleanupSemantic(got)
+		if want := ref.diffs(t, req); !diffsEqual(got, want) {
+			t.Errorf("%s: DiffCleanupSemantic(%q, %q) = %v, reference %v",
+				c.Name, c.Text1, c.Text2, got, want)
+		}
+
+		// Match locations are byte offsets here but code point offsets in
+		// most other ports.
+		if c.Text2 == "" || !isASCII(c.Text1+c.Text2) {
+			continue
+		}
+		loc := len(c.Text1) / 2
+		var want int
+		ref.call(t, refRequest{"match", c.Text1, c.Text2, loc}, &want)
+		if got := e.MatchMain(c.Text1, c.Text2, loc); got != want {
+			t.Errorf("%s: MatchMain(%q, %q, %d) = %d, reference %d",
+				c.Name, c.Text1, c.Text2, loc, got, want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+#!/usr/bin/env python3
+# Reference adapter for reference_test.go, wrapping the Python port of
+# diff-match-patch (pip install diff-match-patch).  It reads one JSON
+# request per line on stdin and writes one JSON response per line.
+import json
+import sys
+
+from diff_match_patch import diff_match_patch
+
+dmp = diff_match_patch()
+dmp.Diff_Timeout = 0
+
+for line in sys.stdin:
+    req = json.loads(line)
+    op = req["op"]
+    if op == "diff":
+        resp = dmp.diff_main(req["text1"], req["text2"], False)
+    elif op == "semantic":
+        diffs = dmp.diff_main(req["text1"], req["text2"], False)
+        dmp.diff_cleanupSemantic(diffs)
+        resp = diffs
+    elif op == "match":
+        resp = dmp.match_main(req["text1"], req["text2"], req["loc"])
+    else:
+        raise ValueError("unknown op: " + op)
+    sys.stdout.write(json.dumps(resp) + "\n")
+    sys.stdout.flush()
@@ -1,6 +1,3 @@
-// Package DMP offers robust algorithms to perform the
-// operations required for synchronizing plain text.
-
 /**
  * Go language implementation of Google Diff, Match, and Patch library
  *
@@ -12,4 +9,13 @@
  *
  * See included LICENSE file for license details.
  */
+
+// Package DMP offers robust algorithms to perform the
+// operations required for synchronizing plain text.
+//
+// A *DMP may be shared by any number of goroutines as long as its fields
+// are not modified while it is in use; the package holds no other mutable
+// state.  Patches passed to Apply and friends are never modified, and
+// neither are the slices given to DiffMainRunes and the DiffCleanup
+// functions, unless BorrowInputs is set.
 package dmp
@@ -0,0 +1,119 @@
+package dmp
+
+import (
+	"strings"
+)
+
+// EOL is the kind of line endings of a text.
+type EOL int8
+
+const (
+	// EOLNone texts have no line breaks.
+	EOLNone EOL = iota
+	// EOLLF texts end their lines with "\n", as on Unix.
+	EOLLF
+	// EOLCRLF texts end their lines with "\r\n", as on Windows.
+	EOLCRLF
+	// EOLCR texts end their lines with "\r", as on classic Mac OS.
+	EOLCR
+	// EOLMixed texts use more than one kind of line ending.
+	EOLMixed
+)
+
+// String returns "none", "LF", "CRLF", "CR" or "mixed".
+func (e EOL) String() string {
+	switch e {
+	case EOLLF:
+		return "LF"
+	case EOLCRLF:
+		return "CRLF"
+	case EOLCR:
+		return "CR"
+	case EOLMixed:
+		return "mixed"
+	}
+	return "none"
+}
+
+// eolNormalizer converts CRLF and CR line endings to LF.
+var eolNormalizer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// DetectEOL returns the kind of line endings of text, so that tools can
+// warn when two texts differ in them before showing a confusing diff.
+func DetectEOL(text string) EOL {
+	eol := EOLNone
+	for i := 0; i < len(text); i++ {
+		var kind EOL
+		switch {
+		case text[i] == '\n':
+			kind = EOLLF
+		case text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n':
+			kind = EOLCRLF
+			i++
+		case text[i] == '\r':
+			kind = EOLCR
+		default:
+			continue
+		}
+		if eol != EOLNone && eol != kind {
+			return EOLMixed
+		}
+		eol = kind
+	}
+	return eol
+}
+
+// eolTexts returns s1 and s2 with normalized line endings if NormalizeEOL
+// is set.
+func (dmp *DMP) eolTexts(s1, s2 string) (string, string) {
+	if !dmp.NormalizeEOL {
+		return s1, s2
+	}
+	return eolNormalizer.Replace(s1), eolNormalizer.Replace(s2)
+}
+
+// eolText normalizes the line endings of s as eolTexts does, keeping the
+// offset in s of each byte of the result as ignoreText does.
+func eolText(s string) *ignoredText {
+	t := &ignoredText{orig: len(s), offsets: make([]int, 0, len(s))}
+	buf := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		t.offsets = append(t.offsets, i)
+		if s[i] != '\r' {
+			buf = append(buf, s[i])
+			continue
+		}
+		buf = append(buf, '\n')
+		if i+1 < len(s) && s[i+1] == '\n' {
+			i++
+		}
+	}
+	t.text = string(buf)
+	return t
+}
+
+// lineBreaks counts the line breaks of s, counting "\r\n" as one.
+func lineBreaks(s string) int {
+	return strings.Count(s, "\n") + strings.Count(s, "\r") -
+		strings.Count(s, "\r\n")
+}
+
+// eolOnly tells whether replacing deleted with inserted, between the
+// texts before and after, only changes line endings: both consist of line
+// break characters, and the number of line breaks is kept.
+func eolOnly(before, deleted, inserted, after string) bool {
+	isBreaks := func(s string) bool {
+		return strings.Trim(s, "\r\n") == ""
+	}
+	if !isBreaks(deleted) || !isBreaks(inserted) || deleted == inserted {
+		return false
+	}
+	if len(before) > 0 {
+		before = before[len(before)-1:]
+	}
+	if len(after) > 0 {
+		after = after[:1]
+	}
+	return lineBreaks(before+deleted+after) ==
+		lineBreaks(before+inserted+after)
+}
@@ -0,0 +1,71 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDetectEOL(t *testing.T) {
+	for _, c := range []struct {
+		text string
+		eol  EOL
+	}{
+		{"", EOLNone},
+		{"one line", EOLNone},
+		{"a\nb\n", EOLLF},
+		{"a\r\nb\r\n", EOLCRLF},
+		{"a\rb", EOLCR},
+		{"a\r\nb\n", EOLMixed},
+		{"a\rb\r\n", EOLMixed},
+	} {
+		assert.Equal(t, c.eol, DetectEOL(c.text), c.text)
+	}
+	assert.Equal(t, "CRLF", EOLCRLF.String(), "")
+	assert.Equal(t, "mixed", EOLMixed.String(), "")
+}
+
+func TestNormalizeEOL(t *testing.T) {
+	dmp := New()
+	dmp.NormalizeEOL = true
+	assert.Equal(t, []Diff{{DiffEqual, "a\nb\n"}},
+		dmp.DiffMain("a\r\nb\n", "a\nb\r", false), "")
+	assert.Equal(t, []Diff{
+		{DiffEqual, "a\n"}, {DiffInsert, "c\n"}, {DiffEqual, "b\n"},
+	}, dmp.DiffMain("a\r\nb\r\n", "a\nc\nb\n", true), "")
+}
+
+func TestNormalizeEOLPatches(t *testing.T) {
+	// Patches are made and applied against the texts as they are.
+	dmp := New()
+	dmp.NormalizeEOL = true
+	text1 := "alpha\r\nbeta\r\ngamma\r\n"
+	text2 := "alpha\r\nBETA\r\ngamma\r\n"
+	ps := dmp.PatchMake(text1, text2)
+	assert.Equal(t, "@@ -4,12 +4,12 @@\n ha%0D%0A\n-beta\n+BETA\n %0D%0Aga\n",
+		PatchToText(ps), "")
+	patched, applied := dmp.Apply(ps, text1)
+	assert.Equal(t, text2, patched, "")
+	assert.Equal(t, []bool{true}, applied, "")
+
+	patched, applied = dmp.Apply(ps, "x\r\n"+text1)
+	assert.Equal(t, "x\r\n"+text2, patched, "")
+	assert.Equal(t, []bool{true}, applied, "")
+}
+
+func TestDiffClassifyEOL(t *testing.T) {
+	dmp := New()
+	diffs := dmp.DiffMain("one\r\ntwo\nthree", "one\ntwo\r\nthree", false)
+	classes := DiffClassifyWhitespace(diffs)
+	for i, d := range diffs {
+		if d.Type != DiffEqual {
+			assert.Equal(t, EOLOnly, classes[i], d.Text)
+		}
+	}
+
+	// Joining or splitting lines is not a change of line endings.
+	diffs = []Diff{{DiffEqual, "a"}, {DiffDelete, "\r\n"}, {DiffEqual, "b"}}
+	assert.Equal(t, WhitespaceOnly, DiffClassifyWhitespace(diffs)[1], "")
+	diffs = []Diff{{DiffEqual, "a\r\n"}, {DiffInsert, "\r\n"}}
+	assert.Equal(t, WhitespaceOnly, DiffClassifyWhitespace(diffs)[1], "")
+}
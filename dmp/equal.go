@@ -0,0 +1,53 @@
+package dmp
+
+import (
+	"crypto/subtle"
+	"strings"
+)
+
+// TextEqual reports whether a and b are the same text.  It is the first
+// check of DiffMain, made before the texts are converted to runes, so that
+// diffing a large document against itself costs one bulk compare of
+// memory, which stops at the first difference.  Hashing the texts first
+// would read both in full and could not be faster; digests only pay off
+// when kept to compare one text against many.
+func TextEqual(a, b string) bool {
+	return len(a) == len(b) && a == b
+}
+
+// EqualFold reports whether a and b are the same text under Unicode
+// case folding, for callers deciding whether a case-insensitive comparison
+// needs a diff at all.
+func EqualFold(a, b string) bool {
+	return len(a) == len(b) && a == b || strings.EqualFold(a, b)
+}
+
+// ConstantTimeEqual reports whether a and b are the same text in a time
+// that depends only on their lengths, for texts such as tokens that must
+// not leak where they differ.  It is slower than TextEqual.
+func ConstantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// CompareOptions selects what Compare computes beyond equality.
+type CompareOptions struct {
+	// Whether to diff unequal texts and return their Stats.  Without it,
+	// Compare stops at the first difference, as cmp does.
+	Stats bool
+	// Whether to diff line by line first, as DiffMain with checklines.
+	Lines bool
+}
+
+// Compare reports whether a and b are equal, and with opts.Stats the
+// Stats of their diff.  Tools that only need to know whether texts differ,
+// as for an exit code, thus pay for no diff.
+func (dmp *DMP) Compare(a, b string, opts CompareOptions) (bool, Stats) {
+	equal := TextEqual(a, b)
+	if !opts.Stats {
+		return equal, Stats{}
+	}
+	if equal {
+		return true, DiffStats([]Diff{{DiffEqual, a}})
+	}
+	return false, DiffStats(dmp.DiffMain(a, b, opts.Lines))
+}
@@ -0,0 +1,65 @@
+package dmp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestTextEqual(t *testing.T) {
+	assert.True(t, TextEqual("", ""), "")
+	assert.True(t, TextEqual("abc", "abc"), "")
+	assert.False(t, TextEqual("abc", "abd"), "")
+	assert.False(t, TextEqual("abc", "ab"), "")
+
+	assert.True(t, EqualFold("Straße", "STRAßE"), "")
+	assert.True(t, EqualFold("abc", "abc"), "")
+	assert.False(t, EqualFold("abc", "abd"), "")
+
+	assert.True(t, ConstantTimeEqual("secret", "secret"), "")
+	assert.False(t, ConstantTimeEqual("secret", "secreT"), "")
+	assert.False(t, ConstantTimeEqual("secret", "secrets"), "")
+}
+
+func TestDiffMainEqual(t *testing.T) {
+	dmp := New()
+	assert.Equal(t, []Diff{}, dmp.DiffMain("", "", false), "")
+	assert.Equal(t, []Diff{{DiffEqual, "abc"}},
+		dmp.DiffMain("abc", "abc", true), "")
+	// Equal texts are reported as they are, even if not valid UTF-8.
+	assert.Equal(t, []Diff{{DiffEqual, "a\xffb"}},
+		dmp.DiffMain("a\xffb", "a\xffb", false), "")
+}
+
+func Benchmark_DiffMainEqual(b *testing.B) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog.\n",
+		100000)
+	other := string([]byte(text))
+	dmp := New()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dmp.DiffMain(text, other, false)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	dmp := New()
+	equal, st := dmp.Compare("abc", "abc", CompareOptions{})
+	assert.True(t, equal, "")
+	assert.Equal(t, Stats{}, st, "")
+	equal, st = dmp.Compare("abc", "abd", CompareOptions{})
+	assert.False(t, equal, "")
+	assert.Equal(t, Stats{}, st, "")
+
+	equal, st = dmp.Compare("a b\n", "a b\n", CompareOptions{Stats: true})
+	assert.True(t, equal, "")
+	assert.Equal(t, Stats{EqualChars: 4, EqualLines: 1, EqualWords: 2,
+		Ratio: 1}, st, "")
+
+	a, b := "one\ntwo\n", "one\n2\n"
+	equal, st = dmp.Compare(a, b, CompareOptions{Stats: true, Lines: true})
+	assert.False(t, equal, "")
+	assert.Equal(t, DiffStats(dmp.DiffMain(a, b, true)), st, "")
+	assert.Equal(t, 1, st.InsertedLines, "")
+}
@@ -0,0 +1,95 @@
+package dmp_test
+
+import (
+	"fmt"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+func ExampleDMP_DiffMain() {
+	d := dmp.New()
+	diffs := d.DiffMain("The quick brown fox.", "The slow brown dog.", false)
+	signs := map[dmp.Operation]string{
+		dmp.DiffEqual: "=", dmp.DiffDelete: "-", dmp.DiffInsert: "+",
+	}
+	for _, diff := range dmp.DiffCleanupSemantic(diffs) {
+		fmt.Printf("%s%q\n", signs[diff.Type], diff.Text)
+	}
+	// Output:
+	// ="The "
+	// -"quick"
+	// +"slow"
+	// =" brown "
+	// -"fox"
+	// +"dog"
+	// ="."
+}
+
+func ExampleDMP_PatchMake() {
+	d := dmp.New()
+	patches := d.PatchMake("The quick brown fox.", "That quick brown fox.")
+	fmt.Print(dmp.PatchToText(patches))
+	// Output:
+	// @@ -1,7 +1,8 @@
+	//  Th
+	// -e
+	// +at
+	//   qui
+}
+
+func ExampleDMP_Apply() {
+	d := dmp.New()
+	patches := d.PatchMake("The quick brown fox.", "The slow brown fox.")
+
+	// The target text has changed since the patch was made.
+	text, applied := d.Apply(patches, "Yesterday, the quick brown fox.")
+	fmt.Println(text)
+	fmt.Println(applied)
+	// Output:
+	// Yesterday, the slow brown fox.
+	// [true]
+}
+
+func ExampleDMP_Merge3() {
+	d := dmp.New()
+	base := "The quick brown fox jumps over the lazy dog."
+	ours := "The quick red fox jumps over the lazy dog."
+	theirs := "The quick brown fox leaps over the lazy cat."
+	chunks := d.Merge3(base, ours, theirs)
+	text, _, ok := dmp.ResolveConflicts(chunks, dmp.MergePolicy{})
+	fmt.Println(text)
+	fmt.Println(ok)
+	// Output:
+	// The quick red fox leaps over the lazy cat.
+	// true
+}
+
+func ExampleDiffToDelta() {
+	d := dmp.New()
+	text1 := "The quick brown fox."
+	diffs := d.DiffMain(text1, "The slow brown fox.", false)
+
+	// The delta only makes sense to someone holding text1.
+	delta := dmp.DiffToDelta(diffs)
+	fmt.Println(delta)
+
+	diffs, err := dmp.DiffFromDelta(text1, delta)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(dmp.DiffText2(diffs))
+	// Output:
+	// =4	-5	+slow	=11
+	// The slow brown fox.
+}
+
+func ExamplePatchFromText() {
+	patches, err := dmp.PatchFromText("@@ -1,7 +1,8 @@\n Th\n-e\n+at\n  qui\n")
+	if err != nil {
+		panic(err)
+	}
+	text, _ := dmp.New().Apply(patches, "The quick brown fox.")
+	fmt.Println(text)
+	// Output:
+	// That quick brown fox.
+}
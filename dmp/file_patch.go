@@ -0,0 +1,88 @@
+package dmp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"unicode/utf8"
+)
+
+// FilePatchKind tags what a FilePatch carries.
+type FilePatchKind int8
+
+const (
+	// FileIdentical means both files have the same content.
+	FileIdentical FilePatchKind = iota
+	// FileText means the files are text and Patches holds line-mode
+	// patches from the old content to the new one.
+	FileText
+	// FileBinary means at least one file is not text and Binary holds the
+	// full new content.
+	FileBinary
+)
+
+// FilePatch is the difference between two file snapshots.
+type FilePatch struct {
+	OldPath string
+	NewPath string
+	Kind    FilePatchKind
+	Patches []Patch
+	Binary  []byte
+}
+
+// MakeFilePatch reads two files and returns the patch turning the first
+// into the second.  Files are identical if they are the same file or have
+// the same bytes; their metadata alone is never taken for their content.
+// Text files are diffed in line mode; anything else falls back to
+// carrying the whole new content.
+func (dmp *DMP) MakeFilePatch(oldPath, newPath string) (*FilePatch, error) {
+	fp := &FilePatch{OldPath: oldPath, NewPath: newPath}
+
+	info1, err := os.Stat(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	info2, err := os.Stat(newPath)
+	if err != nil {
+		return nil, err
+	}
+	if os.SameFile(info1, info2) {
+		fp.Kind = FileIdentical
+		return fp, nil
+	}
+
+	old, err := ioutil.ReadFile(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := ioutil.ReadFile(newPath)
+	if err != nil {
+		return nil, err
+	}
+	// Files of different sizes differ without comparing their bytes.
+	if info1.Size() == info2.Size() && bytes.Equal(old, cur) {
+		fp.Kind = FileIdentical
+		return fp, nil
+	}
+
+	if !isText(old) || !isText(cur) {
+		fp.Kind = FileBinary
+		fp.Binary = cur
+		return fp, nil
+	}
+
+	fp.Kind = FileText
+	fp.Patches = dmp.patchMakeLines(string(old), string(cur))
+	return fp, nil
+}
+
+// patchMakeLines makes patches from a line-level diff of two texts.
+func (dmp *DMP) patchMakeLines(text1, text2 string) []Patch {
+	diffs := dmp.diffLines(text1, text2, 0, deadline(dmp.DiffTimeout))
+	return dmp.PatchMake(text1, diffs)
+}
+
+// isText reports whether b looks like text: valid UTF-8 without NUL bytes.
+func isText(b []byte) bool {
+	return utf8.Valid(b) && bytes.IndexByte(b, 0) == -1
+}
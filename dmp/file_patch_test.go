@@ -0,0 +1,73 @@
+package dmp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func writeTempFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMakeFilePatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dmp := New()
+	a := writeTempFile(t, dir, "a.txt", "alpha\nbeta\ngamma\n")
+	b := writeTempFile(t, dir, "b.txt", "alpha\nbeta\ngamma\n")
+	c := writeTempFile(t, dir, "c.txt", "alpha\nBETA\ngamma\n")
+	bin := writeTempFile(t, dir, "d.bin", "alpha\x00\xff\n")
+	// Files of the same size and time are still compared.
+	now := time.Now()
+	for i, path := range []string{a, b, c} {
+		mtime := now.Add(time.Duration(i) * time.Second)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fp, err := dmp.MakeFilePatch(a, b)
+	assert.Nil(t, err, "")
+	assert.Equal(t, FileIdentical, fp.Kind, "Identical content.")
+	assert.Equal(t, 0, len(fp.Patches), "")
+
+	fp, err = dmp.MakeFilePatch(a, a)
+	assert.Nil(t, err, "")
+	assert.Equal(t, FileIdentical, fp.Kind, "Same file.")
+
+	fp, err = dmp.MakeFilePatch(a, c)
+	assert.Nil(t, err, "")
+	assert.Equal(t, FileText, fp.Kind, "")
+	assert.Equal(t, "@@ -3,13 +3,13 @@\n pha%0A\n-beta%0A\n+BETA%0A\n gamm\n",
+		PatchToText(fp.Patches), "Line mode patch.")
+	s, _ := dmp.Apply(fp.Patches, "alpha\nbeta\ngamma\n")
+	assert.Equal(t, "alpha\nBETA\ngamma\n", s, "")
+
+	fp, err = dmp.MakeFilePatch(a, bin)
+	assert.Nil(t, err, "")
+	assert.Equal(t, FileBinary, fp.Kind, "")
+	assert.Equal(t, []byte("alpha\x00\xff\n"), fp.Binary, "")
+
+	if err := os.Chtimes(c, now, now); err != nil {
+		t.Fatal(err)
+	}
+	fp, err = dmp.MakeFilePatch(a, c)
+	assert.Nil(t, err, "")
+	assert.Equal(t, FileText, fp.Kind, "Same size and time.")
+
+	_, err = dmp.MakeFilePatch(a, filepath.Join(dir, "missing"))
+	assert.NotNil(t, err, "Missing file.")
+}
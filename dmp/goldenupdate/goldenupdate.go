@@ -0,0 +1,110 @@
+// Package goldenupdate compares test output with golden files.  On a
+// mismatch it reports the changed lines in the form of diff -u, and writes
+// a patch next to the golden file that Update applies to accept the new
+// output, so that reviewing and updating goldens is one workflow.
+package goldenupdate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+// Number of unchanged lines around the changed ones in Mismatch.Diff.
+const context = 3
+
+// Mismatch describes output that differs from its golden file.
+type Mismatch struct {
+	// Golden is the path of the golden file.
+	Golden string
+	// Diff shows the changes from the golden file to the output in the
+	// form of diff -u, without the file header.
+	Diff string
+	// Patch turns the golden file into the output, in the format of
+	// dmp.PatchToText, with whole lines as dmp.PatchMakeLines makes them.
+	Patch string
+}
+
+// PatchPath returns where WritePatch writes the patch of m.
+func (m *Mismatch) PatchPath() string {
+	return PatchPath(m.Golden)
+}
+
+// WritePatch writes the patch of m next to the golden file.
+func (m *Mismatch) WritePatch() error {
+	return ioutil.WriteFile(m.PatchPath(), []byte(m.Patch), 0644)
+}
+
+// PatchPath returns the path of the patch to the golden file golden.
+func PatchPath(golden string) string {
+	return golden + ".patch"
+}
+
+// Compare compares got with the content of the golden file, which is
+// empty if the file does not exist.  Returns nil if they are the same.
+func Compare(golden, got string) (*Mismatch, error) {
+	b, err := ioutil.ReadFile(golden)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	want := string(b)
+	if want == got {
+		return nil, nil
+	}
+	e := dmp.New()
+	return &Mismatch{
+		Golden: golden,
+		Diff:   e.DiffUnified(want, got, context),
+		Patch:  dmp.PatchToText(e.PatchMakeLines(want, got)),
+	}, nil
+}
+
+// Check fails t if got differs from the golden file.  The failure shows
+// the diff and the patch written by WritePatch, which Update applies.
+func Check(t testing.TB, golden, got string) {
+	t.Helper()
+	m, err := Compare(golden, got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m == nil {
+		return
+	}
+	if err := m.WritePatch(); err != nil {
+		t.Fatal(err)
+	}
+	t.Errorf("Output differs from %s:\n%s\nTo accept it, apply %s.",
+		golden, m.Diff, m.PatchPath())
+}
+
+// Update applies the patch written by Mismatch.WritePatch to the golden
+// file and removes the patch.  It fails if any hunk no longer applies, in
+// which case the golden file is left alone.
+func Update(golden string) error {
+	text, err := ioutil.ReadFile(PatchPath(golden))
+	if err != nil {
+		return err
+	}
+	ps, err := dmp.PatchFromText(string(text))
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadFile(golden)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	updated, applied := dmp.New().ApplyLines(ps, string(b))
+	for i, ok := range applied {
+		if !ok {
+			return fmt.Errorf("Hunk %d of %s does not apply to %s",
+				i+1, PatchPath(golden), golden)
+		}
+	}
+	if err := ioutil.WriteFile(golden, []byte(updated), 0644); err != nil {
+		return err
+	}
+	return os.Remove(PatchPath(golden))
+}
@@ -0,0 +1,75 @@
+package goldenupdate
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+// recorder records the failures of Check.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCompare(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "out.golden")
+	want := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	ioutil.WriteFile(golden, []byte(want), 0644)
+
+	m, err := Compare(golden, want)
+	assert.Nil(t, err, "")
+	assert.Nil(t, m, "")
+
+	got := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13"
+	m, err = Compare(golden, got)
+	assert.Nil(t, err, "")
+	assert.Equal(t, "@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n"+
+		"@@ -10,3 +10,4 @@\n 10\n 11\n 12\n+13\n"+
+		"\\ No newline at end of file\n", m.Diff, "")
+
+	// A missing golden file is empty.
+	m, err = Compare(golden+".new", "a\n")
+	assert.Nil(t, err, "")
+	assert.Equal(t, "@@ -0,0 +1 @@\n+a\n", m.Diff, "")
+}
+
+func TestCheckAndUpdate(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "out.golden")
+	ioutil.WriteFile(golden, []byte("a\nb\nc\n"), 0644)
+
+	r := &recorder{TB: t}
+	Check(r, golden, "a\nb\nc\n")
+	assert.Equal(t, 0, len(r.errors), "")
+	_, err := os.Stat(PatchPath(golden))
+	assert.True(t, os.IsNotExist(err), "")
+
+	Check(r, golden, "a\nB\nc\n")
+	assert.Equal(t, 1, len(r.errors), "")
+	assert.True(t, strings.Contains(r.errors[0], "-b\n+B\n"), "")
+	assert.True(t, strings.Contains(r.errors[0], PatchPath(golden)), "")
+
+	assert.Nil(t, Update(golden), "")
+	b, _ := ioutil.ReadFile(golden)
+	assert.Equal(t, "a\nB\nc\n", string(b), "")
+	_, err = os.Stat(PatchPath(golden))
+	assert.True(t, os.IsNotExist(err), "")
+
+	// A golden file changed since the patch was written.
+	Check(r, golden, "a\nX\nc\n")
+	ioutil.WriteFile(golden, []byte("y\nz\n"), 0644)
+	assert.NotNil(t, Update(golden), "")
+	b, _ = ioutil.ReadFile(golden)
+	assert.Equal(t, "y\nz\n", string(b), "")
+}
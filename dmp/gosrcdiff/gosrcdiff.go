@@ -0,0 +1,94 @@
+// Package gosrcdiff diffs Go source files without reporting differences
+// that gofmt would remove.  Both files are formatted, the formatted files
+// are diffed line by line, and the changes are mapped back onto the
+// original first file, so that patches apply to it as it is.
+package gosrcdiff
+
+import (
+	"go/format"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+// Diff returns the diffs turning src1 into a file that formats like
+// src2.  Outside of the changed lines, src1 keeps its formatting.  It
+// fails if either file does not parse.
+func Diff(engine *dmp.DMP, src1, src2 string) ([]dmp.Diff, error) {
+	formatted1, err := format.Source([]byte(src1))
+	if err != nil {
+		return nil, err
+	}
+	formatted2, err := format.Source([]byte(src2))
+	if err != nil {
+		return nil, err
+	}
+	fmt1, fmt2 := string(formatted1), string(formatted2)
+
+	// Line diff of the formatted files.
+	chars1, chars2, lines := dmp.DiffLinesToChars(fmt1, fmt2)
+	changes := dmp.DiffCharsToLines(
+		engine.DiffMain(chars1, chars2, false), lines,
+	)
+
+	// Offsets in the formatted first file map to the original through a
+	// character diff of the two.
+	toOriginal := engine.DiffMain(fmt1, src1, false)
+
+	diffs := []dmp.Diff{}
+	add := func(op dmp.Operation, text string) {
+		if text != "" {
+			diffs = append(diffs, dmp.Diff{Type: op, Text: text})
+		}
+	}
+	done := 0
+	// emit replaces fmt1[start:end] with text.
+	emit := func(start, end int, text string) {
+		from := dmp.DiffXIndex(toOriginal, start)
+		to := dmp.DiffXIndex(toOriginal, end)
+		from = max(from, done)
+		to = max(to, from)
+		add(dmp.DiffEqual, src1[done:from])
+		add(dmp.DiffDelete, src1[from:to])
+		add(dmp.DiffInsert, text)
+		done = to
+	}
+
+	pos := 0
+	start, inserted, open := 0, "", false
+	for _, d := range changes {
+		switch d.Type {
+		case dmp.DiffEqual:
+			if open {
+				emit(start, pos, inserted)
+				open = false
+			}
+			pos += len(d.Text)
+			continue
+		case dmp.DiffDelete:
+			if !open {
+				start, inserted, open = pos, "", true
+			}
+			pos += len(d.Text)
+		case dmp.DiffInsert:
+			if !open {
+				start, inserted, open = pos, "", true
+			}
+			inserted += d.Text
+		}
+	}
+	if open {
+		emit(start, pos, inserted)
+	}
+	add(dmp.DiffEqual, src1[done:])
+	return diffs, nil
+}
+
+// PatchMake returns patches turning src1 into a file that formats like
+// src2, as computed by Diff.  They apply to src1 as it is.
+func PatchMake(engine *dmp.DMP, src1, src2 string) ([]dmp.Patch, error) {
+	diffs, err := Diff(engine, src1, src2)
+	if err != nil {
+		return nil, err
+	}
+	return engine.PatchMake(src1, diffs), nil
+}
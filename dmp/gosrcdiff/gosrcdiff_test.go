@@ -0,0 +1,75 @@
+package gosrcdiff
+
+import (
+	"go/format"
+	"testing"
+
+	"github.com/sergi/go-diff/dmp"
+	"github.com/stretchrcom/testify/assert"
+)
+
+const src1 = `package main
+
+import "fmt"
+
+func   main( ) {
+	x:=1
+    fmt.Println( x )
+}
+
+func other() {   return }
+`
+
+const src2 = `package main
+
+import "fmt"
+
+func main() {
+	x := 2
+	fmt.Println(x)
+}
+
+func other() { return }
+`
+
+func TestDiff(t *testing.T) {
+	engine := dmp.New()
+	diffs, err := Diff(engine, src1, src2)
+	assert.Nil(t, err, "")
+	assert.Equal(t, src1, dmp.DiffText1(diffs), "")
+
+	// Only the changed line is touched; the rest keeps its formatting.
+	var deleted, inserted string
+	for _, d := range diffs {
+		switch d.Type {
+		case dmp.DiffDelete:
+			deleted += d.Text
+		case dmp.DiffInsert:
+			inserted += d.Text
+		}
+	}
+	assert.Equal(t, "\tx:=1\n", deleted, "")
+	assert.Equal(t, "\tx := 2\n", inserted, "")
+
+	result := dmp.DiffText2(diffs)
+	formatted, err := format.Source([]byte(result))
+	assert.Nil(t, err, "")
+	assert.Equal(t, src2, string(formatted), "")
+
+	// Formatting-only differences give no changes.
+	diffs, _ = Diff(engine, src1, src1)
+	assert.Equal(t, []dmp.Diff{{Type: dmp.DiffEqual, Text: src1}}, diffs, "")
+
+	_, err = Diff(engine, src1, "package")
+	assert.NotNil(t, err, "Invalid source.")
+}
+
+func TestPatchMake(t *testing.T) {
+	engine := dmp.New()
+	ps, err := PatchMake(engine, src1, src2)
+	assert.Nil(t, err, "")
+	result, applied := engine.Apply(ps, src1)
+	assert.Equal(t, []bool{true}, applied, "")
+	formatted, _ := format.Source([]byte(result))
+	assert.Equal(t, src2, string(formatted), "")
+}
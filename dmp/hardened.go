@@ -0,0 +1,232 @@
+package dmp
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Limits caps what a Hardened accepts and produces.  Zero fields are
+// unlimited.
+type Limits struct {
+	// Bytes of each text and patch text given.
+	MaxInputBytes int
+
+	// Diffs returned by DiffMain, and patches made or parsed.
+	MaxDiffs int
+
+	// Steps of the diffs and matches of one call, as Budget.MaxIterations
+	// counts them.  A call that runs out of steps is not an error: its
+	// diffs are coarser, and its matches less exact, as with a Budget.
+	MaxIterations int
+
+	// Bytes of rendered HTML, text and patch text.
+	MaxOutputBytes int
+}
+
+// LimitError reports an input or output over the Limits of a Hardened.
+type LimitError struct {
+	// Which limit: "input", "diffs" or "output".
+	Limit string
+	// Size of the input or output, in bytes or diffs.  Output is cut
+	// off at the first write over the limit.
+	Size int
+	Max  int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("Over the %s limit: %d > %d", e.Limit, e.Size, e.Max)
+}
+
+// MalformedPatchError reports a patch text that does not parse, or
+// patches that PatchValidate rejects.
+type MalformedPatchError struct {
+	Err error
+}
+
+func (e *MalformedPatchError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the parser or of PatchValidate.
+func (e *MalformedPatchError) Unwrap() error {
+	return e.Err
+}
+
+// Hardened is the subset of the API fit for inputs from untrusted users,
+// as in public services diffing submitted content.  Inputs and outputs
+// are capped by Limits, and the work of each call by a step count rather
+// than by time, so that the results do not depend on the load of the
+// machine.  Every error is a *LimitError, *InvalidUTF8Error or
+// *MalformedPatchError.
+type Hardened struct {
+	Limits Limits
+	dmp    DMP
+}
+
+// NewHardened returns a Hardened with limits for texts of up to 1 MiB.
+func NewHardened() *Hardened {
+	h := &Hardened{
+		Limits: Limits{
+			MaxInputBytes:  1 << 20,
+			MaxDiffs:       100000,
+			MaxIterations:  10000,
+			MaxOutputBytes: 8 << 20,
+		},
+		dmp: *New(),
+	}
+	h.dmp.DiffTimeout = 0
+	h.dmp.StrictUTF8 = true
+	return h
+}
+
+// engine returns the DMP of a call, with a budget of MaxIterations.
+func (h *Hardened) engine() *DMP {
+	e := h.dmp
+	e.budget = &budgetState{
+		Budget: Budget{MaxIterations: h.Limits.MaxIterations},
+	}
+	return &e
+}
+
+// checkInputs checks the sizes of texts, and that they are valid UTF-8.
+// They are given in pairs of names and texts, as to checkTexts.
+func (h *Hardened) checkInputs(inputs ...string) error {
+	for i := 1; i < len(inputs); i += 2 {
+		err := checkLimit("input", len(inputs[i]), h.Limits.MaxInputBytes)
+		if err != nil {
+			return err
+		}
+	}
+	return h.dmp.checkTexts(inputs...)
+}
+
+// checkLimit returns a *LimitError if size is over max.
+func checkLimit(limit string, size, max int) error {
+	if max > 0 && size > max {
+		return &LimitError{Limit: limit, Size: size, Max: max}
+	}
+	return nil
+}
+
+// DiffMain finds the differences between two texts.
+func (h *Hardened) DiffMain(text1, text2 string) ([]Diff, error) {
+	if err := h.checkInputs("text1", text1, "text2", text2); err != nil {
+		return nil, err
+	}
+	diffs := h.engine().DiffMain(text1, text2, true)
+	if err := checkLimit("diffs", len(diffs), h.Limits.MaxDiffs); err != nil {
+		return nil, err
+	}
+	return diffs, nil
+}
+
+// PatchMake makes the patches turning text1 into text2.
+func (h *Hardened) PatchMake(text1, text2 string) ([]Patch, error) {
+	if err := h.checkInputs("text1", text1, "text2", text2); err != nil {
+		return nil, err
+	}
+	ps := h.engine().PatchMake(text1, text2)
+	if err := checkLimit("diffs", len(ps), h.Limits.MaxDiffs); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// PatchFromText parses patches written by PatchToText.
+func (h *Hardened) PatchFromText(text string) ([]Patch, error) {
+	if err := h.checkInputs("patch", text); err != nil {
+		return nil, err
+	}
+	ps, err := PatchFromText(text)
+	if err != nil {
+		return nil, &MalformedPatchError{err}
+	}
+	if err := checkLimit("diffs", len(ps), h.Limits.MaxDiffs); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+// PatchToText writes patches in the textual format of PatchFromText.
+func (h *Hardened) PatchToText(ps []Patch) (string, error) {
+	var buf bytes.Buffer
+	lw := &limitWriter{w: &buf, n: h.Limits.MaxOutputBytes}
+	if err := PatchWriteText(lw, ps); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Apply applies patches to text, and tells which were applied.  The
+// patches must be well formed, see PatchValidate.
+func (h *Hardened) Apply(ps []Patch, text string) (string, []bool, error) {
+	if err := PatchValidate(ps); err != nil {
+		return text, nil, &MalformedPatchError{err}
+	}
+	if err := checkLimit("diffs", len(ps), h.Limits.MaxDiffs); err != nil {
+		return text, nil, err
+	}
+	if err := h.checkInputs("text", text); err != nil {
+		return text, nil, err
+	}
+	if err := h.dmp.checkPatches(ps); err != nil {
+		return text, nil, err
+	}
+	patched, applied := h.engine().Apply(ps, text)
+	return patched, applied, checkLimit(
+		"output", len(patched), h.Limits.MaxOutputBytes,
+	)
+}
+
+// MatchMain locates the best instance of pattern in text near loc, or
+// returns -1.
+func (h *Hardened) MatchMain(text, pattern string, loc int) (int, error) {
+	err := h.checkInputs("text", text, "pattern", pattern)
+	if err != nil {
+		return -1, err
+	}
+	return h.engine().MatchMain(text, pattern, loc), nil
+}
+
+// DiffPrettyHtml renders diffs as DiffPrettyHtml does.
+func (h *Hardened) DiffPrettyHtml(diffs []Diff) (string, error) {
+	var buf bytes.Buffer
+	lw := &limitWriter{w: &buf, n: h.Limits.MaxOutputBytes}
+	if err := DiffWriteHtml(lw, diffs, HtmlOptions{}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// DiffPrettyText renders diffs as DiffPrettyText does.
+func (h *Hardened) DiffPrettyText(diffs []Diff) (string, error) {
+	var buf bytes.Buffer
+	lw := &limitWriter{w: &buf, n: h.Limits.MaxOutputBytes}
+	if err := DiffWriteText(lw, diffs, TextOptions{}); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// limitWriter writes at most n bytes to w (0 for no limit), and fails with
+// an output *LimitError past them.
+type limitWriter struct {
+	w       *bytes.Buffer
+	n       int
+	written int
+	err     error
+}
+
+func (lw *limitWriter) Write(p []byte) (int, error) {
+	if lw.err != nil {
+		return 0, lw.err
+	}
+	if lw.n > 0 && lw.written+len(p) > lw.n {
+		lw.err = &LimitError{
+			Limit: "output", Size: lw.written + len(p), Max: lw.n,
+		}
+		return 0, lw.err
+	}
+	lw.written += len(p)
+	return lw.w.Write(p)
+}
@@ -0,0 +1,79 @@
+package dmp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestHardened(t *testing.T) {
+	h := NewHardened()
+	diffs, err := h.DiffMain("The cat sat.", "The hat sat.")
+	assert.Nil(t, err, "")
+	assert.Equal(t, New().DiffMain("The cat sat.", "The hat sat.", true),
+		diffs, "")
+
+	ps, err := h.PatchMake("The cat sat.", "The hat sat.")
+	assert.Nil(t, err, "")
+	text, err := h.PatchToText(ps)
+	assert.Nil(t, err, "")
+	ps, err = h.PatchFromText(text)
+	assert.Nil(t, err, "")
+	patched, applied, err := h.Apply(ps, "The cat sat down.")
+	assert.Nil(t, err, "")
+	assert.Equal(t, "The hat sat down.", patched, "")
+	assert.Equal(t, []bool{true}, applied, "")
+
+	i, err := h.MatchMain("abcdef", "cxe", 0)
+	assert.Nil(t, err, "")
+	assert.Equal(t, 2, i, "")
+
+	// Inputs over the limits.
+	h.Limits.MaxInputBytes = 8
+	_, err = h.DiffMain("123456789", "")
+	assert.Equal(t, &LimitError{"input", 9, 8}, err, "")
+	_, err = h.PatchFromText(text)
+	assert.Equal(t, "input", err.(*LimitError).Limit, "")
+	_, err = h.MatchMain("abc", "123456789", 0)
+	assert.Equal(t, "Over the input limit: 9 > 8", err.Error(), "")
+	h.Limits.MaxInputBytes = 0
+
+	// Invalid UTF-8 and malformed patches.
+	_, err = h.DiffMain("ok", "\xff")
+	assert.Equal(t, &InvalidUTF8Error{"text2", 0}, err, "")
+	_, err = h.PatchFromText("@@ nonsense\n")
+	var malformed *MalformedPatchError
+	assert.True(t, errors.As(err, &malformed), "")
+
+	// Too many diffs.
+	h.Limits.MaxDiffs = 3
+	_, err = h.DiffMain("a1b2c3", "a4b5c6")
+	assert.Equal(t, "diffs", err.(*LimitError).Limit, "")
+	h.Limits.MaxDiffs = 0
+
+	// Too much output.
+	h.Limits.MaxOutputBytes = 20
+	_, err = h.DiffPrettyHtml(diffs)
+	assert.Equal(t, "output", err.(*LimitError).Limit, "")
+	_, err = h.DiffPrettyText(diffs)
+	assert.Equal(t, "output", err.(*LimitError).Limit, "")
+	_, err = h.PatchToText(ps)
+	assert.Equal(t, "output", err.(*LimitError).Limit, "")
+	h.Limits.MaxOutputBytes = 0
+
+	// Running out of steps gives a coarser diff, the same every time.
+	h.Limits.MaxIterations = 5
+	text1 := strings.Repeat("abcdefghij", 30)
+	text2 := strings.Repeat("abcdefghi", 30)
+	diffs, err = h.DiffMain(text1, text2)
+	assert.Nil(t, err, "")
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	again, _ := h.DiffMain(text1, text2)
+	assert.Equal(t, diffs, again, "")
+	assert.True(t,
+		DiffLevenshtein(diffs) > DiffLevenshtein(New().DiffMain(
+			text1, text2, true)), "")
+}
@@ -0,0 +1,59 @@
+package dmp
+
+// DiffHeatmap cuts the new text of diffs (text2) into buckets of equal
+// length and returns the share of the changed bytes that falls into each,
+// for minimaps and scrollbar heatmaps.  Inserted bytes count where they
+// are, and deleted ones where they were removed.  The shares add up to 1,
+// or are all 0 if nothing changed.  Returns nil if buckets is not
+// positive.
+func DiffHeatmap(diffs []Diff, buckets int) []float64 {
+	diffs = withoutMoves(diffs)
+	if buckets <= 0 {
+		return nil
+	}
+	heat := make([]float64, buckets)
+	n := 0
+	for _, d := range diffs {
+		if d.Type != DiffDelete {
+			n += len(d.Text)
+		}
+	}
+	// bucket returns the bucket of offset p of text2, and where the next
+	// bucket starts.
+	bucket := func(p int) (int, int) {
+		if n == 0 {
+			return 0, 1
+		}
+		k := min(p*buckets/n, buckets-1)
+		// Bucket k starts at the first offset p with p*buckets/n == k.
+		return k, ((k+1)*n + buckets - 1) / buckets
+	}
+
+	total := 0
+	pos := 0
+	for _, d := range diffs {
+		switch d.Type {
+		case DiffDelete:
+			k, _ := bucket(pos)
+			heat[k] += float64(len(d.Text))
+			total += len(d.Text)
+		case DiffInsert:
+			for p := pos; p < pos+len(d.Text); {
+				k, next := bucket(p)
+				end := min(next, pos+len(d.Text))
+				heat[k] += float64(end - p)
+				p = end
+			}
+			total += len(d.Text)
+			pos += len(d.Text)
+		case DiffEqual:
+			pos += len(d.Text)
+		}
+	}
+	if total > 0 {
+		for k := range heat {
+			heat[k] /= float64(total)
+		}
+	}
+	return heat
+}
@@ -0,0 +1,31 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffHeatmap(t *testing.T) {
+	// text2 is "abcdefgh"; "cd" is inserted and "xyzw" deleted after "g".
+	diffs := []Diff{
+		{DiffEqual, "ab"},
+		{DiffInsert, "cd"},
+		{DiffEqual, "efg"},
+		{DiffDelete, "xyzw"},
+		{DiffEqual, "h"},
+	}
+	assert.Equal(t, []float64{0, 2.0 / 6, 0, 4.0 / 6}, DiffHeatmap(diffs, 4), "")
+	assert.Equal(t, []float64{2.0 / 6, 4.0 / 6}, DiffHeatmap(diffs, 2), "")
+
+	// An insertion spanning buckets of uneven length.
+	diffs = []Diff{{DiffEqual, "a"}, {DiffInsert, "bcdef"}, {DiffEqual, "g"}}
+	assert.Equal(t, []float64{0.4, 0.4, 0.2}, DiffHeatmap(diffs, 3), "")
+
+	// Deleting everything.
+	diffs = []Diff{{DiffDelete, "abc"}}
+	assert.Equal(t, []float64{1, 0}, DiffHeatmap(diffs, 2), "")
+
+	assert.Equal(t, []float64{0, 0}, DiffHeatmap([]Diff{{DiffEqual, "a"}}, 2), "")
+	assert.Nil(t, DiffHeatmap(diffs, 0), "")
+}
@@ -0,0 +1,119 @@
+package dmp
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ignoring tells whether dmp ignores differences of case, whitespace or
+// blank lines.
+func (dmp *DMP) ignoring() bool {
+	return dmp.DiffIgnoreCase || dmp.DiffIgnoreWhitespace ||
+		dmp.DiffIgnoreBlankLines
+}
+
+// exact returns dmp, or a copy of it that neither ignores differences nor
+// normalizes line endings, for the patches and merges that must rebuild
+// the texts from the diffs.
+func (dmp *DMP) exact() *DMP {
+	if !dmp.ignoring() && !dmp.NormalizeEOL {
+		return dmp
+	}
+	e := *dmp
+	e.NormalizeEOL = false
+	e.DiffIgnoreCase = false
+	e.DiffIgnoreWhitespace = false
+	e.DiffIgnoreBlankLines = false
+	return &e
+}
+
+// ignoredText is a text with the differences dmp ignores normalized away.
+type ignoredText struct {
+	text string
+	// Offset in the original text of the rune each byte of text comes
+	// from.
+	offsets []int
+	// Length of the original text.
+	orig int
+}
+
+// origOffset returns the offset in the original text of the boundary
+// before byte i of the normalized text.  Characters dropped by the
+// normalization belong to the text before them.
+func (t *ignoredText) origOffset(i int) int {
+	switch {
+	case i >= len(t.offsets):
+		return t.orig
+	case i == 0:
+		return 0
+	}
+	return t.offsets[i]
+}
+
+// ignoreText normalizes text as the DiffIgnore... settings of dmp ask.
+func (dmp *DMP) ignoreText(text string) *ignoredText {
+	t := &ignoredText{orig: len(text)}
+	var buf []byte
+	pos := 0
+	for _, line := range splitLinesAfter(text) {
+		if dmp.DiffIgnoreBlankLines && strings.TrimSpace(line) == "" &&
+			strings.HasSuffix(line, "\n") {
+			pos += len(line)
+			continue
+		}
+		for i, r := range line {
+			if dmp.DiffIgnoreWhitespace && r != '\n' && unicode.IsSpace(r) {
+				continue
+			}
+			if dmp.DiffIgnoreCase {
+				r = unicode.ToLower(r)
+			}
+			// Invalid bytes become U+FFFD, as they do in the diffs.
+			n := len(buf)
+			buf = utf8.AppendRune(buf, r)
+			for ; n < len(buf); n++ {
+				t.offsets = append(t.offsets, pos+i)
+			}
+		}
+		pos += len(line)
+	}
+	t.text = string(buf)
+	return t
+}
+
+// diffIgnoring diffs s1 and s2 ignoring what the DiffIgnore... settings of
+// dmp ask for, and maps the diffs back onto the original texts: deletions
+// and equalities take their text from s1, insertions from s2.
+func (dmp *DMP) diffIgnoring(
+	s1, s2 string, checkLines bool, end time.Time,
+) []Diff {
+	t1, t2 := dmp.ignoreText(s1), dmp.ignoreText(s2)
+	diffs := dmp.exact().diffDispatch(t1.text, t2.text, checkLines, end)
+
+	ret := make([]Diff, 0, len(diffs)+1)
+	if t1.text == "" {
+		// All of s1 is ignored, and equal to all of s2 that is.
+		ret = append(ret, Diff{DiffEqual, s1})
+	}
+	pos1, pos2 := 0, 0
+	for _, d := range diffs {
+		end1, end2 := pos1, pos2
+		if d.Type != DiffInsert {
+			end1 += len(d.Text)
+		}
+		if d.Type != DiffDelete {
+			end2 += len(d.Text)
+		}
+		text1 := s1[t1.origOffset(pos1):t1.origOffset(end1)]
+		text2 := s2[t2.origOffset(pos2):t2.origOffset(end2)]
+		if d.Type == DiffEqual {
+			ret = append(ret, Diff{DiffEqual, text1})
+		} else {
+			ret = append(ret, Diff{DiffDelete, text1}, Diff{DiffInsert, text2})
+		}
+		pos1, pos2 = end1, end2
+	}
+	return diffCleanupMerge(ret)
+}
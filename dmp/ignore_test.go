@@ -0,0 +1,85 @@
+package dmp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffIgnore(t *testing.T) {
+	dmp := New()
+	dmp.DiffIgnoreWhitespace = true
+	text1 := "key = value  \nname=Old\n"
+	text2 := "key=value\n  name = New \t\n"
+	diffs := dmp.DiffMain(text1, text2, false)
+	assert.Equal(t, []Diff{
+		{DiffEqual, "key = value  \nname="},
+		{DiffDelete, "Old"},
+		{DiffInsert, "New \t"},
+		{DiffEqual, "\n"},
+	}, diffs, "")
+	assert.Equal(t, text1, DiffText1(diffs), "")
+
+	dmp = New()
+	dmp.DiffIgnoreCase = true
+	diffs = dmp.DiffMain("Hello World", "hello world!", false)
+	assert.Equal(t, []Diff{
+		{DiffEqual, "Hello World"}, {DiffInsert, "!"},
+	}, diffs, "")
+
+	dmp = New()
+	dmp.DiffIgnoreBlankLines = true
+	diffs = dmp.DiffMain("a\n\nb\nc\n", "a\nb\n \nc\nd\n", true)
+	assert.Equal(t, []Diff{
+		{DiffEqual, "a\n\nb\nc\n"}, {DiffInsert, "d\n"},
+	}, diffs, "")
+
+	// Texts of ignored characters only.
+	dmp = New()
+	dmp.DiffIgnoreWhitespace = true
+	assert.Equal(t, []Diff{{DiffEqual, "  "}, {DiffInsert, " x"}},
+		dmp.DiffMain("  ", " x", false), "")
+	assert.Equal(t, []Diff{{DiffEqual, " "}},
+		dmp.DiffMain(" ", "\t", false), "")
+	assert.Equal(t, []Diff{{DiffDelete, "x "}},
+		dmp.DiffMain("x ", "", false), "")
+	assert.Equal(t, []Diff{}, dmp.DiffMain("", "", false), "")
+
+	// Case folding that changes the length of runes.
+	dmp = New()
+	dmp.DiffIgnoreCase = true
+	diffs = dmp.DiffMain("KELVİN x", "kelvin y", false)
+	assert.Equal(t, "KELVİN x", DiffText1(diffs), "")
+}
+
+func TestDiffIgnorePatches(t *testing.T) {
+	// Patches and merges diff the texts as they are, so what is ignored
+	// is kept.
+	dmp := New()
+	dmp.DiffIgnoreWhitespace = true
+	text := "The quick brown fox jumps over the la zy dog."
+	ps := dmp.PatchMake("The quick brown fox jumps over the lazy dog.",
+		"The quick brown fox jumps over the lazy cat.")
+	patched, applied := dmp.Apply(ps, text)
+	assert.Equal(t, "The quick brown fox jumps over the la zy cat.",
+		patched, "")
+	assert.Equal(t, []bool{true}, applied, "")
+
+	ps = dmp.PatchMake("a b\nc\n", "a  b\nC\n")
+	patched, _ = dmp.Apply(ps, "a b\nc\n")
+	assert.Equal(t, "a  b\nC\n", patched, "")
+
+	merged, _, ok := ResolveConflicts(dmp.Merge3("alpha beta gamma delta",
+		"alpha  beta gamma delta", "alpha beta gamma Delta"), MergePolicy{})
+	assert.True(t, ok, "")
+	assert.Equal(t, "alpha  beta gamma Delta", merged, "")
+
+	// The engines with a context or a budget ignore the same.
+	diffs := dmp.DiffMain("a b", "ab", false)
+	ctxDiffs, err := dmp.DiffMainContext(context.Background(), "a b", "ab", false)
+	assert.Nil(t, err, "")
+	assert.Equal(t, diffs, ctxDiffs, "")
+	budgetDiffs, _ := dmp.DiffMainBudget("a b", "ab", false, Budget{})
+	assert.Equal(t, diffs, budgetDiffs, "")
+}
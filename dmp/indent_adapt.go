@@ -0,0 +1,92 @@
+package dmp
+
+import (
+	"strings"
+)
+
+// adaptIndent returns p re-indented to fit s, whose text1 is not in s,
+// along with the indentation it replaced and the one it put instead.
+// The change of indentation is taken from the first line of text1 with
+// more than indentation, from its nearest occurrence to loc in s, and
+// must make all of text1 occur in s.  Returns false if there is no such
+// change.
+func adaptIndent(dmp *DMP, p Patch, text1, s string, loc int) (
+	Patch, string, string, bool,
+) {
+	from, to, ok := indentChange(text1, s, loc)
+	if !ok {
+		return p, "", "", false
+	}
+	indented1 := reindent(text1, from, to)
+	if !strings.Contains(s, indented1) {
+		return p, "", "", false
+	}
+	indented2 := reindent(DiffText2(p.diffs), from, to)
+	q := PatchDeepCopy([]Patch{p})[0]
+	q.diffs = dmp.diffMain(
+		indented1, indented2, false, deadline(dmp.DiffTimeout),
+	)
+	q.length1 = len(indented1)
+	q.length2 = len(indented2)
+	return q, from, to, true
+}
+
+// indentChange returns how the first line of text1 that starts after a
+// line break and holds more than indentation is indented differently at
+// its occurrence in s nearest to loc: its leading from is replaced with
+// to.  The indentation both share at the end, as nested lines do, is
+// left out.
+func indentChange(text1, s string, loc int) (string, string, bool) {
+	lines := strings.SplitAfter(text1, "\n")
+	for _, line := range lines[1:] {
+		content := strings.TrimLeft(line, " \t")
+		if strings.TrimSpace(content) == "" {
+			continue
+		}
+		indent := line[:len(line)-len(content)]
+		found, best := "", -1
+		for i := 0; ; {
+			j := strings.Index(s[i:], content)
+			if j == -1 {
+				break
+			}
+			j += i
+			i = j + 1
+			start := j
+			for start > 0 && (s[start-1] == ' ' || s[start-1] == '\t') {
+				start--
+			}
+			if start > 0 && s[start-1] != '\n' {
+				continue
+			}
+			if best == -1 || abs(start-loc) < abs(best-loc) {
+				found, best = s[start:j], start
+			}
+		}
+		if best == -1 {
+			return "", "", false
+		}
+		n := 0
+		for n < len(indent) && n < len(found) &&
+			indent[len(indent)-1-n] == found[len(found)-1-n] {
+			n++
+		}
+		from, to := indent[:len(indent)-n], found[:len(found)-n]
+		return from, to, from != to
+	}
+	return "", "", false
+}
+
+// reindent replaces the leading from of the lines of text after the first
+// with to.  Blank lines are left alone.
+func reindent(text, from, to string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		l := lines[i]
+		if strings.TrimSpace(l) == "" || !strings.HasPrefix(l, from) {
+			continue
+		}
+		lines[i] = to + l[len(from):]
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,46 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchAdaptIndent(t *testing.T) {
+	dmp := New()
+	old := "func f() {\n\ta()\n\tb()\n}\n"
+	cur := "func f() {\n\ta()\n\tx()\n\tb()\n}\n"
+	ps := dmp.PatchMake(old, cur)
+
+	// The body moved into a block, one level deeper.
+	target := "func f() {\n\tif ok {\n\t\ta()\n\t\tb()\n\t}\n}\n"
+	dmp.PatchAdaptIndent = true
+	patched, results := dmp.ApplyDetailed(ps, target)
+	assert.Equal(t,
+		"func f() {\n\tif ok {\n\t\ta()\n\t\tx()\n\t\tb()\n\t}\n}\n",
+		patched, "")
+	assert.True(t, results[0].Applied, "")
+	assert.Equal(t, "", results[0].IndentFrom, "")
+	assert.Equal(t, "\t", results[0].IndentTo, "")
+
+	// And back out of it.
+	ps = dmp.PatchMake(target, "func f() {\n\tif ok {\n\t\ta()\n\t\tx()\n"+
+		"\t\tb()\n\t}\n}\n")
+	patched, results = dmp.ApplyDetailed(ps, old)
+	assert.Equal(t, cur, patched, "")
+	assert.Equal(t, "\t", results[0].IndentFrom, "")
+	assert.Equal(t, "", results[0].IndentTo, "")
+
+	// Patches that apply as they are are left alone.
+	ps = dmp.PatchMake(old, cur)
+	patched, results = dmp.ApplyDetailed(ps, old)
+	assert.Equal(t, cur, patched, "")
+	assert.Equal(t, "", results[0].IndentTo, "")
+
+	// Without the option, the inserted line keeps its indentation.
+	dmp.PatchAdaptIndent = false
+	patched, _ = dmp.Apply(ps, target)
+	assert.Equal(t,
+		"func f() {\n\tif ok {\n\t\ta()\n\t\tx()\n\tb()\n\t}\n}\n",
+		patched, "")
+}
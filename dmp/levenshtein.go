@@ -3,6 +3,7 @@ package dmp
 // DiffLevenshtein computes the Levenshtein distance; the number of inserted,
 // deleted or substituted characters.
 func DiffLevenshtein(diffs []Diff) int {
+	diffs = withoutMoves(diffs)
 	ret := 0
 	insertions := 0
 	deletions := 0
@@ -0,0 +1,32 @@
+package dmp
+
+import (
+	"strings"
+)
+
+// LinePos is where a diff starts in both texts, as 1-based line numbers.
+// A diff that starts right after a line break starts on the next line.
+type LinePos struct {
+	Line1 int
+	Line2 int
+}
+
+// DiffLineNumbers returns the LinePos of each of diffs, in one pass.  A
+// diff that does not occur in a text, such as an insertion in text1, is
+// given the line of that text it falls on.  Lines end at "\n".
+func DiffLineNumbers(diffs []Diff) []LinePos {
+	diffs = withoutMoves(diffs)
+	ret := make([]LinePos, len(diffs))
+	pos := LinePos{1, 1}
+	for i, d := range diffs {
+		ret[i] = pos
+		n := strings.Count(d.Text, "\n")
+		if d.Type <= 0 {
+			pos.Line1 += n
+		}
+		if d.Type >= 0 {
+			pos.Line2 += n
+		}
+	}
+	return ret
+}
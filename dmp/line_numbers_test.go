@@ -0,0 +1,39 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffLineNumbers(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "a\nb"},
+		{DiffDelete, "c\nd\n"},
+		{DiffInsert, "e\n"},
+		{DiffEqual, "f\n"},
+		{DiffInsert, "g"},
+	}
+	assert.Equal(t, []LinePos{
+		{1, 1}, {2, 2}, {4, 2}, {4, 3}, {5, 4},
+	}, DiffLineNumbers(diffs), "")
+	assert.Equal(t, []LinePos{}, DiffLineNumbers(nil), "")
+
+	// The lines of each diff are those of DiffText1 and DiffText2.
+	for i, pos := range DiffLineNumbers(diffs) {
+		text1, text2 := DiffText1(diffs[:i]), DiffText2(diffs[:i])
+		assert.Equal(t, countLines(text1), pos.Line1, "")
+		assert.Equal(t, countLines(text2), pos.Line2, "")
+	}
+}
+
+// countLines returns the line number of the end of text.
+func countLines(text string) int {
+	n := 1
+	for _, r := range text {
+		if r == '\n' {
+			n++
+		}
+	}
+	return n
+}
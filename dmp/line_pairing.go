@@ -0,0 +1,139 @@
+package dmp
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Lines are paired if the Dice coefficient of their words is at least
+// this.
+const linePairThreshold = 0.5
+
+// Blocks with more line combinations than this are rediffed as a whole,
+// like without DiffPairLines.
+const linePairMaxCells = 1 << 16
+
+// diffReplacedLines rediffs a block of deleted lines against the block of
+// inserted lines that replaced it.  With DiffPairLines, each deleted line
+// is first paired with the most similar inserted line, keeping the order
+// of both blocks, and only paired lines are diffed character by
+// character.  Other lines are reported as whole deletions and insertions.
+func (dmp *DMP) diffReplacedLines(
+	deleted, inserted string, deadline time.Time,
+) []Diff {
+	lines1 := splitLinesAfter(deleted)
+	lines2 := splitLinesAfter(inserted)
+	if !dmp.DiffPairLines || len(lines1)*len(lines2) > linePairMaxCells ||
+		len(lines1) < 2 && len(lines2) < 2 {
+		return dmp.diffMain(deleted, inserted, false, deadline)
+	}
+
+	words1 := make([]map[string]int, len(lines1))
+	for i, l := range lines1 {
+		words1[i] = lineWords(l)
+	}
+	words2 := make([]map[string]int, len(lines2))
+	for j, l := range lines2 {
+		words2[j] = lineWords(l)
+	}
+
+	// best[i][j] is the highest total similarity of pairs among the first
+	// i deleted and first j inserted lines.
+	n, m := len(lines1), len(lines2)
+	best := make([][]float64, n+1)
+	for i := range best {
+		best[i] = make([]float64, m+1)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			sim := lineSimilarity(
+				lines1[i], lines2[j], words1[i], words2[j],
+			)
+			b := math.Max(best[i][j+1], best[i+1][j])
+			if sim >= linePairThreshold {
+				b = math.Max(b, best[i][j]+sim)
+			}
+			best[i+1][j+1] = b
+		}
+	}
+
+	// Trace the pairs back, then emit the blocks in order.
+	var pairs [][2]int
+	for i, j := n, m; i > 0 && j > 0; {
+		switch {
+		case best[i][j] == best[i-1][j]:
+			i--
+		case best[i][j] == best[i][j-1]:
+			j--
+		default:
+			pairs = append(pairs, [2]int{i - 1, j - 1})
+			i--
+			j--
+		}
+	}
+	diffs := []Diff{}
+	i, j := 0, 0
+	emit := func(toI, toJ int) {
+		if text := strings.Join(lines1[i:toI], ""); text != "" {
+			diffs = append(diffs, Diff{DiffDelete, text})
+		}
+		if text := strings.Join(lines2[j:toJ], ""); text != "" {
+			diffs = append(diffs, Diff{DiffInsert, text})
+		}
+		i, j = toI, toJ
+	}
+	for k := len(pairs) - 1; k >= 0; k-- {
+		p := pairs[k]
+		emit(p[0], p[1])
+		diffs = append(diffs,
+			dmp.diffMain(lines1[i], lines2[j], false, deadline)...)
+		i++
+		j++
+	}
+	emit(n, m)
+	return diffs
+}
+
+// splitLinesAfter cuts s after each newline.
+func splitLinesAfter(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineWords counts the words of a line.
+func lineWords(line string) map[string]int {
+	words := map[string]int{}
+	for _, w := range strings.FieldsFunc(line, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w]++
+	}
+	return words
+}
+
+// lineSimilarity is the Dice coefficient of the words of two lines, or 1
+// for identical lines.
+func lineSimilarity(
+	line1, line2 string, words1, words2 map[string]int,
+) float64 {
+	if line1 == line2 {
+		return 1
+	}
+	total, common := 0, 0
+	for w, c1 := range words1 {
+		total += c1
+		common += min(c1, words2[w])
+	}
+	for _, c2 := range words2 {
+		total += c2
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(2*common) / float64(total)
+}
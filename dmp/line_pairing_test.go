@@ -0,0 +1,49 @@
+package dmp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffPairLines(t *testing.T) {
+	dmp := New()
+	dmp.DiffTimeout = 0
+	dmp.DiffPairLines = true
+
+	diffs := dmp.diffReplacedLines(
+		"name: alice\nage: 30\n",
+		"name: bob\nemail: bob@example.com\nage: 31\n",
+		deadline(0),
+	)
+	assert.Equal(t, []Diff{
+		{DiffEqual, "name: "}, {DiffDelete, "alice"}, {DiffInsert, "bob"},
+		{DiffEqual, "\n"},
+		{DiffInsert, "email: bob@example.com\n"},
+		{DiffEqual, "age: 3"}, {DiffDelete, "0"}, {DiffInsert, "1"},
+		{DiffEqual, "\n"},
+	}, diffs, "The new line is not mixed into its neighbours.")
+
+	diffs = dmp.diffReplacedLines("one two\nthree\n", "four\nfive six\n",
+		deadline(0))
+	assert.Equal(t, []Diff{
+		{DiffDelete, "one two\nthree\n"}, {DiffInsert, "four\nfive six\n"},
+	}, diffs, "Dissimilar lines are not paired.")
+
+	// Through DiffMain, in line mode.
+	filler := strings.Repeat("unchanged line of filler text here\n", 5)
+	block1 := "name: alice\nage: 30\n"
+	block2 := "name: bob\nemail: bob@example.com\nage: 31\n"
+	text1 := block1 + filler + block1
+	text2 := block2 + filler + block2
+	diffs = dmp.DiffMain(text1, text2, true)
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.Equal(t, Diff{DiffDelete, "alice"}, diffs[1], "")
+
+	dmp.DiffPairLines = false
+	diffs = dmp.DiffMain(text1, text2, true)
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.NotEqual(t, Diff{DiffDelete, "alice"}, diffs[1], "")
+}
@@ -0,0 +1,173 @@
+package dmp
+
+import (
+	"sort"
+	"strings"
+)
+
+// Number of unchanged lines around the changes of a line patch, as in
+// diff -u.
+const patchLineContext = 3
+
+// Number of context lines ApplyLines may ignore at each end of a hunk
+// that does not match, as patch --fuzz does.
+const patchLineFuzz = 2
+
+// lineOp is one line of a line diff.
+type lineOp struct {
+	op   Operation
+	line string
+}
+
+// PatchMakeLines makes patches from text1 to text2 whose hunks hold whole
+// lines, with patchLineContext lines of context, like the hunks of diff
+// -u.  They are ordinary patches, but ApplyLines applies them line by
+// line.
+func (dmp *DMP) PatchMakeLines(text1, text2 string) []Patch {
+	ids1, ids2, lines := DiffLinesToTokens(text1, text2)
+	ops := []lineOp{}
+	for _, d := range DiffSlicesWith(dmp, ids1, ids2) {
+		for _, id := range d.Items {
+			ops = append(ops, lineOp{d.Type, lines[id]})
+		}
+	}
+	// pos[k] is the offset in text2 of the line of ops[k].
+	pos := make([]int, len(ops)+1)
+	for k, o := range ops {
+		pos[k+1] = pos[k]
+		if o.op != DiffDelete {
+			pos[k+1] += len(o.line)
+		}
+	}
+
+	ps := []Patch{}
+	// End of the previous hunk, which the next one may not overlap.
+	floor := 0
+	for k := 0; k < len(ops); {
+		if ops[k].op == DiffEqual {
+			k++
+			continue
+		}
+		start := max(floor, k-patchLineContext)
+		end := k
+		for end < len(ops) {
+			if ops[end].op != DiffEqual {
+				end++
+				continue
+			}
+			run := 0
+			for end+run < len(ops) && ops[end+run].op == DiffEqual {
+				run++
+			}
+			if end+run < len(ops) && run <= 2*patchLineContext {
+				// The next change shares the context.
+				end += run
+				continue
+			}
+			end += min(run, patchLineContext)
+			break
+		}
+		// Earlier patches have been applied at this point, so the start
+		// in text1 counts like the start in text2.
+		p := Patch{start1: pos[start], start2: pos[start]}
+		for _, o := range ops[start:end] {
+			p.diffs = appendDiffText(p.diffs, o.op, o.line)
+			if o.op != DiffInsert {
+				p.length1 += len(o.line)
+			}
+			if o.op != DiffDelete {
+				p.length2 += len(o.line)
+			}
+		}
+		ps = append(ps, p)
+		floor, k = end, end
+	}
+	return ps
+}
+
+// ApplyLines applies patches to text line by line, like patch(1).  Each
+// hunk must match whole lines of the text exactly; the match nearest to
+// where the hunk is expected wins.  If there is none, up to patchLineFuzz
+// context lines are ignored at each end of the hunk.  Returns the patched
+// text and which patches were applied.
+func (dmp *DMP) ApplyLines(ps []Patch, text string) (string, []bool) {
+	lines := splitLinesAfter(text)
+	applied := make([]bool, len(ps))
+	// Offset between the expected and actual location of the previous
+	// patch, as in Apply.
+	delta := 0
+	for i, p := range ps {
+		oldLines := splitLinesAfter(DiffText1(p.diffs))
+		newLines := splitLinesAfter(DiffText2(p.diffs))
+		// Offsets of the lines, to find the expected line of the hunk.
+		offsets := make([]int, len(lines)+1)
+		for k, l := range lines {
+			offsets[k+1] = offsets[k] + len(l)
+		}
+		expected := sort.SearchInts(offsets, p.start2+delta)
+
+		// Context lines at the ends of the hunk.
+		top, bottom := 0, 0
+		if n := len(p.diffs); n > 1 {
+			if p.diffs[0].Type == DiffEqual {
+				top = len(splitLinesAfter(p.diffs[0].Text))
+			}
+			if p.diffs[n-1].Type == DiffEqual {
+				bottom = len(splitLinesAfter(p.diffs[n-1].Text))
+			}
+		}
+
+		found := -1
+		var trimTop, trimBottom int
+		var pattern []string
+		for fuzz := 0; fuzz <= patchLineFuzz && found == -1; fuzz++ {
+			trimTop, trimBottom = min(fuzz, top), min(fuzz, bottom)
+			if trimTop+trimBottom >= len(oldLines) && len(oldLines) > 0 {
+				break
+			}
+			pattern = oldLines[trimTop : len(oldLines)-trimBottom]
+			found = nearestLines(lines, pattern, expected+trimTop)
+		}
+		if found == -1 {
+			delta -= p.length2 - p.length1
+			continue
+		}
+		replaced := append([]string{}, lines[:found]...)
+		replaced = append(replaced,
+			newLines[trimTop:len(newLines)-trimBottom]...)
+		lines = append(replaced, lines[found+len(pattern):]...)
+		actual := offsets[found] - len(strings.Join(oldLines[:trimTop], ""))
+		delta = actual - p.start2
+		applied[i] = true
+	}
+	return strings.Join(lines, ""), applied
+}
+
+// nearestLines returns the start of the occurrence of pattern in lines
+// nearest to expected, or -1.  An empty pattern matches at expected.
+func nearestLines(lines, pattern []string, expected int) int {
+	expected = max(0, min(expected, len(lines)))
+	if len(pattern) == 0 {
+		return expected
+	}
+	matches := func(at int) bool {
+		if at < 0 || at+len(pattern) > len(lines) {
+			return false
+		}
+		for k, l := range pattern {
+			if lines[at+k] != l {
+				return false
+			}
+		}
+		return true
+	}
+	for dist := 0; dist <= len(lines); dist++ {
+		if matches(expected - dist) {
+			return expected - dist
+		}
+		if matches(expected + dist) {
+			return expected + dist
+		}
+	}
+	return -1
+}
@@ -0,0 +1,59 @@
+package dmp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchMakeLines(t *testing.T) {
+	dmp := New()
+	lines := []string{}
+	for i := 0; i < 20; i++ {
+		lines = append(lines, "line "+string(rune('a'+i))+"\n")
+	}
+	text1 := strings.Join(lines, "")
+	edited := append([]string{}, lines...)
+	edited[2] = "changed c\n"
+	edited[15] = "changed p\n"
+	text2 := strings.Join(edited, "")
+
+	ps := dmp.PatchMakeLines(text1, text2)
+	assert.Equal(t, 2, len(ps), "Changes far apart get their own hunks.")
+	assert.Equal(t,
+		"@@ -1,42 +1,45 @@\n line a%0Aline b%0A\n-line c%0A\n+changed c%0A\n"+
+			" line d%0Aline e%0Aline f%0A\n", ps[0].String(), "")
+	for _, p := range ps {
+		assert.True(t, strings.HasSuffix(DiffText1(p.diffs), "\n"), "")
+	}
+	patched, applied := dmp.ApplyLines(ps, text1)
+	assert.Equal(t, text2, patched, "")
+	assert.Equal(t, []bool{true, true}, applied, "")
+
+	// Hunks are found by their context lines after the text moved.
+	moved := "new first line\nnew second line\n" + text1
+	patched, applied = dmp.ApplyLines(ps, moved)
+	assert.Equal(t, "new first line\nnew second line\n"+text2, patched, "")
+	assert.Equal(t, []bool{true, true}, applied, "")
+
+	// A changed context line is ignored as fuzz.
+	fuzzy := strings.Replace(text1, "line a\n", "line A\n", 1)
+	patched, applied = dmp.ApplyLines(ps, fuzzy)
+	assert.Equal(t,
+		strings.Replace(text2, "line a\n", "line A\n", 1), patched, "")
+	assert.Equal(t, []bool{true, true}, applied, "")
+
+	// The changed line itself must match.
+	conflict := strings.Replace(text1, "line c\n", "line C\n", 1)
+	patched, applied = dmp.ApplyLines(ps, conflict)
+	assert.Equal(t, []bool{false, true}, applied, "")
+	assert.Equal(t,
+		strings.Replace(conflict, "line p\n", "changed p\n", 1), patched, "")
+
+	// Insertions into an empty text.
+	ps = dmp.PatchMakeLines("", "one\ntwo")
+	patched, applied = dmp.ApplyLines(ps, "")
+	assert.Equal(t, "one\ntwo", patched, "")
+	assert.Equal(t, []bool{true}, applied, "")
+}
@@ -0,0 +1,75 @@
+package dmp
+
+import (
+	"bytes"
+	"time"
+)
+
+// DiffLinesToTokens splits two texts into lines, and reduces them to the
+// indices of their lines in the returned array of distinct lines, for
+// DiffSlices.  Unlike the runes of DiffLinesToRunes, which can not stand
+// for more than about a million distinct lines, indices cover any number
+// of lines.
+func DiffLinesToTokens(s1, s2 string) ([]uint32, []uint32, []string) {
+	return diffTokensToIndices(LineTokenizer, s1, s2)
+}
+
+// DiffTokensToLines rehydrates diffs of the indices of DiffLinesToTokens
+// to diffs of the lines of text.
+func DiffTokensToLines(
+	diffs []SliceDiff[uint32], lineArray []string,
+) []Diff {
+	hydrated := make([]Diff, 0, len(diffs))
+	for _, d := range diffs {
+		var text bytes.Buffer
+		for _, i := range d.Items {
+			text.WriteString(lineArray[i])
+		}
+		hydrated = append(hydrated, Diff{d.Type, text.String()})
+	}
+	return hydrated
+}
+
+// diffTokensToIndices cuts two texts into tokens with t and reduces them
+// to the indices of the tokens in the returned array of distinct tokens.
+func diffTokensToIndices(
+	t Tokenizer, s1, s2 string,
+) ([]uint32, []uint32, []string) {
+	tokenArray := []string{}
+	tokenHash := map[string]uint32{}
+	munge := func(text string) []uint32 {
+		tokens := t.Tokenize(text)
+		ids := make([]uint32, len(tokens))
+		for i, token := range tokens {
+			id, ok := tokenHash[token]
+			if !ok {
+				id = uint32(len(tokenArray))
+				tokenArray = append(tokenArray, token)
+				tokenHash[token] = id
+			}
+			ids[i] = id
+		}
+		return ids
+	}
+	ids1 := munge(s1)
+	ids2 := munge(s2)
+	return ids1, ids2, tokenArray
+}
+
+// diffLines diffs s1 and s2 line by line, with lines longer than maxLen
+// bytes split like in line mode (0 for no limit).  The diffs insert,
+// delete and keep whole lines.
+func (dmp *DMP) diffLines(
+	s1, s2 string, maxLen int, deadline time.Time,
+) []Diff {
+	return dmp.diffTokens(lineTokenizer{maxLen}, s1, s2, deadline)
+}
+
+// diffTokens diffs s1 and s2 cut into tokens by t, of which there may be
+// any number.  The diffs insert, delete and keep whole tokens.
+func (dmp *DMP) diffTokens(
+	t Tokenizer, s1, s2 string, deadline time.Time,
+) []Diff {
+	ids1, ids2, tokens := diffTokensToIndices(t, s1, s2)
+	return DiffTokensToLines(diffSlices(dmp, ids1, ids2, deadline), tokens)
+}
@@ -0,0 +1,47 @@
+package dmp
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffLinesToTokens(t *testing.T) {
+	ids1, ids2, lines := DiffLinesToTokens("a\nb\na\n", "b\nc")
+	assert.Equal(t, []uint32{0, 1, 0}, ids1, "")
+	assert.Equal(t, []uint32{1, 2}, ids2, "")
+	assert.Equal(t, []string{"a\n", "b\n", "c"}, lines, "")
+	assert.Equal(t, []Diff{
+		{DiffDelete, "a\n"},
+		{DiffEqual, "b\n"},
+		{DiffDelete, "a\n"},
+		{DiffInsert, "c"},
+	}, DiffTokensToLines(DiffSlices(ids1, ids2), lines), "")
+
+	// More distinct lines than runes can stand for.
+	var a, b []string
+	for i := 0; i < 70000; i++ {
+		a = append(a, strconv.Itoa(i)+"\n")
+		if i%1000 == 999 {
+			b = append(b, "x"+strconv.Itoa(i)+"\n")
+		} else {
+			b = append(b, strconv.Itoa(i)+"\n")
+		}
+	}
+	text1, text2 := strings.Join(a, ""), strings.Join(b, "")
+	ids1, ids2, lines = DiffLinesToTokens(text1, text2)
+	assert.Equal(t, 70070, len(lines), "")
+	diffs := DiffTokensToLines(DiffSlices(ids1, ids2), lines)
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.Equal(t, 210, len(diffs), "")
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+	diffs = dmp.DiffMain(text1, text2, true)
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.Equal(t, 70, DiffLevenshtein(diffs), "")
+}
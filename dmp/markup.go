@@ -0,0 +1,42 @@
+package dmp
+
+import (
+	"regexp"
+)
+
+// MarkupTags matches HTML and XML tags, comments included.
+var MarkupTags = regexp.MustCompile(`<!--[\s\S]*?-->|<[^<>]*>`)
+
+// MarkupTokenizer cuts a text into the whole matches of tag, such as
+// MarkupTags, and single characters of the text between them.
+func MarkupTokenizer(tag *regexp.Regexp) Tokenizer {
+	return TokenizerFunc(func(text string) []string {
+		tokens := []string{}
+		content := func(s string) {
+			for _, r := range s {
+				tokens = append(tokens, string(r))
+			}
+		}
+		pos := 0
+		for _, m := range tag.FindAllStringIndex(text, -1) {
+			content(text[pos:m[0]])
+			if m[1] > m[0] {
+				tokens = append(tokens, text[m[0]:m[1]])
+			}
+			pos = m[1]
+		}
+		content(text[pos:])
+		return tokens
+	})
+}
+
+// DiffMarkup diffs two documents cut into tokens by t, such as a
+// MarkupTokenizer, so that the diffs insert, delete and keep whole tokens
+// only: the text around the tags is diffed character by character, but a
+// changed tag is replaced whole instead of torn apart mid-element.
+//
+// Cleanups that shift edits, such as DiffCleanupSemantic, work on the
+// characters of the diffs and may cut tags again.
+func (dmp *DMP) DiffMarkup(text1, text2 string, t Tokenizer) []Diff {
+	return dmp.diffTokens(t, text1, text2, deadline(dmp.DiffTimeout))
+}
@@ -0,0 +1,39 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestMarkupTokenizer(t *testing.T) {
+	tok := MarkupTokenizer(MarkupTags)
+	assert.Equal(t,
+		[]string{"<p>", "é", "<", "<!-- x>y -->", "<br/>", "</p>"},
+		tok.Tokenize("<p>é<<!-- x>y --><br/></p>"), "")
+	assert.Equal(t, []string{}, tok.Tokenize(""), "")
+}
+
+func TestDiffMarkup(t *testing.T) {
+	dmp := New()
+	text1 := `<p class="a">Hello <b>world</b></p>`
+	text2 := `<p class="b">Hello <i>world</i>!</p>`
+	diffs := dmp.DiffMarkup(text1, text2, MarkupTokenizer(MarkupTags))
+	assert.Equal(t, []Diff{
+		{DiffDelete, `<p class="a">`},
+		{DiffInsert, `<p class="b">`},
+		{DiffEqual, "Hello "},
+		{DiffDelete, "<b>"},
+		{DiffInsert, "<i>"},
+		{DiffEqual, "world"},
+		{DiffDelete, "</b>"},
+		{DiffInsert, "</i>!"},
+		{DiffEqual, "</p>"},
+	}, diffs, "")
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+
+	// A plain diff tears the tags apart.
+	plain := dmp.DiffMain(text1, text2, false)
+	assert.Equal(t, Diff{DiffEqual, `<p class="`}, plain[0], "")
+}
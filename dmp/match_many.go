@@ -0,0 +1,122 @@
+package dmp
+
+import (
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// matchGram is the length of the text grams indexed by matchIndex.
+const matchGram = 4
+
+// matchBuckets is the number of hash buckets of matchIndex.
+const matchBuckets = 1 << 16
+
+// matchIndex records where each matchGram-byte gram of a text starts, so
+// that exact occurrences of many patterns can be found without scanning
+// the text again for each of them.  The positions are grouped by the hash
+// of their gram, in increasing order: bucket h holds
+// pos[start[h]:start[h+1]].  A nil *matchIndex scans.
+type matchIndex struct {
+	start []int32
+	pos   []int32
+}
+
+func gramBucket(s string) int {
+	k := uint32(s[0])<<24 | uint32(s[1])<<16 | uint32(s[2])<<8 |
+		uint32(s[3])
+	return int(k * 2654435761 >> 16)
+}
+
+func newMatchIndex(text string) *matchIndex {
+	mi := &matchIndex{start: make([]int32, matchBuckets+1)}
+	n := len(text) - matchGram + 1
+	if n <= 0 {
+		return mi
+	}
+	// Counting sort of the positions by bucket.
+	for i := 0; i < n; i++ {
+		mi.start[gramBucket(text[i:])+1]++
+	}
+	for h := 1; h <= matchBuckets; h++ {
+		mi.start[h] += mi.start[h-1]
+	}
+	mi.pos = make([]int32, n)
+	next := append([]int32{}, mi.start[:matchBuckets]...)
+	for i := 0; i < n; i++ {
+		h := gramBucket(text[i:])
+		mi.pos[next[h]] = int32(i)
+		next[h]++
+	}
+	return mi
+}
+
+func (mi *matchIndex) bucket(pattern string) []int32 {
+	h := gramBucket(pattern)
+	return mi.pos[mi.start[h]:mi.start[h+1]]
+}
+
+// indexOf is like the function of the same name, using the index.
+func (mi *matchIndex) indexOf(text, pattern string, i int) int {
+	if mi == nil || len(pattern) < matchGram {
+		return indexOf(text, pattern, i)
+	}
+	if i > len(text)-1 {
+		return -1
+	}
+	pos := mi.bucket(pattern)
+	first := sort.Search(len(pos), func(j int) bool {
+		return int(pos[j]) >= i
+	})
+	for j := first; j < len(pos); j++ {
+		p := int(pos[j])
+		if p+len(pattern) <= len(text) && text[p:p+len(pattern)] == pattern {
+			return p
+		}
+	}
+	return -1
+}
+
+// lastIndexOf is like the function of the same name, using the index.
+func (mi *matchIndex) lastIndexOf(text, pattern string, i int) int {
+	if mi == nil || len(pattern) < matchGram {
+		return lastIndexOf(text, pattern, i)
+	}
+	if i < 0 {
+		return -1
+	}
+	// The occurrence must end by the end of the rune at i.
+	limit := len(text)
+	if i < len(text) {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		limit = i + size
+	}
+	pos := mi.bucket(pattern)
+	last := sort.Search(len(pos), func(j int) bool {
+		return int(pos[j])+len(pattern) > limit
+	}) - 1
+	for j := last; j >= 0; j-- {
+		p := int(pos[j])
+		if text[p:p+len(pattern)] == pattern {
+			return p
+		}
+	}
+	return -1
+}
+
+// MatchMany locates the best instance of each pattern near the location
+// of the same index in locs, like MatchMain.  The text is indexed once and
+// shared by all patterns, which makes it much faster than calling
+// MatchMain in a loop when there are many patterns, such as the hunks of a
+// large patch set applied to one large text.
+func (dmp *DMP) MatchMany(text string, patterns []string, locs []int) []int {
+	ret := make([]int, len(patterns))
+	var mi *matchIndex
+	if len(patterns) > 1 && len(text) <= math.MaxInt32 {
+		mi = newMatchIndex(text)
+	}
+	for i, pattern := range patterns {
+		ret[i] = matchMain(dmp, text, pattern, locs[i], mi)
+	}
+	return ret
+}
@@ -0,0 +1,74 @@
+package dmp
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestMatchIndex(t *testing.T) {
+	text := "abcdabcdxyzé abcd"
+	mi := newMatchIndex(text)
+	for _, pattern := range []string{"abcd", "bcdx", "ab", "zé a", "nope", "abcdabcdxyzé abcd!"} {
+		for i := -1; i <= len(text)+1; i++ {
+			assert.Equal(t, indexOf(text, pattern, i), mi.indexOf(text, pattern, i), pattern)
+			assert.Equal(t, lastIndexOf(text, pattern, i), mi.lastIndexOf(text, pattern, i), pattern)
+		}
+	}
+}
+
+func TestMatchMany(t *testing.T) {
+	dmp := New()
+	text := readFile("speedtest1.txt", t)
+	rnd := rand.New(rand.NewSource(1))
+	var patterns []string
+	var locs []int
+	for i := 0; i < 50; i++ {
+		start := rnd.Intn(len(text) - 32)
+		pattern := text[start : start+10+rnd.Intn(22)]
+		if i%3 == 0 {
+			// Fuzzy match.
+			pattern = strings.Replace(pattern, "e", "x", 1)
+		}
+		patterns = append(patterns, pattern)
+		locs = append(locs, start+rnd.Intn(200)-100)
+	}
+	patterns = append(patterns, "abc", "")
+	locs = append(locs, 0, 5)
+
+	got := dmp.MatchMany(text, patterns, locs)
+	for i := range patterns {
+		assert.Equal(t, dmp.MatchMain(text, patterns[i], locs[i]), got[i], patterns[i])
+	}
+}
+
+func benchmarkMatch(b *testing.B, many bool) {
+	dmp := New()
+	text := strings.Repeat(readFile("speedtest1.txt", b), 10)
+	var patterns []string
+	var locs []int
+	for start := 0; start+20 < len(text); start += 500 {
+		patterns = append(patterns, text[start:start+20]+"!")
+		locs = append(locs, start)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if many {
+			dmp.MatchMany(text, patterns, locs)
+			continue
+		}
+		for j := range patterns {
+			dmp.MatchMain(text, patterns[j], locs[j])
+		}
+	}
+}
+
+func Benchmark_MatchMany(b *testing.B) {
+	benchmarkMatch(b, true)
+}
+
+func Benchmark_MatchMainLoop(b *testing.B) {
+	benchmarkMatch(b, false)
+}
@@ -0,0 +1,249 @@
+package dmp
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+// MergeChunk is a piece of a three-way merge: a range of the base text and
+// the texts that ours and theirs have in its place.
+type MergeChunk struct {
+	Base   string
+	Ours   string
+	Theirs string
+	// Conflict is true if both sides changed the base differently.
+	Conflict bool
+}
+
+// Merged returns the merged text of a chunk without conflict: the text of
+// the side that changed it, if any.
+func (c MergeChunk) Merged() string {
+	if c.Ours != c.Base {
+		return c.Ours
+	}
+	return c.Theirs
+}
+
+// Merge3 merges the changes ours and theirs made to base.  Where only one
+// side changed the base, or both made the same change, its change is
+// taken; changes of both sides that overlap or touch are conflicts.  The
+// chunks cover the base in order.
+func (dmp *DMP) Merge3(base, ours, theirs string) []MergeChunk {
+	dmp = dmp.exact()
+	hunks1 := mergeHunks(
+		dmp.DiffCleanupSemantic(dmp.DiffMain(base, ours, true)),
+	)
+	hunks2 := mergeHunks(
+		dmp.DiffCleanupSemantic(dmp.DiffMain(base, theirs, true)),
+	)
+
+	chunks := []MergeChunk{}
+	pos := 0
+	for len(hunks1) > 0 || len(hunks2) > 0 {
+		// Start a group with the first hunk, and add the hunks of either
+		// side that overlap or touch it.
+		start := len(base)
+		if len(hunks1) > 0 {
+			start = hunks1[0].start
+		}
+		if len(hunks2) > 0 {
+			start = min(start, hunks2[0].start)
+		}
+		end := start
+		n1, n2 := 0, 0
+		for {
+			if n1 < len(hunks1) && hunks1[n1].start <= end {
+				end = max(end, hunks1[n1].end)
+				n1++
+			} else if n2 < len(hunks2) && hunks2[n2].start <= end {
+				end = max(end, hunks2[n2].end)
+				n2++
+			} else {
+				break
+			}
+		}
+
+		if pos < start {
+			text := base[pos:start]
+			chunks = append(chunks, MergeChunk{text, text, text, false})
+		}
+		c := MergeChunk{
+			Base:   base[start:end],
+			Ours:   applyHunks(base, start, end, hunks1[:n1]),
+			Theirs: applyHunks(base, start, end, hunks2[:n2]),
+		}
+		c.Conflict = c.Ours != c.Base && c.Theirs != c.Base &&
+			c.Ours != c.Theirs
+		chunks = append(chunks, c)
+		pos = end
+		hunks1, hunks2 = hunks1[n1:], hunks2[n2:]
+	}
+	if pos < len(base) {
+		text := base[pos:]
+		chunks = append(chunks, MergeChunk{text, text, text, false})
+	}
+	return chunks
+}
+
+// mergeHunk replaces the base text from start to end with text.
+type mergeHunk struct {
+	start, end int
+	text       string
+}
+
+// mergeHunks returns the changes diffs make to their text1, in order.
+func mergeHunks(diffs []Diff) []mergeHunk {
+	hunks := []mergeHunk{}
+	pos := 0
+	var h *mergeHunk
+	for _, d := range diffs {
+		if d.Type == DiffEqual {
+			pos += len(d.Text)
+			h = nil
+			continue
+		}
+		if h == nil {
+			hunks = append(hunks, mergeHunk{start: pos, end: pos})
+			h = &hunks[len(hunks)-1]
+		}
+		if d.Type == DiffDelete {
+			pos += len(d.Text)
+			h.end = pos
+		} else {
+			h.text += d.Text
+		}
+	}
+	return hunks
+}
+
+// applyHunks returns the text of base from start to end with hunks, which
+// lie within, applied.
+func applyHunks(base string, start, end int, hunks []mergeHunk) string {
+	var buf bytes.Buffer
+	pos := start
+	for _, h := range hunks {
+		buf.WriteString(base[pos:h.start])
+		buf.WriteString(h.text)
+		pos = h.end
+	}
+	buf.WriteString(base[pos:end])
+	return buf.String()
+}
+
+// MergeStrategy resolves the conflicts of a three-way merge.
+type MergeStrategy int8
+
+const (
+	// MergeManual leaves the conflict to be resolved by hand.
+	MergeManual MergeStrategy = iota
+	// MergeOurs takes the text of ours.
+	MergeOurs
+	// MergeTheirs takes the text of theirs.
+	MergeTheirs
+	// MergeUnion takes the text of ours followed by that of theirs.
+	MergeUnion
+	// MergeNewest takes the text of the side changed last, according to
+	// MergePolicy.Times, and leaves ties to be resolved by hand.
+	MergeNewest
+)
+
+// MergePolicy selects how ResolveConflicts resolves conflicts.
+type MergePolicy struct {
+	// Strategy for all conflicts, unless Choose is set.
+	Strategy MergeStrategy
+	// Choose returns the strategy for a conflict (nil to use Strategy).
+	Choose func(c MergeChunk) MergeStrategy
+	// Times returns when ours and theirs last changed the text of a
+	// conflict, from the metadata of the sync backend, for MergeNewest.
+	Times func(c MergeChunk) (ours, theirs time.Time)
+}
+
+// MergeResolution reports the strategy that resolved a conflict.
+type MergeResolution struct {
+	// Index of the conflict in the chunks.
+	Chunk    int
+	Strategy MergeStrategy
+	// Text the conflict was resolved with.
+	Text string
+}
+
+// ResolveConflicts joins the merged text of chunks, resolving their
+// conflicts by policy, and reports the conflicts resolved.  Conflicts left
+// to be resolved by hand are written with conflict markers, as by diff3,
+// and make ok false.
+func ResolveConflicts(
+	chunks []MergeChunk, policy MergePolicy,
+) (text string, resolved []MergeResolution, ok bool) {
+	var buf bytes.Buffer
+	ok = true
+	for i, c := range chunks {
+		if !c.Conflict {
+			buf.WriteString(c.Merged())
+			continue
+		}
+		strategy := policy.Strategy
+		if policy.Choose != nil {
+			strategy = policy.Choose(c)
+		}
+		var resolution string
+		switch strategy {
+		case MergeOurs:
+			resolution = c.Ours
+		case MergeTheirs:
+			resolution = c.Theirs
+		case MergeUnion:
+			resolution = c.Ours + c.Theirs
+		case MergeNewest:
+			if policy.Times == nil {
+				strategy = MergeManual
+				break
+			}
+			ours, theirs := policy.Times(c)
+			if ours.After(theirs) {
+				resolution = c.Ours
+			} else if theirs.After(ours) {
+				resolution = c.Theirs
+			} else {
+				strategy = MergeManual
+			}
+		default:
+			strategy = MergeManual
+		}
+		if strategy == MergeManual {
+			writeConflict(&buf, c)
+			ok = false
+			continue
+		}
+		buf.WriteString(resolution)
+		resolved = append(resolved, MergeResolution{i, strategy, resolution})
+	}
+	return buf.String(), resolved, ok
+}
+
+// writeConflict writes c between diff3 conflict markers, each on a line of
+// its own.
+func writeConflict(buf *bytes.Buffer, c MergeChunk) {
+	line := func(s string) {
+		buf.WriteString(s)
+		if !strings.HasSuffix(s, "\n") {
+			buf.WriteByte('\n')
+		}
+	}
+	if buf.Len() > 0 && !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	line("<<<<<<< ours")
+	if c.Ours != "" {
+		line(c.Ours)
+	}
+	line("||||||| base")
+	if c.Base != "" {
+		line(c.Base)
+	}
+	line("=======")
+	if c.Theirs != "" {
+		line(c.Theirs)
+	}
+	line(">>>>>>> theirs")
+}
@@ -0,0 +1,105 @@
+package dmp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestMerge3(t *testing.T) {
+	dmp := New()
+	base := "one\ntwo\nthree\nfour\nfive\n"
+	ours := "one\n2\nthree\nfour\nfive\n"
+	theirs := "one\ntwo\nthree\nfour\n5\n"
+	chunks := dmp.Merge3(base, ours, theirs)
+	text, resolved, ok := ResolveConflicts(chunks, MergePolicy{})
+	assert.True(t, ok, "")
+	assert.Equal(t, 0, len(resolved), "")
+	assert.Equal(t, "one\n2\nthree\nfour\n5\n", text, "")
+
+	// The same change on both sides is no conflict.
+	chunks = dmp.Merge3(base, ours, ours)
+	text, _, ok = ResolveConflicts(chunks, MergePolicy{})
+	assert.True(t, ok, "")
+	assert.Equal(t, ours, text, "")
+
+	theirs = "one\ndeux\nthree\nfour\nfive\n"
+	chunks = dmp.Merge3(base, ours, theirs)
+	assert.Equal(t, []MergeChunk{
+		{"one\n", "one\n", "one\n", false},
+		{"two", "2", "deux", true},
+		{"\nthree\nfour\nfive\n", "\nthree\nfour\nfive\n",
+			"\nthree\nfour\nfive\n", false},
+	}, chunks, "")
+
+	text, resolved, ok = ResolveConflicts(chunks, MergePolicy{})
+	assert.False(t, ok, "")
+	assert.Equal(t, "one\n<<<<<<< ours\n2\n||||||| base\ntwo\n=======\n"+
+		"deux\n>>>>>>> theirs\n\nthree\nfour\nfive\n", text, "")
+
+	for strategy, want := range map[MergeStrategy]string{
+		MergeOurs:   ours,
+		MergeTheirs: theirs,
+		MergeUnion:  "one\n2deux\nthree\nfour\nfive\n",
+	} {
+		text, resolved, ok = ResolveConflicts(
+			chunks, MergePolicy{Strategy: strategy},
+		)
+		assert.True(t, ok, "")
+		assert.Equal(t, want, text, "")
+		assert.Equal(t, 1, len(resolved), "")
+		assert.Equal(t, 1, resolved[0].Chunk, "")
+		assert.Equal(t, strategy, resolved[0].Strategy, "")
+	}
+
+	now := time.Now()
+	policy := MergePolicy{
+		Strategy: MergeNewest,
+		Times: func(c MergeChunk) (time.Time, time.Time) {
+			return now, now.Add(time.Second)
+		},
+	}
+	text, resolved, ok = ResolveConflicts(chunks, policy)
+	assert.True(t, ok, "")
+	assert.Equal(t, theirs, text, "")
+	assert.Equal(t, []MergeResolution{{1, MergeNewest, "deux"}}, resolved, "")
+
+	// Ties and missing times are left to be resolved by hand.
+	policy.Times = func(c MergeChunk) (time.Time, time.Time) {
+		return now, now
+	}
+	_, resolved, ok = ResolveConflicts(chunks, policy)
+	assert.False(t, ok, "")
+	assert.Equal(t, 0, len(resolved), "")
+	_, _, ok = ResolveConflicts(chunks, MergePolicy{Strategy: MergeNewest})
+	assert.False(t, ok, "")
+
+	policy = MergePolicy{Choose: func(c MergeChunk) MergeStrategy {
+		if c.Base == "two" {
+			return MergeTheirs
+		}
+		return MergeOurs
+	}}
+	text, _, ok = ResolveConflicts(chunks, policy)
+	assert.True(t, ok, "")
+	assert.Equal(t, theirs, text, "")
+}
+
+func TestMerge3Insertions(t *testing.T) {
+	dmp := New()
+	chunks := dmp.Merge3("ac", "abc", "ac!")
+	text, _, ok := ResolveConflicts(chunks, MergePolicy{})
+	assert.True(t, ok, "")
+	assert.Equal(t, "abc!", text, "")
+
+	// Insertions at the same place conflict.
+	chunks = dmp.Merge3("ac", "abc", "axc")
+	text, _, ok = ResolveConflicts(chunks, MergePolicy{Strategy: MergeUnion})
+	assert.True(t, ok, "")
+	assert.Equal(t, "abxc", text, "")
+
+	chunks = dmp.Merge3("", "a", "")
+	assert.Equal(t, []MergeChunk{{"", "a", "", false}}, chunks, "")
+	assert.Equal(t, []MergeChunk{}, dmp.Merge3("", "", ""), "")
+}
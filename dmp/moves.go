@@ -0,0 +1,123 @@
+package dmp
+
+import (
+	"unicode/utf8"
+)
+
+// DiffDetectMoves marks blocks of at least minLength bytes that diffs
+// delete in one place and insert in another as moves: the deleted copy
+// becomes a DiffMoveFrom and the inserted one a DiffMove with the same
+// text.  Blocks may be parts of larger deletions and insertions, and the
+// longest are taken first.  A deletion and an insertion with no equality
+// between them replace one text with another, and are never a move.  The
+// search is quadratic in the length of the edits.
+//
+// Moves are for display, as by DiffPrettyHtml.  The other functions of
+// this package take them as the deletions and insertions they are, and
+// those that return diffs, such as the cleanups, return them as such.
+func DiffDetectMoves(diffs []Diff, minLength int) []Diff {
+	diffs = append([]Diff(nil), diffs...)
+	minLength = max(minLength, 1)
+	for {
+		del, ins := -1, -1
+		var at1, at2, n int
+		for i, d := range diffs {
+			if d.Type != DiffDelete || len(d.Text) < minLength {
+				continue
+			}
+			for j, e := range diffs {
+				if e.Type != DiffInsert || len(e.Text) < minLength ||
+					!equalBetween(diffs, i, j) {
+					continue
+				}
+				a, b, l := longestCommonSubstring(d.Text, e.Text)
+				if l >= minLength && l > n {
+					del, ins, at1, at2, n = i, j, a, b, l
+				}
+			}
+		}
+		if del == -1 {
+			return diffs
+		}
+		// Split the later diff first, so that the index of the other one
+		// still holds.
+		if ins > del {
+			diffs = splitMoved(diffs, ins, at2, n, DiffMove)
+			diffs = splitMoved(diffs, del, at1, n, DiffMoveFrom)
+		} else {
+			diffs = splitMoved(diffs, del, at1, n, DiffMoveFrom)
+			diffs = splitMoved(diffs, ins, at2, n, DiffMove)
+		}
+	}
+}
+
+// withoutMoves returns diffs with their moves turned back into deletions
+// and insertions, or diffs itself if it has none.
+func withoutMoves(diffs []Diff) []Diff {
+	for i, d := range diffs {
+		if d.Type.edit() == d.Type {
+			continue
+		}
+		ret := append([]Diff{}, diffs...)
+		for j := i; j < len(ret); j++ {
+			ret[j].Type = ret[j].Type.edit()
+		}
+		return ret
+	}
+	return diffs
+}
+
+// equalBetween tells whether there is an equality between diffs i and j.
+func equalBetween(diffs []Diff, i, j int) bool {
+	for k := min(i, j) + 1; k < max(i, j); k++ {
+		if diffs[k].Type == DiffEqual {
+			return true
+		}
+	}
+	return false
+}
+
+// splitMoved replaces diffs[k] with its parts before, in and after the n
+// bytes at at, the middle one having type op.
+func splitMoved(diffs []Diff, k, at, n int, op Operation) []Diff {
+	d := diffs[k]
+	parts := []Diff{}
+	if at > 0 {
+		parts = append(parts, Diff{d.Type, d.Text[:at]})
+	}
+	parts = append(parts, Diff{op, d.Text[at : at+n]})
+	if at+n < len(d.Text) {
+		parts = append(parts, Diff{d.Type, d.Text[at+n:]})
+	}
+	return append(diffs[:k], append(parts, diffs[k+1:]...)...)
+}
+
+// longestCommonSubstring returns where the longest common substring of a
+// and b starts in each and its length, cut to whole runes.
+func longestCommonSubstring(a, b string) (int, int, int) {
+	at1, at2, n := 0, 0, 0
+	// prev[j] and cur[j] are the lengths of the common suffixes of a up to
+	// the previous and current byte and b up to byte j.
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := 0; i < len(a); i++ {
+		for j := 0; j < len(b); j++ {
+			cur[j+1] = 0
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+				if cur[j+1] > n {
+					at1, at2, n = i+1-cur[j+1], j+1-cur[j+1], cur[j+1]
+				}
+			}
+		}
+		prev, cur = cur, prev
+	}
+	for n > 0 && !utf8.RuneStart(a[at1]) {
+		at1, at2, n = at1+1, at2+1, n-1
+	}
+	for n > 0 && (at1+n < len(a) && !utf8.RuneStart(a[at1+n]) ||
+		at2+n < len(b) && !utf8.RuneStart(b[at2+n])) {
+		n--
+	}
+	return at1, at2, n
+}
@@ -0,0 +1,109 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffDetectMoves(t *testing.T) {
+	dmp := New()
+	text1 := "Alpha. Bravo charlie delta. Echo foxtrot."
+	text2 := "Bravo charlie delta. Alpha. Echo golf."
+	diffs := dmp.DiffCleanupSemantic(dmp.DiffMain(text1, text2, false))
+	moved := DiffDetectMoves(diffs, 6)
+	assert.Equal(t, text1, DiffText1(moved), "")
+	assert.Equal(t, text2, DiffText2(moved), "")
+	var from, to []string
+	for _, d := range moved {
+		switch d.Type {
+		case DiffMoveFrom:
+			from = append(from, d.Text)
+		case DiffMove:
+			to = append(to, d.Text)
+		}
+	}
+	assert.Equal(t, []string{"Alpha. "}, from, "")
+	assert.Equal(t, from, to, "")
+
+	// Part of a deletion moves into part of an insertion.
+	diffs = []Diff{
+		{DiffDelete, "one two three"},
+		{DiffEqual, " and "},
+		{DiffInsert, "four two three five"},
+	}
+	assert.Equal(t, []Diff{
+		{DiffDelete, "one"},
+		{DiffMoveFrom, " two three"},
+		{DiffEqual, " and "},
+		{DiffInsert, "four"},
+		{DiffMove, " two three"},
+		{DiffInsert, " five"},
+	}, DiffDetectMoves(diffs, 5), "")
+
+	// Too short.
+	assert.Equal(t, diffs, DiffDetectMoves(diffs, 11), "")
+
+	// A replacement is not a move.
+	diffs = []Diff{
+		{DiffEqual, "x"},
+		{DiffDelete, "same text"},
+		{DiffInsert, "same text!"},
+	}
+	assert.Equal(t, diffs, DiffDetectMoves(diffs, 1), "")
+
+	// Moves are cut to whole runes.
+	diffs = []Diff{
+		{DiffDelete, "été"},
+		{DiffEqual, " "},
+		{DiffInsert, "èté"},
+	}
+	assert.Equal(t, []Diff{
+		{DiffDelete, "é"},
+		{DiffMoveFrom, "té"},
+		{DiffEqual, " "},
+		{DiffInsert, "è"},
+		{DiffMove, "té"},
+	}, DiffDetectMoves(diffs, 2), "")
+}
+
+func TestDiffMovesAsEdits(t *testing.T) {
+	// Functions that know only deletions and insertions take moves as
+	// such.
+	dmp := New()
+	text1 := "Alpha. Bravo charlie delta. Echo foxtrot."
+	text2 := "Bravo charlie delta. Alpha. Echo golf."
+	diffs := dmp.DiffMain(text1, text2, false)
+	moved := DiffDetectMoves(diffs, 6)
+	assert.NotEqual(t, diffs, moved, "")
+	edits := withoutMoves(moved)
+
+	assert.Equal(t, DiffLevenshtein(edits), DiffLevenshtein(moved), "")
+	assert.Equal(t, DiffToDelta(edits), DiffToDelta(moved), "")
+	for loc := 0; loc <= len(text1); loc++ {
+		assert.Equal(t, DiffXIndex(edits, loc), DiffXIndex(moved, loc), "")
+	}
+	assert.Equal(t, DiffCleanupMerge(edits), DiffCleanupMerge(moved), "")
+	data, err := DiffsToJSON(moved)
+	assert.Nil(t, err, "")
+	decoded, err := DiffsFromJSON(data)
+	assert.Nil(t, err, "")
+	assert.Equal(t, edits, decoded, "")
+
+	ps := dmp.PatchMake(text1, moved)
+	assert.Equal(t, PatchToText(dmp.PatchMake(text1, edits)),
+		PatchToText(ps), "")
+	patched, _ := dmp.Apply(ps, text1)
+	assert.Equal(t, text2, patched, "")
+}
+
+func TestDiffPrettyHtmlMoves(t *testing.T) {
+	diffs := []Diff{
+		{DiffMoveFrom, "a"},
+		{DiffEqual, "b"},
+		{DiffMove, "a"},
+	}
+	assert.Equal(t, "<del style=\"background:#e6e6ff;\">a</del>"+
+		"<span>b</span><ins style=\"background:#e6e6ff;\">a</ins>",
+		DiffPrettyHtml(diffs), "")
+}
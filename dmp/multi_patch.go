@@ -0,0 +1,273 @@
+package dmp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DevNull is the path used for the missing side of a created or deleted
+// file, as in diff(1) output.
+const DevNull = "/dev/null"
+
+// MultiPatch holds the patches of a change touching several files.
+type MultiPatch struct {
+	Files []*FilePatch
+}
+
+// FileResult reports the outcome of applying the patches of one file.
+type FileResult struct {
+	Path string
+	// Applied holds one entry per patch (after splitting, as in Apply).
+	Applied []bool
+	// Written is true if the file was updated, which only happens when all
+	// of its patches applied.
+	Written bool
+}
+
+// String emits the patches of all files in the layout of a .patch file:
+// each file starts with "--- a/old" and "+++ b/new" headers followed by its
+// hunks.  Binary files carry their new content base64-encoded after a
+// "literal" line.  Identical files are omitted unless renamed.  Only the
+// file headers follow unified diffs: the hunks are the patch text of
+// PatchToText, with character offsets and escaped text, which patch(1)
+// and git apply do not read.
+func (mp *MultiPatch) String() string {
+	var buf bytes.Buffer
+	for _, fp := range mp.Files {
+		if fp.Kind == FileIdentical && fp.OldPath == fp.NewPath {
+			continue
+		}
+		oldPath := prefixPath("a/", fp.OldPath)
+		newPath := prefixPath("b/", fp.NewPath)
+		buf.WriteString("--- " + oldPath + "\n")
+		buf.WriteString("+++ " + newPath + "\n")
+		if fp.Kind != FileBinary {
+			buf.WriteString(PatchToText(fp.Patches))
+			continue
+		}
+
+		buf.WriteString("Binary files " + oldPath + " and " + newPath +
+			" differ\n")
+		buf.WriteString("literal " + strconv.Itoa(len(fp.Binary)) + "\n")
+		data := base64.StdEncoding.EncodeToString(fp.Binary)
+		for len(data) > 76 {
+			buf.WriteString(data[:76] + "\n")
+			data = data[76:]
+		}
+		buf.WriteString(data + "\n")
+	}
+	return buf.String()
+}
+
+func prefixPath(prefix, path string) string {
+	if path == DevNull {
+		return path
+	}
+	return prefix + path
+}
+
+func unprefixPath(prefix, path string) string {
+	if path == DevNull {
+		return path
+	}
+	return strings.TrimPrefix(path, prefix)
+}
+
+// MultiPatchFromText parses the output of MultiPatch.String.  Lines before
+// the first "---" header and "diff" command lines are ignored.
+func MultiPatchFromText(text string) (*MultiPatch, error) {
+	mp := &MultiPatch{}
+	lines := strings.Split(text, "\n")
+	i := 0
+	for i < len(lines) {
+		if !strings.HasPrefix(lines[i], "--- ") {
+			i++
+			continue
+		}
+		if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+			return nil, fmt.Errorf("Missing +++ header after: %s", lines[i])
+		}
+		fp := &FilePatch{
+			OldPath: unprefixPath("a/", lines[i][4:]),
+			NewPath: unprefixPath("b/", lines[i+1][4:]),
+			Kind:    FileText,
+		}
+		i += 2
+
+		// Collect the body up to the next file.  Hunks end where their
+		// headers say, as deleted text may start with "-- ".
+		start := i
+		if i < len(lines) && strings.HasPrefix(lines[i], "@@ ") {
+			i = patchTextEnd(lines, i)
+		} else {
+			for i < len(lines) && !strings.HasPrefix(lines[i], "--- ") &&
+				!strings.HasPrefix(lines[i], "diff ") {
+				i++
+			}
+		}
+		body := lines[start:i]
+
+		if len(body) > 0 && strings.HasPrefix(body[0], "Binary files ") {
+			data, err := parseBinaryBody(body[1:])
+			if err != nil {
+				return nil, err
+			}
+			fp.Kind = FileBinary
+			fp.Binary = data
+		} else {
+			ps, err := PatchFromText(strings.Join(body, "\n"))
+			if err != nil {
+				return nil, err
+			}
+			fp.Patches = ps
+		}
+		mp.Files = append(mp.Files, fp)
+	}
+	return mp, nil
+}
+
+// patchTextEnd returns the index of the line after the hunks of patch
+// text starting at lines[i], counting the text of each hunk against the
+// lengths in its header.
+func patchTextEnd(lines []string, i int) int {
+	for i < len(lines) {
+		p, ok := parsePatchHeader(lines[i])
+		if !ok {
+			break
+		}
+		i++
+		left1, left2 := p.length1, p.length2
+		for i < len(lines) && (left1 > 0 || left2 > 0) {
+			line := lines[i]
+			if line == "" {
+				i++
+				continue
+			}
+			n := len(unescapePatchText(line[1:]))
+			switch line[0] {
+			case '-':
+				left1 -= n
+			case '+':
+				left2 -= n
+			case ' ':
+				left1 -= n
+				left2 -= n
+			default:
+				// Let PatchFromText report the mismatch.
+				return i
+			}
+			i++
+		}
+	}
+	return i
+}
+
+func parseBinaryBody(body []string) ([]byte, error) {
+	if len(body) == 0 || !strings.HasPrefix(body[0], "literal ") {
+		return nil, fmt.Errorf("Missing literal in binary patch")
+	}
+	n, err := strconv.Atoi(body[0][len("literal "):])
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(
+		strings.Join(body[1:], ""),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != n {
+		return nil, fmt.Errorf(
+			"Binary length mismatch: %d != %d", len(data), n,
+		)
+	}
+	return data, nil
+}
+
+// ApplyMultiPatch applies the patches under the directory root.  A file is
+// only written when all of its patches applied; a new path of DevNull
+// removes the file, and another new path removes the old one once the
+// new one is written.  Paths may not escape root.
+func (dmp *DMP) ApplyMultiPatch(mp *MultiPatch, root string) (
+	[]FileResult, error,
+) {
+	results := []FileResult{}
+	for _, fp := range mp.Files {
+		if fp.Kind == FileIdentical && fp.OldPath == fp.NewPath {
+			continue
+		}
+		r := FileResult{Path: fp.NewPath}
+		if fp.NewPath == DevNull {
+			r.Path = fp.OldPath
+		}
+
+		content, oldPath := "", ""
+		if fp.OldPath != DevNull {
+			var err error
+			oldPath, err = rootedPath(root, fp.OldPath)
+			if err != nil {
+				return results, err
+			}
+			b, err := ioutil.ReadFile(oldPath)
+			if err != nil {
+				return results, err
+			}
+			content = string(b)
+		}
+
+		ok := true
+		if fp.Kind == FileBinary {
+			content = string(fp.Binary)
+		} else {
+			content, r.Applied = dmp.Apply(fp.Patches, content)
+			for _, applied := range r.Applied {
+				ok = ok && applied
+			}
+		}
+
+		if ok {
+			path, err := rootedPath(root, r.Path)
+			if err != nil {
+				return results, err
+			}
+			if fp.NewPath == DevNull {
+				err = os.Remove(path)
+			} else {
+				err = writeFileAll(path, []byte(content))
+				if err == nil && oldPath != "" && oldPath != path {
+					// Renamed.
+					err = os.Remove(oldPath)
+				}
+			}
+			if err != nil {
+				return results, err
+			}
+			r.Written = true
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// rootedPath joins root and a relative patch path, refusing paths that
+// leave root.
+func rootedPath(root, path string) (string, error) {
+	clean := filepath.Clean("/" + filepath.FromSlash(path))
+	if clean == string(filepath.Separator) || path == "" {
+		return "", fmt.Errorf("Invalid path in patch: %q", path)
+	}
+	return filepath.Join(root, clean), nil
+}
+
+func writeFileAll(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
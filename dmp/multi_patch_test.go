@@ -0,0 +1,116 @@
+package dmp
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestMultiPatchText(t *testing.T) {
+	dmp := New()
+	mp := &MultiPatch{Files: []*FilePatch{
+		{OldPath: "a.txt", NewPath: "a.txt", Kind: FileText,
+			Patches: dmp.PatchMake("hello world\n", "hello there\n")},
+		{OldPath: "same.txt", NewPath: "same.txt", Kind: FileIdentical},
+		{OldPath: DevNull, NewPath: "dir/new.txt", Kind: FileText,
+			Patches: dmp.PatchMake("", "fresh\n")},
+		{OldPath: "img.bin", NewPath: "img.bin", Kind: FileBinary,
+			Binary: []byte{0, 1, 2, 255}},
+	}}
+
+	text := mp.String()
+	assert.Equal(t, "--- a/a.txt\n+++ b/a.txt\n@@ -3,10 +3,10 @@\n llo \n-world\n+there\n %0A\n"+
+		"--- /dev/null\n+++ b/dir/new.txt\n@@ -0,0 +1,6 @@\n+fresh%0A\n"+
+		"--- a/img.bin\n+++ b/img.bin\nBinary files a/img.bin and b/img.bin differ\nliteral 4\nAAEC/w==\n",
+		text, "")
+
+	parsed, err := MultiPatchFromText("diff --git a/a.txt b/a.txt\n" + text)
+	assert.Nil(t, err, "")
+	assert.Equal(t, 3, len(parsed.Files), "")
+	assert.Equal(t, text, parsed.String(), "Round trip.")
+	assert.Equal(t, DevNull, parsed.Files[1].OldPath, "")
+	assert.Equal(t, "dir/new.txt", parsed.Files[1].NewPath, "")
+	assert.Equal(t, []byte{0, 1, 2, 255}, parsed.Files[2].Binary, "")
+
+	// Deleted text that looks like a file header.
+	mp = &MultiPatch{Files: []*FilePatch{
+		{OldPath: "c.sql", NewPath: "c.sql", Kind: FileText,
+			Patches: dmp.PatchMake("select 1;\n-- a comment\nselect 2;\n",
+				"select 1;\nselect 2;\n")},
+		{OldPath: "d.txt", NewPath: "d.txt", Kind: FileText,
+			Patches: dmp.PatchMake("-- x", "+++ y")},
+	}}
+	text = mp.String()
+	parsed, err = MultiPatchFromText(text)
+	assert.Nil(t, err, "")
+	assert.Equal(t, 2, len(parsed.Files), "")
+	assert.Equal(t, text, parsed.String(), "Round trip.")
+
+	_, err = MultiPatchFromText("--- a/x\n@@ -1 +1 @@\n")
+	assert.NotNil(t, err, "Missing +++ header.")
+	_, err = MultiPatchFromText("--- a/x\n+++ b/x\nBinary files differ\nliteral 9\nAAEC/w==\n")
+	assert.NotNil(t, err, "Binary length mismatch.")
+}
+
+func TestApplyMultiPatch(t *testing.T) {
+	root, err := ioutil.TempDir("", "dmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	dmp := New()
+	writeTempFile(t, root, "a.txt", "hello world\n")
+	writeTempFile(t, root, "gone.txt", "bye\n")
+	writeTempFile(t, root, "b.txt", "something else entirely\n")
+	writeTempFile(t, root, "old.txt", "hello world\n")
+	writeTempFile(t, root, "same.txt", "unchanged\n")
+
+	mp := &MultiPatch{Files: []*FilePatch{
+		{OldPath: "a.txt", NewPath: "a.txt", Kind: FileText,
+			Patches: dmp.PatchMake("hello world\n", "hello there\n")},
+		{OldPath: DevNull, NewPath: "../dir/new.txt", Kind: FileText,
+			Patches: dmp.PatchMake("", "fresh\n")},
+		{OldPath: "gone.txt", NewPath: DevNull, Kind: FileText,
+			Patches: dmp.PatchMake("bye\n", "")},
+		{OldPath: "b.txt", NewPath: "b.txt", Kind: FileText,
+			Patches: dmp.PatchMake("The quick brown fox.", "The slow brown fox.")},
+		{OldPath: "old.txt", NewPath: "moved.txt", Kind: FileText,
+			Patches: dmp.PatchMake("hello world\n", "hello there\n")},
+		{OldPath: "same.txt", NewPath: "renamed.txt", Kind: FileIdentical},
+	}}
+	// Renames of identical files survive the text.
+	mp, err = MultiPatchFromText(mp.String())
+	assert.Nil(t, err, "")
+
+	results, err := dmp.ApplyMultiPatch(mp, root)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []FileResult{
+		{"a.txt", []bool{true}, true},
+		{"../dir/new.txt", []bool{true}, true},
+		{"gone.txt", []bool{true}, true},
+		{"b.txt", []bool{false}, false},
+		{"moved.txt", []bool{true}, true},
+		{"renamed.txt", []bool{}, true},
+	}, results, "")
+
+	b, _ := ioutil.ReadFile(filepath.Join(root, "a.txt"))
+	assert.Equal(t, "hello there\n", string(b), "")
+	b, _ = ioutil.ReadFile(filepath.Join(root, "dir", "new.txt"))
+	assert.Equal(t, "fresh\n", string(b), "Paths stay inside root.")
+	_, err = os.Stat(filepath.Join(root, "gone.txt"))
+	assert.True(t, os.IsNotExist(err), "")
+	b, _ = ioutil.ReadFile(filepath.Join(root, "b.txt"))
+	assert.Equal(t, "something else entirely\n", string(b), "Failed files are untouched.")
+	b, _ = ioutil.ReadFile(filepath.Join(root, "moved.txt"))
+	assert.Equal(t, "hello there\n", string(b), "")
+	b, _ = ioutil.ReadFile(filepath.Join(root, "renamed.txt"))
+	assert.Equal(t, "unchanged\n", string(b), "")
+	for _, name := range []string{"old.txt", "same.txt"} {
+		_, err = os.Stat(filepath.Join(root, name))
+		assert.True(t, os.IsNotExist(err), "Renamed from "+name)
+	}
+}
@@ -0,0 +1,97 @@
+package dmp
+
+import (
+	"sort"
+)
+
+// NWayRegion is a region of the base text and the versions that changed
+// it.  Regions of inserted text are empty ranges of the base at the point
+// of the insertion.
+type NWayRegion struct {
+	Range
+	// Indexes of the versions that deleted or replaced the region, or
+	// inserted text at an empty region; empty for unchanged regions.
+	Changed []int
+}
+
+// NWayDiff holds the diffs from a base text to several versions of it.
+type NWayDiff struct {
+	// Diffs from the base to each version.
+	Diffs [][]Diff
+	// Regions cover the base in order, cut wherever a version starts or
+	// stops changing it.
+	Regions []NWayRegion
+}
+
+// DiffN diffs base against each of versions and annotates each region of
+// the base with the versions that changed it, as views comparing several
+// revisions at once need.
+func (dmp *DMP) DiffN(base string, versions []string) NWayDiff {
+	var nd NWayDiff
+	// Deleted ranges and insertion points of each version.
+	deleted := make([][]Range, len(versions))
+	inserted := make([][]int, len(versions))
+	cuts := []int{0, len(base)}
+	for v, text := range versions {
+		diffs := dmp.DiffMain(base, text, true)
+		nd.Diffs = append(nd.Diffs, diffs)
+		pos := 0
+		for i, d := range diffs {
+			switch d.Type {
+			case DiffDelete:
+				deleted[v] = append(deleted[v], Range{pos, pos + len(d.Text)})
+				cuts = append(cuts, pos, pos+len(d.Text))
+				pos += len(d.Text)
+			case DiffInsert:
+				if i > 0 && diffs[i-1].Type == DiffDelete ||
+					i+1 < len(diffs) && diffs[i+1].Type == DiffDelete {
+					// Part of a replacement of the deleted text.
+					continue
+				}
+				inserted[v] = append(inserted[v], pos)
+				cuts = append(cuts, pos)
+			case DiffEqual:
+				pos += len(d.Text)
+			}
+		}
+	}
+	sort.Ints(cuts)
+
+	points := []int{}
+	for i, c := range cuts {
+		if i == 0 || c != cuts[i-1] {
+			points = append(points, c)
+		}
+	}
+	// segment returns the index of the region of the base starting at c.
+	segment := func(c int) int { return sort.SearchInts(points, c) }
+	segChanged := make([][]int, len(points))
+	insChanged := make([][]int, len(points))
+	for v := range versions {
+		for _, r := range deleted[v] {
+			for s := segment(r.Start); s < segment(r.End); s++ {
+				segChanged[s] = append(segChanged[s], v)
+			}
+		}
+		for _, p := range inserted[v] {
+			s := segment(p)
+			if n := len(insChanged[s]); n == 0 || insChanged[s][n-1] != v {
+				insChanged[s] = append(insChanged[s], v)
+			}
+		}
+	}
+
+	for s, p := range points {
+		if len(insChanged[s]) > 0 {
+			nd.Regions = append(nd.Regions, NWayRegion{
+				Range{p, p}, insChanged[s],
+			})
+		}
+		if s+1 < len(points) {
+			nd.Regions = append(nd.Regions, NWayRegion{
+				Range{p, points[s+1]}, segChanged[s],
+			})
+		}
+	}
+	return nd
+}
@@ -0,0 +1,41 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffN(t *testing.T) {
+	dmp := New()
+	base := "The quick brown fox."
+	nd := dmp.DiffN(base, []string{
+		"The slow brown fox.",
+		"The quick brown cat.",
+		"The quick brown fox!",
+		"The quick brown fox.",
+		"Yes. The quick brown fox.",
+	})
+	assert.Equal(t, 5, len(nd.Diffs), "")
+	assert.Equal(t, []Diff{{DiffEqual, base}}, nd.Diffs[3], "")
+	assert.Equal(t, []NWayRegion{
+		{Range{0, 0}, []int{4}},
+		{Range{0, 4}, nil},
+		{Range{4, 9}, []int{0}},
+		{Range{9, 16}, nil},
+		{Range{16, 19}, []int{1}},
+		{Range{19, 20}, []int{2}},
+	}, nd.Regions, "")
+
+	// Overlapping changes.
+	nd = dmp.DiffN("abcdef", []string{"aXf", "abYf"})
+	assert.Equal(t, []NWayRegion{
+		{Range{0, 1}, nil},
+		{Range{1, 2}, []int{0}},
+		{Range{2, 5}, []int{0, 1}},
+		{Range{5, 6}, nil},
+	}, nd.Regions, "")
+
+	nd = dmp.DiffN("", []string{"", "new"})
+	assert.Equal(t, []NWayRegion{{Range{0, 0}, []int{1}}}, nd.Regions, "")
+}
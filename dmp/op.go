@@ -6,4 +6,22 @@ const (
 	DiffDelete Operation = -1
 	DiffInsert Operation = 1
 	DiffEqual  Operation = 0
+
+	// DiffMoveFrom is text of text1 that DiffDetectMoves found moved
+	// elsewhere, and DiffMove the same text at its place in text2.  Like
+	// deletions and insertions, they count in text1 and text2 only.
+	DiffMoveFrom Operation = -2
+	DiffMove     Operation = 2
 )
+
+// edit returns the deletion or insertion that a move is, and other
+// operations as they are.
+func (op Operation) edit() Operation {
+	switch op {
+	case DiffMoveFrom:
+		return DiffDelete
+	case DiffMove:
+		return DiffInsert
+	}
+	return op
+}
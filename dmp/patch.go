@@ -14,29 +14,54 @@ type Patch struct {
 	start2  int
 	length1 int
 	length2 int
+
+	// Replica that made the patch and its version, for a PatchSequencer
+	// to order the patches of several replicas.  Both are optional.
+	Replica string
+	Version VersionVector
+}
+
+// NewPatch returns the patch applying diffs at start1 of the old text, and
+// start2 of the new one, with the lengths of the texts the diffs cover.
+// The patch must pass PatchValidate.
+func NewPatch(diffs []Diff, start1, start2 int) (Patch, error) {
+	diffs = withoutMoves(diffs)
+	p := Patch{
+		diffs:   append([]Diff{}, diffs...),
+		start1:  start1,
+		start2:  start2,
+		length1: len(DiffText1(diffs)),
+		length2: len(DiffText2(diffs)),
+	}
+	if err := PatchValidate([]Patch{p}); err != nil {
+		return Patch{}, err
+	}
+	return p, nil
 }
 
+// Diffs returns a copy of the diffs of p.
+func (p *Patch) Diffs() []Diff {
+	return append([]Diff{}, p.diffs...)
+}
+
+// Start1 returns the byte offset of p in the old text.
+func (p *Patch) Start1() int { return p.start1 }
+
+// Start2 returns the byte offset of p in the new text.
+func (p *Patch) Start2() int { return p.start2 }
+
+// Length1 returns the length in bytes of the old text p covers.
+func (p *Patch) Length1() int { return p.length1 }
+
+// Length2 returns the length in bytes of the new text p covers.
+func (p *Patch) Length2() int { return p.length2 }
+
 // String emulates GNU diff's format.
 // Header: @@ -382,8 +481,9 @@
 // Indicies are printed as 1-based, not 0-based.
 func (p *Patch) String() string {
-	var coords1, coords2 string
-
-	if p.length1 == 0 {
-		coords1 = strconv.Itoa(p.start1) + ",0"
-	} else if p.length1 == 1 {
-		coords1 = strconv.Itoa(p.start1 + 1)
-	} else {
-		coords1 = strconv.Itoa(p.start1+1) + "," + strconv.Itoa(p.length1)
-	}
-
-	if p.length2 == 0 {
-		coords2 = strconv.Itoa(p.start2) + ",0"
-	} else if p.length2 == 1 {
-		coords2 = strconv.Itoa(p.start2 + 1)
-	} else {
-		coords2 = strconv.Itoa(p.start2+1) + "," + strconv.Itoa(p.length2)
-	}
+	coords1 := patchCoords(p.start1, p.length1)
+	coords2 := patchCoords(p.start2, p.length2)
 
 	var text bytes.Buffer
 	text.WriteString("@@ -" + coords1 + " +" + coords2 + " @@\n")
@@ -52,11 +77,26 @@ func (p *Patch) String() string {
 			text.WriteString(" ")
 		}
 
-		text.WriteString(
-			strings.Replace(url.QueryEscape(aDiff.Text), "+", " ", -1),
-		)
+		text.WriteString(escapePatchText(aDiff.Text))
 		text.WriteString("\n")
 	}
 
-	return unescaper.Replace(text.String())
+	return text.String()
+}
+
+// escapePatchText encodes the text of a diff line of patch text.
+func escapePatchText(text string) string {
+	text = strings.Replace(url.QueryEscape(text), "+", " ", -1)
+	return unescaper.Replace(text)
+}
+
+// patchCoords formats the start and length of one side of a patch header.
+func patchCoords(start, length int) string {
+	switch length {
+	case 0:
+		return strconv.Itoa(start) + ",0"
+	case 1:
+		return strconv.Itoa(start + 1)
+	}
+	return strconv.Itoa(start+1) + "," + strconv.Itoa(length)
 }
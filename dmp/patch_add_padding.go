@@ -1,10 +1,29 @@
 package dmp
 
-func patchAddPadding(ps []Patch, npad int) string {
+// patchPadding returns the text that Apply puts around the target text:
+// PatchPadding, or PatchMargin control characters from \x01 upwards.
+func patchPadding(dmp *DMP) string {
+	if dmp.PatchPadding != "" {
+		return dmp.PatchPadding
+	}
 	ret := ""
-	for x := 1; x <= npad; x++ {
-		ret += string(x)
+	for x := 1; x <= dmp.PatchMargin; x++ {
+		ret += string(rune(x))
 	}
+	return ret
+}
+
+func patchAddPadding(ps []Patch, pad string) string {
+	patchAddPaddingEdges(ps, pad)
+	return pad
+}
+
+// patchAddPaddingEdges pads the patches like patchAddPadding, and also
+// returns on how many edges (0 to 2) the padding had to be added to the
+// patch context.
+func patchAddPaddingEdges(ps []Patch, ret string) (string, int) {
+	edges := 0
+	npad := len(ret)
 
 	// Bump all the ps forward.
 	for i := range ps {
@@ -20,6 +39,7 @@ func patchAddPadding(ps []Patch, npad int) string {
 		p.start2 -= npad // Should be 0.
 		p.length1 += npad
 		p.length2 += npad
+		edges++
 	} else if npad > len(p.diffs[0].Text) {
 		// Grow first equality.
 		extraLength := npad - len(p.diffs[0].Text)
@@ -28,6 +48,7 @@ func patchAddPadding(ps []Patch, npad int) string {
 		p.start2 -= extraLength
 		p.length1 += extraLength
 		p.length2 += extraLength
+		edges++
 	}
 
 	// Add some padding on end of last diff.
@@ -37,6 +58,7 @@ func patchAddPadding(ps []Patch, npad int) string {
 		last.diffs = append(last.diffs, Diff{DiffEqual, ret})
 		last.length1 += npad
 		last.length2 += npad
+		edges++
 	} else if npad > len(last.diffs[len(last.diffs)-1].Text) {
 		// Grow last equality.
 		lastDiff := last.diffs[len(last.diffs)-1]
@@ -44,7 +66,8 @@ func patchAddPadding(ps []Patch, npad int) string {
 		last.diffs[len(last.diffs)-1].Text += ret[:extraLength]
 		last.length1 += extraLength
 		last.length2 += extraLength
+		edges++
 	}
 
-	return ret
+	return ret, edges
 }
@@ -0,0 +1,303 @@
+package dmp
+
+import (
+	"bytes"
+	"math"
+	"strings"
+)
+
+// PatchResult reports the outcome of applying one patch.
+type PatchResult struct {
+	// Applied is true if the patch was applied.
+	Applied bool
+
+	// Confidence ranges from 0 (not applied) to 1 (exact text found at the
+	// expected location).  It is derived from the match score: the
+	// Levenshtein distance of the matched window relative to the patch
+	// text, plus the distance from the expected location relative to
+	// MatchDistance.
+	Confidence float64
+
+	// Blocked is true if the patch was found but skipped because it would
+	// have modified a protected range.
+	Blocked bool
+
+	// Ambiguous is true if the text of the patch occurs more than once in
+	// the target, in which case PatchAmbiguity decided where to apply it.
+	// Texts are only searched for copies, and so only reported ambiguous,
+	// if PatchAmbiguity is not AmbiguityBestScore.
+	Ambiguous bool
+
+	// Offset is where the text of the patch was found, in bytes of the
+	// text as patched by the patches before it, or -1 if it was not found.
+	Offset int
+
+	// Fuzz is the Levenshtein distance between the text the patch expected
+	// and the text found at Offset: 0 for an exact match.
+	Fuzz int
+
+	// Matched is the text found at Offset, which the patch replaced if it
+	// was applied.
+	Matched string
+
+	// IndentFrom and IndentTo tell how PatchAdaptIndent re-indented the
+	// patch: the leading IndentFrom of its lines was replaced with
+	// IndentTo.  Both are empty if the patch was not re-indented.
+	IndentFrom string
+	IndentTo   string
+}
+
+// ApplyStats describes how much help a set of patches needed to apply.
+type ApplyStats struct {
+	// PaddedEdges counts the text edges (0 to 2) where the null padding
+	// had to be added to the patch context.
+	PaddedEdges int
+
+	// SplitPatches counts the patches that were longer than MatchMaxBits
+	// and had to be split.
+	SplitPatches int
+
+	// Drift holds, for each applied patch, how far the location where it
+	// was found is from the expected location.  It is 0 for patches that
+	// were not applied.
+	Drift []int
+
+	// Padding is the text that was added around the target text while
+	// applying, see PatchPadding.
+	Padding string
+}
+
+// patchConfidence converts a match with e errors found at loc for a patch
+// expected at expected into a confidence between 0 and 1.
+func patchConfidence(dmp *DMP, e, loc, expected int, text string) float64 {
+	if len(text) == 0 {
+		// Nothing to compare against; only the location counts.
+		text = " "
+	}
+	score := matchBitapScore(dmp, e, loc, expected, text)
+	return math.Max(0, 1-score)
+}
+
+// ApplyOptions are the settings of one call of ApplyWithOptions.
+type ApplyOptions struct {
+	// MaxFuzz is the largest Fuzz of a patch that is applied.  Zero or
+	// less leaves it to MatchThreshold and PatchDeleteThreshold, as Apply
+	// does.
+	MaxFuzz int
+
+	// ExactContext only applies patches whose text is found exactly,
+	// though it may be away from where it was expected.
+	ExactContext bool
+
+	// DryRun returns the text as given, with the results the patches
+	// would have if applied.
+	DryRun bool
+
+	// StopOnFirstFailure leaves the patches after the first one not
+	// applied alone, as not applied.
+	StopOnFirstFailure bool
+}
+
+// applyOptions holds the per-call settings of patchApply.
+type applyOptions struct {
+	// Options of ApplyWithOptions; limitFuzz is set if MaxFuzz applies,
+	// and then holds 0 for ExactContext.
+	ApplyOptions
+	limitFuzz bool
+
+	// Ranges of the target text that patches may not modify.
+	protected []Range
+
+	// The patches are already padded and split, and the target text is
+	// not to be padded.
+	prepared bool
+}
+
+func patchApply(dmp *DMP, ps []Patch, s string, opts applyOptions) (
+	string, []PatchResult, ApplyStats,
+) {
+	var stats ApplyStats
+	if len(ps) == 0 {
+		return s, []PatchResult{}, stats
+	}
+
+	// Deep copy the patches so that no changes are made to originals.
+	ps = PatchDeepCopy(ps)
+
+	nullPadding := ""
+	if !opts.prepared {
+		var edges int
+		nullPadding, edges = patchAddPaddingEdges(ps, patchPadding(dmp))
+		stats.PaddedEdges = edges
+		stats.Padding = nullPadding
+		s = nullPadding + s + nullPadding
+		opts.protected = shiftRanges(opts.protected, len(nullPadding))
+		for _, p := range ps {
+			if p.length1 > dmp.MatchMaxBits {
+				stats.SplitPatches++
+			}
+		}
+		ps = patchSplitMax(ps, dmp.MatchMaxBits, dmp.PatchMargin)
+	}
+
+	x := 0
+	// delta keeps track of the offset between the expected and actual
+	// location of the previous patch.  If there are patches expected at
+	// positions 10 and 20, but the first patch was found at 12, delta is 2
+	// and the second patch has an effective expected position of 22.
+	delta := 0
+	results := make([]PatchResult, len(ps))
+	for i := range results {
+		results[i].Offset = -1
+	}
+	stats.Drift = make([]int, len(ps))
+	var buf bytes.Buffer
+	for _, p := range ps {
+		if isDone(dmp.done) {
+			break
+		}
+		if opts.StopOnFirstFailure && x > 0 && !results[x-1].Applied {
+			break
+		}
+		expected_loc := p.start2 + delta
+		buf.Reset()
+		DiffText1To(&buf, p.diffs)
+		text1 := buf.String()
+		if dmp.PatchAdaptIndent && !strings.Contains(s, text1) {
+			q, from, to, ok := adaptIndent(dmp, p, text1, s, expected_loc)
+			if ok {
+				p, text1 = q, DiffText1(q.diffs)
+				results[x].IndentFrom, results[x].IndentTo = from, to
+			}
+		}
+		var startLoc int
+		endLoc := -1
+		// Scanning the whole text for copies is only worth it if the
+		// policy acts on them; an empty text is found anywhere.
+		ambiguous := dmp.PatchAmbiguity != AmbiguityBestScore &&
+			text1 != "" &&
+			strings.Index(s, text1) != strings.LastIndex(s, text1)
+		results[x].Ambiguous = ambiguous
+		if ambiguous {
+			// The patch text occurs several times; let the policy pick.
+			startLoc = ambiguousMatch(
+				dmp.PatchAmbiguity, s, text1, expected_loc,
+			)
+		} else if len(text1) > dmp.MatchMaxBits {
+			// PatchSplitMax will only provide an oversized pattern
+			// in the case of a monster delete.
+			startLoc = dmp.MatchMain(
+				s, text1[:dmp.MatchMaxBits], expected_loc,
+			)
+			if startLoc != -1 {
+				endLoc = dmp.MatchMain(
+					s, text1[len(text1)-dmp.MatchMaxBits:],
+					expected_loc+len(text1)-dmp.MatchMaxBits,
+				)
+				if endLoc == -1 || startLoc >= endLoc {
+					// Can't find valid trailing context.  Drop this patch.
+					startLoc = -1
+				}
+			}
+		} else {
+			startLoc = dmp.MatchMain(s, text1, expected_loc)
+		}
+		if startLoc == -1 {
+			// No match found.  :(
+			results[x].Applied = false
+			// Subtract the delta for this failed patch from subsequent
+			// patches.
+			delta -= p.length2 - p.length1
+		} else {
+			// Found a match.  :)
+			results[x].Applied = true
+			delta = startLoc - expected_loc
+			stats.Drift[x] = delta
+			end := startLoc + len(text1)
+			if endLoc != -1 {
+				end = endLoc + dmp.MatchMaxBits
+			}
+			text2 := s[startLoc:min(end, len(s))]
+			results[x].Offset = max(0, startLoc-len(nullPadding))
+			// Leave out the padding.
+			lo := min(max(startLoc, len(nullPadding)), len(s))
+			hi := min(startLoc+len(text2), len(s)-len(nullPadding))
+			results[x].Matched = s[lo:max(lo, hi)]
+			ed := newTextEditor(s, opts.protected)
+			if text1 == text2 {
+				// Perfect match, just shove the Replacement text in.
+				if len(opts.protected) == 0 {
+					buf.Reset()
+					DiffText2To(&buf, p.diffs)
+					ed.replace(startLoc, startLoc+len(text1), buf.String())
+				} else {
+					ed.replaceDiffs(startLoc, p.diffs)
+				}
+				results[x].Confidence = patchConfidence(
+					dmp, 0, startLoc, expected_loc, text1,
+				)
+			} else {
+				// Imperfect match.  Run a diff to get a framework of
+				// equivalent indices.
+				diffs := dmp.exact().DiffMain(text1, text2, false)
+				results[x].Fuzz = DiffLevenshtein(diffs)
+				if len(text1) > dmp.MatchMaxBits &&
+					float64(DiffLevenshtein(diffs))/float64(len(text1)) >
+						dmp.PatchDeleteThreshold {
+					// The end points match, but the content is unacceptably
+					// bad.
+					results[x].Applied = false
+					stats.Drift[x] = 0
+				} else if opts.limitFuzz && results[x].Fuzz > opts.MaxFuzz {
+					// Not as close a match as the caller wants.
+					results[x].Applied = false
+					stats.Drift[x] = 0
+				} else {
+					results[x].Confidence = patchConfidence(
+						dmp, DiffLevenshtein(diffs),
+						startLoc, expected_loc, text1,
+					)
+					diffs = diffCleanupSemanticLossless(diffs, nil)
+					index1 := 0
+					for _, d := range p.diffs {
+						if d.Type != DiffEqual {
+							index2 := DiffXIndex(diffs, index1)
+							if d.Type == DiffInsert {
+								// Insertion
+								ed.replace(startLoc+index2,
+									startLoc+index2, d.Text)
+							} else if d.Type == DiffDelete {
+								// Deletion
+								ed.replace(startLoc+index2,
+									startLoc+DiffXIndex(
+										diffs,
+										index1+len(d.Text),
+									), "")
+							}
+						}
+						if d.Type != DiffDelete {
+							index1 += len(d.Text)
+						}
+					}
+				}
+			}
+			if ed.blocked || ed.failed {
+				// The patch would modify a protected range, or, if it is
+				// malformed, text outside s.  Skip it like a failed patch.
+				results[x].Applied = false
+				results[x].Confidence = 0
+				results[x].Blocked = ed.blocked
+				stats.Drift[x] = 0
+				delta -= p.length2 - p.length1
+			} else {
+				s = ed.text
+				opts.protected = ed.protected
+			}
+		}
+		x++
+	}
+	// Strip the padding off.  Malformed patches may have cut into it.
+	lo := min(len(nullPadding), len(s))
+	s = s[lo:max(lo, len(s)-len(nullPadding))]
+	return s, results, stats
+}
@@ -0,0 +1,171 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestApplyDetailed(t *testing.T) {
+	dmp := New()
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.",
+		"That quick brown fox jumped over a lazy dog.")
+
+	// Exact match at the expected location.
+	s, results := dmp.ApplyDetailed(patches, "The quick brown fox jumps over the lazy dog.")
+	assert.Equal(t, "That quick brown fox jumped over a lazy dog.", s, "")
+	for _, r := range results {
+		assert.True(t, r.Applied, "")
+		assert.Equal(t, 1.0, r.Confidence, "")
+	}
+
+	// Partial match.
+	s, results = dmp.ApplyDetailed(patches, "The quick red rabbit jumps over the tired tiger.")
+	assert.Equal(t, "That quick red rabbit jumped over a tired tiger.", s, "")
+	for _, r := range results {
+		assert.True(t, r.Applied, "")
+		assert.True(t, r.Confidence > 0 && r.Confidence < 1, "")
+	}
+
+	// Failed match.
+	_, results = dmp.ApplyDetailed(patches, "I am the very model of a modern major general.")
+	for _, r := range results {
+		assert.False(t, r.Applied, "")
+		assert.Equal(t, 0.0, r.Confidence, "")
+	}
+
+	// Shifted text loses confidence for the drift and the lost padding.
+	shifted := "Prefix text. The quick brown fox jumps over the lazy dog."
+	s, results = dmp.ApplyDetailed(patches, shifted)
+	assert.Equal(t, "Prefix text. That quick brown fox jumped over a lazy dog.", s, "")
+	assert.True(t, results[0].Confidence < 1 && results[0].Confidence > 0.5, "")
+	assert.Equal(t, 1.0, results[1].Confidence, "")
+
+	// Null case.
+	s, results = dmp.ApplyDetailed(nil, "Hello world.")
+	assert.Equal(t, "Hello world.", s, "")
+	assert.Equal(t, 0, len(results), "")
+}
+
+func TestApplyDetailedReport(t *testing.T) {
+	dmp := New()
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.",
+		"That quick brown fox jumped over a lazy dog.")
+
+	// Offsets are in the text patched by the previous patches.
+	_, results := dmp.ApplyDetailed(patches, "The quick brown fox jumps over the lazy dog.")
+	assert.Equal(t, 0, results[0].Offset, "")
+	assert.Equal(t, 0, results[0].Fuzz, "")
+	assert.Equal(t, "The quick b", results[0].Matched, "Padding left out.")
+	assert.Equal(t, 21, results[1].Offset, "")
+	assert.Equal(t, "jumps over the laz", results[1].Matched, "")
+
+	_, results = dmp.ApplyDetailed(patches, "The quick red rabbit jumps over the tired tiger.")
+	assert.Equal(t, 22, results[1].Offset, "")
+	assert.Equal(t, 3, results[1].Fuzz, "")
+	assert.Equal(t, "jumps over the tir", results[1].Matched, "")
+
+	_, results = dmp.ApplyDetailed(patches, "I am the very model of a modern major general.")
+	assert.Equal(t, PatchResult{Offset: -1}, results[0], "")
+}
+
+func TestApplyWithStats(t *testing.T) {
+	dmp := New()
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.",
+		"That quick brown fox jumped over a lazy dog.")
+
+	_, _, stats := dmp.ApplyWithStats(patches, "The quick brown fox jumps over the lazy dog.")
+	assert.Equal(t, ApplyStats{1, 0, []int{0, 0}, "\x01\x02\x03\x04"}, stats, "Padding on the leading edge only.")
+
+	_, _, stats = dmp.ApplyWithStats(patches, "Some prefix. The quick brown fox jumps over the lazy dog.")
+	assert.Equal(t, 2, len(stats.Drift), "")
+	assert.True(t, stats.Drift[0] > 0, "First patch found later than expected.")
+	assert.Equal(t, 0, stats.Drift[1], "Second patch follows the first.")
+
+	patches = dmp.PatchMake("x1234567890123456789012345678901234567890123456789012345678901234567890y", "xabcy")
+	text, results, stats := dmp.ApplyWithStats(patches,
+		"x123456789012345678901234567890-----++++++++++-----123456789012345678901234567890y")
+	assert.Equal(t, "xabcy", text, "")
+	assert.Equal(t, 2, len(results), "")
+	assert.Equal(t, 2, stats.PaddedEdges, "")
+	assert.Equal(t, 1, stats.SplitPatches, "")
+
+	_, _, stats = dmp.ApplyWithStats(nil, "text")
+	assert.Equal(t, ApplyStats{}, stats, "Null case.")
+}
+
+func TestPatchPadding(t *testing.T) {
+	dmp := New()
+	dmp.PatchPadding = "@@@@@@"
+	patches := dmp.PatchMake("", "test")
+	padding := dmp.PatchAddPadding(patches)
+	assert.Equal(t, "@@@@@@", padding, "")
+	assert.Equal(t, "@@ -1,12 +1,16 @@\n @@@@@@\n+test\n @@@@@@\n", PatchToText(patches), "")
+
+	patches = dmp.PatchMake("The quick brown fox.", "The slow brown fox.")
+	s, _, stats := dmp.ApplyWithStats(patches, "The quick brown fox.")
+	assert.Equal(t, "The slow brown fox.", s, "")
+	assert.Equal(t, "@@@@@@", stats.Padding, "")
+	assert.Equal(t, 2, stats.PaddedEdges, "")
+}
+
+func TestApplyWithOptions(t *testing.T) {
+	dmp := New()
+	patches := dmp.PatchMake("The quick brown fox jumps over the lazy dog.",
+		"That quick brown fox jumped over a lazy dog.")
+	fuzzy := "The quick red rabbit jumps over the tired tiger."
+
+	// No limit, as Apply.
+	s, results := dmp.ApplyWithOptions(patches, fuzzy, ApplyOptions{})
+	want, wantResults := dmp.ApplyDetailed(patches, fuzzy)
+	assert.Equal(t, want, s, "")
+	assert.Equal(t, wantResults, results, "")
+	assert.True(t, results[0].Applied && results[1].Applied, "")
+
+	// A limit above the fuzz of the patches.
+	s, _ = dmp.ApplyWithOptions(patches, fuzzy, ApplyOptions{MaxFuzz: 100})
+	assert.Equal(t, want, s, "")
+	s, results = dmp.ApplyWithOptions(patches, fuzzy, ApplyOptions{MaxFuzz: 1})
+	assert.Equal(t, "That quick red rabbit jumps over the tired tiger.", s, "")
+	assert.Equal(t, 1, results[0].Fuzz, "")
+	assert.False(t, results[1].Applied, "")
+
+	// Exact context only.
+	s, results = dmp.ApplyWithOptions(patches,
+		"The quick brown fox jumps over the tired tiger.",
+		ApplyOptions{ExactContext: true})
+	assert.Equal(t, "That quick brown fox jumps over the tired tiger.", s, "")
+	assert.True(t, results[0].Applied, "")
+	assert.False(t, results[1].Applied, "")
+	assert.True(t, results[1].Fuzz > 0, "")
+
+	// Exact text found away from where it was expected.  The first patch
+	// expects the start of the text.
+	s, results = dmp.ApplyWithOptions(patches,
+		"Prefix. The quick brown fox jumps over the lazy dog.",
+		ApplyOptions{ExactContext: true})
+	assert.Equal(t, "Prefix. The quick brown fox jumped over a lazy dog.", s, "")
+	assert.False(t, results[0].Applied, "")
+	assert.Equal(t, 0, results[1].Fuzz, "")
+
+	// Dry run.
+	s, results = dmp.ApplyWithOptions(patches, fuzzy,
+		ApplyOptions{DryRun: true})
+	assert.Equal(t, fuzzy, s, "")
+	assert.Equal(t, wantResults, results, "")
+
+	// Stop on the first failure.
+	swapped := "The quick brown fox jumps over the lazy dog."
+	patches = dmp.PatchMake(swapped, "The quick brown fox jumps over the lazy cat.")
+	patches = append(dmp.PatchMake("Nothing like it.", "Nothing like that."),
+		patches...)
+	s, results = dmp.ApplyWithOptions(patches, swapped,
+		ApplyOptions{StopOnFirstFailure: true})
+	assert.Equal(t, swapped, s, "")
+	assert.Equal(t, 2, len(results), "")
+	assert.False(t, results[0].Applied || results[1].Applied, "")
+	assert.Equal(t, -1, results[1].Offset, "")
+	s, results = dmp.ApplyWithOptions(patches, swapped, ApplyOptions{})
+	assert.Equal(t, "The quick brown fox jumps over the lazy cat.", s, "")
+	assert.True(t, results[1].Applied, "")
+}
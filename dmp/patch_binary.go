@@ -0,0 +1,176 @@
+package dmp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// patchBinaryFormat is the first byte of the binary encoding of a Patch,
+// to tell later versions of the encoding apart.
+const patchBinaryFormat = 1
+
+// MarshalBinary encodes d as the byte of its operation followed by its
+// text.
+func (d Diff) MarshalBinary() ([]byte, error) {
+	return append([]byte{byte(d.Type.edit())}, d.Text...), nil
+}
+
+// UnmarshalBinary decodes the output of MarshalBinary.
+func (d *Diff) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("Empty binary diff")
+	}
+	op := Operation(int8(data[0]))
+	if op < DiffDelete || op > DiffInsert {
+		return fmt.Errorf("Invalid diff operation: %d", op)
+	}
+	*d = Diff{op, string(data[1:])}
+	return nil
+}
+
+// MarshalBinary encodes p with all of its fields, for caches and RPCs,
+// and for encoding/gob, which uses it.  Unlike PatchToText, it keeps the
+// starts and lengths as they are, and the replica and version.  Numbers
+// are uvarints, and texts are prefixed with their length in bytes.
+func (p Patch) MarshalBinary() ([]byte, error) {
+	data := []byte{patchBinaryFormat}
+	for _, n := range []int{p.start1, p.start2, p.length1, p.length2} {
+		data = binary.AppendUvarint(data, uint64(n))
+	}
+	data = binary.AppendUvarint(data, uint64(len(p.diffs)))
+	for _, d := range p.diffs {
+		data = append(data, byte(d.Type))
+		data = appendBinaryString(data, d.Text)
+	}
+	data = appendBinaryString(data, p.Replica)
+
+	// Sort the replicas, so that equal patches encode the same.
+	replicas := make([]string, 0, len(p.Version))
+	for r := range p.Version {
+		replicas = append(replicas, r)
+	}
+	sort.Strings(replicas)
+	data = binary.AppendUvarint(data, uint64(len(replicas)))
+	for _, r := range replicas {
+		data = appendBinaryString(data, r)
+		data = binary.AppendUvarint(data, p.Version[r])
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes the output of MarshalBinary.  The lengths must
+// match the diffs.
+func (p *Patch) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != patchBinaryFormat {
+		return fmt.Errorf("Unknown binary patch format")
+	}
+	br := &binaryReader{data: data[1:]}
+	var q Patch
+	q.start1 = br.int()
+	q.start2 = br.int()
+	q.length1 = br.int()
+	q.length2 = br.int()
+	n := br.int()
+	for i := 0; i < n && br.err == nil; i++ {
+		op := br.op()
+		q.diffs = append(q.diffs, Diff{op, br.string()})
+	}
+	q.Replica = br.string()
+	if n := br.int(); n > 0 {
+		q.Version = VersionVector{}
+		for i := 0; i < n && br.err == nil; i++ {
+			r := br.string()
+			q.Version[r] = br.uvarint()
+		}
+	}
+	if br.err == nil && len(br.data) > 0 {
+		br.err = fmt.Errorf("Trailing data after binary patch")
+	}
+	if br.err != nil {
+		return br.err
+	}
+
+	length1 := len(DiffText1(q.diffs))
+	length2 := len(DiffText2(q.diffs))
+	if q.length1 != length1 || q.length2 != length2 {
+		return fmt.Errorf(
+			"Patch length mismatch: header -%d +%d, diffs -%d +%d",
+			q.length1, q.length2, length1, length2,
+		)
+	}
+	*p = q
+	return nil
+}
+
+func appendBinaryString(data []byte, s string) []byte {
+	return append(binary.AppendUvarint(data, uint64(len(s))), s...)
+}
+
+// binaryReader decodes the fields of a binary patch.  After the first
+// error, its methods return zeros and err is kept.
+type binaryReader struct {
+	data []byte
+	err  error
+}
+
+func (br *binaryReader) uvarint() uint64 {
+	if br.err != nil {
+		return 0
+	}
+	n, k := binary.Uvarint(br.data)
+	if k <= 0 {
+		br.err = fmt.Errorf("Truncated binary patch")
+		return 0
+	}
+	br.data = br.data[k:]
+	return n
+}
+
+// int reads a uvarint that must fit in an int.
+func (br *binaryReader) int() int {
+	n := br.uvarint()
+	if n > math.MaxInt {
+		br.err = fmt.Errorf("Number too large in binary patch: %d", n)
+		return 0
+	}
+	return int(n)
+}
+
+func (br *binaryReader) byte() byte {
+	if br.err != nil {
+		return 0
+	}
+	if len(br.data) == 0 {
+		br.err = fmt.Errorf("Truncated binary patch")
+		return 0
+	}
+	b := br.data[0]
+	br.data = br.data[1:]
+	return b
+}
+
+// op reads the byte of a diff operation.
+func (br *binaryReader) op() Operation {
+	op := Operation(int8(br.byte()))
+	if br.err == nil && (op < DiffDelete || op > DiffInsert) {
+		br.err = fmt.Errorf("Invalid diff operation in binary patch: %d", op)
+		return 0
+	}
+	return op
+}
+
+func (br *binaryReader) string() string {
+	n := br.uvarint()
+	if br.err != nil {
+		return ""
+	}
+	if n > uint64(len(br.data)) {
+		br.err = fmt.Errorf("Truncated binary patch")
+		return ""
+	}
+	s := string(br.data[:n])
+	br.data = br.data[n:]
+	return s
+}
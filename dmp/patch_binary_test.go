@@ -0,0 +1,73 @@
+package dmp
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffBinary(t *testing.T) {
+	for _, d := range []Diff{
+		{DiffDelete, "abc"}, {DiffInsert, "\x00\xff"}, {DiffEqual, ""},
+	} {
+		data, err := d.MarshalBinary()
+		assert.Nil(t, err, "")
+		var got Diff
+		assert.Nil(t, got.UnmarshalBinary(data), "")
+		assert.Equal(t, d, got, "")
+	}
+	data, _ := Diff{DiffDelete, "abc"}.MarshalBinary()
+	assert.Equal(t, "\xffabc", string(data), "")
+
+	var d Diff
+	assert.NotNil(t, d.UnmarshalBinary(nil), "")
+	assert.NotNil(t, d.UnmarshalBinary([]byte("\x02abc")), "")
+	assert.Equal(t, Diff{}, d, "")
+}
+
+func TestPatchBinary(t *testing.T) {
+	dmp := New()
+	ps := dmp.PatchMake("The quick brown fox jumps over the lazy dog.",
+		"That quick brown fox jumped over a lazy dog.")
+	ps[0].Replica = "a"
+	ps[0].Version = VersionVector{"a": 3, "b": 300}
+	for _, p := range ps {
+		data, err := p.MarshalBinary()
+		assert.Nil(t, err, "")
+		var got Patch
+		assert.Nil(t, got.UnmarshalBinary(data), "")
+		assert.Equal(t, p, got, "")
+	}
+
+	// Starts and lengths are kept as they are.
+	data, _ := ps[1].MarshalBinary()
+	assert.Equal(t,
+		"\x01\x15\x15\x12\x11\x07\x00\x04jump\xff\x01s\x01\x02ed"+
+			"\x00\x06 over \xff\x03the\x01\x01a\x00\x04 laz\x00\x00",
+		string(data), "")
+
+	// Through gob.
+	var buf bytes.Buffer
+	assert.Nil(t, gob.NewEncoder(&buf).Encode(ps), "")
+	var got []Patch
+	assert.Nil(t, gob.NewDecoder(&buf).Decode(&got), "")
+	assert.Equal(t, ps, got, "")
+
+	// Errors.
+	var p Patch
+	assert.NotNil(t, p.UnmarshalBinary(nil), "Empty.")
+	assert.NotNil(t, p.UnmarshalBinary([]byte{2}), "Unknown format.")
+	for i := 1; i < len(data); i++ {
+		assert.NotNil(t, p.UnmarshalBinary(data[:i]), "Truncated.")
+	}
+	assert.NotNil(t, p.UnmarshalBinary(append(data, 0)), "Trailing data.")
+	bad := append([]byte{}, data...)
+	bad[3] = 0x13
+	assert.NotNil(t, p.UnmarshalBinary(bad), "Length mismatch.")
+	bad = append([]byte{}, data...)
+	bad[6] = 0x05
+	assert.NotNil(t, p.UnmarshalBinary(bad), "Invalid operation.")
+	assert.Equal(t, Patch{}, p, "")
+}
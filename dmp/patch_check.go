@@ -0,0 +1,51 @@
+package dmp
+
+// HunkState tells how a patch would apply to a text.
+type HunkState int8
+
+const (
+	// HunkClean patches are found exactly where they are expected.
+	HunkClean HunkState = iota
+	// HunkFuzzy patches apply, but their text is found away from where it
+	// is expected, or differs from the text found.
+	HunkFuzzy
+	// HunkFailed patches do not apply.
+	HunkFailed
+)
+
+// HunkStatus reports how one patch would apply, see PatchCheck.
+type HunkStatus struct {
+	State HunkState
+	// Offset is where the text of the patch is found, as for PatchResult,
+	// or -1.
+	Offset int
+	// Drift is how far Offset is from where the patch is expected, and
+	// Fuzz the Levenshtein distance between the text of the patch and the
+	// text found.  Both are 0 for failed patches.
+	Drift int
+	Fuzz  int
+}
+
+// PatchCheck reports how each patch would apply to s, without applying
+// them: each is checked against s with the patches before it applied, as
+// Apply would.  As with Apply, there is one status per patch split by
+// PatchSplitMax.
+func (dmp *DMP) PatchCheck(ps []Patch, s string) []HunkStatus {
+	_, results, stats := patchApply(dmp, ps, s, applyOptions{})
+	status := make([]HunkStatus, len(results))
+	for i, r := range results {
+		switch {
+		case !r.Applied:
+			status[i] = HunkStatus{State: HunkFailed, Offset: r.Offset}
+			continue
+		case r.Fuzz == 0 && stats.Drift[i] == 0:
+			status[i].State = HunkClean
+		default:
+			status[i].State = HunkFuzzy
+		}
+		status[i].Offset = r.Offset
+		status[i].Drift = stats.Drift[i]
+		status[i].Fuzz = r.Fuzz
+	}
+	return status
+}
@@ -0,0 +1,40 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchCheck(t *testing.T) {
+	dmp := New()
+	text1 := "The quick brown fox jumps over the lazy dog."
+	patches := dmp.PatchMake(text1,
+		"That quick brown fox jumped over a lazy dog.")
+
+	assert.Equal(t, []HunkStatus{
+		{HunkClean, 0, 0, 0},
+		{HunkClean, 21, 0, 0},
+	}, dmp.PatchCheck(patches, text1), "")
+
+	// The drift of the first patch carries over to the second.
+	assert.Equal(t, []HunkStatus{
+		{HunkFuzzy, 6, 8, 2},
+		{HunkClean, 29, 0, 0},
+	}, dmp.PatchCheck(patches,
+		"Prefix. The quick brown fox jumps over the lazy dog."), "")
+	status := dmp.PatchCheck(patches,
+		"The quick brown fox jumps over the tired tiger.")
+	assert.Equal(t, HunkClean, status[0].State, "")
+	assert.Equal(t, HunkFuzzy, status[1].State, "")
+	assert.True(t, status[1].Fuzz > 0, "")
+
+	status = dmp.PatchCheck(patches, "Something else entirely.")
+	assert.Equal(t, []HunkStatus{
+		{HunkFailed, -1, 0, 0},
+		{HunkFailed, -1, 0, 0},
+	}, status, "")
+
+	// Null case.
+	assert.Equal(t, []HunkStatus{}, dmp.PatchCheck(nil, text1), "")
+}
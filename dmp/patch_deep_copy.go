@@ -7,6 +7,7 @@ func PatchDeepCopy(patches []Patch) []Patch {
 	for _, p := range patches {
 		cp := p
 		cp.diffs = append([]Diff{}, p.diffs...)
+		cp.Version = p.Version.Copy()
 		ret = append(ret, cp)
 	}
 	return ret
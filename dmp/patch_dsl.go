@@ -0,0 +1,192 @@
+package dmp
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PatchToDSL writes patches in a compact notation meant for tests and
+// scripts, one patch per line:
+//
+//	@12 ='The ' -'quick' +'slow' =' fox'
+//
+// The number after @ is the start of the patch, followed by ",N" if the
+// start in the patched text differs.  Each diff is written as its sign
+// ('=' for equalities) and its quoted text.  Quotes and backslashes are
+// escaped with a backslash, as are newlines (\n), tabs (\t), carriage
+// returns (\r) and other control bytes (\xNN).
+func PatchToDSL(ps []Patch) string {
+	var buf bytes.Buffer
+	for _, p := range ps {
+		buf.WriteString("@" + strconv.Itoa(p.start1))
+		if p.start2 != p.start1 {
+			buf.WriteString("," + strconv.Itoa(p.start2))
+		}
+		for _, d := range p.diffs {
+			switch d.Type {
+			case DiffEqual:
+				buf.WriteString(" =")
+			case DiffDelete:
+				buf.WriteString(" -")
+			case DiffInsert:
+				buf.WriteString(" +")
+			}
+			buf.WriteString(dslQuote(d.Text))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// PatchFromDSL parses the notation of PatchToDSL.  Patches may also be
+// separated by ";" instead of newlines, and the lengths are computed from
+// the diffs.
+func PatchFromDSL(s string) ([]Patch, error) {
+	ps := []Patch{}
+	s = strings.TrimSpace(s)
+	for len(s) > 0 {
+		p, rest, err := parseDSLPatch(s)
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, p)
+		s = strings.TrimLeft(rest, " \t\r\n;")
+	}
+	return ps, nil
+}
+
+// MustPatchFromDSL is like PatchFromDSL but panics on errors.  It is meant
+// for patch literals in tests.
+func MustPatchFromDSL(s string) []Patch {
+	ps, err := PatchFromDSL(s)
+	if err != nil {
+		panic(err)
+	}
+	return ps
+}
+
+// parseDSLPatch parses one patch at the start of s and returns the rest.
+func parseDSLPatch(s string) (Patch, string, error) {
+	var p Patch
+	if s[0] != '@' {
+		return p, s, fmt.Errorf("Invalid patch: %q", s)
+	}
+	end := strings.IndexAny(s, " \t\r\n;")
+	if end == -1 {
+		end = len(s)
+	}
+	starts := strings.SplitN(s[1:end], ",", 2)
+	var err error
+	if p.start1, err = strconv.Atoi(starts[0]); err != nil {
+		return p, s, fmt.Errorf("Invalid patch start: %q", s[:end])
+	}
+	p.start2 = p.start1
+	if len(starts) == 2 {
+		if p.start2, err = strconv.Atoi(starts[1]); err != nil {
+			return p, s, fmt.Errorf("Invalid patch start: %q", s[:end])
+		}
+	}
+	s = s[end:]
+
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if len(s) == 0 || strings.IndexByte("\r\n;", s[0]) != -1 {
+			return p, s, nil
+		}
+		var op Operation
+		switch s[0] {
+		case '=':
+			op = DiffEqual
+		case '-':
+			op = DiffDelete
+		case '+':
+			op = DiffInsert
+		default:
+			return p, s, fmt.Errorf("Invalid diff sign %q in: %q", s[0], s)
+		}
+		text, rest, err := dslUnquote(s[1:])
+		if err != nil {
+			return p, s, err
+		}
+		p.diffs = append(p.diffs, Diff{op, text})
+		if op != DiffInsert {
+			p.length1 += len(text)
+		}
+		if op != DiffDelete {
+			p.length2 += len(text)
+		}
+		s = rest
+	}
+}
+
+func dslQuote(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('\'')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\'', '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				fmt.Fprintf(&buf, `\x%02x`, c)
+			} else {
+				buf.WriteByte(c)
+			}
+		}
+	}
+	buf.WriteByte('\'')
+	return buf.String()
+}
+
+// dslUnquote parses the quoted text at the start of s and returns it with
+// the rest of s.
+func dslUnquote(s string) (string, string, error) {
+	if len(s) == 0 || s[0] != '\'' {
+		return "", s, fmt.Errorf("Missing quote in: %q", s)
+	}
+	var buf bytes.Buffer
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			return buf.String(), s[i+1:], nil
+		}
+		if c != '\\' {
+			buf.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(s) {
+			break
+		}
+		switch s[i] {
+		case 'n':
+			buf.WriteByte('\n')
+		case 't':
+			buf.WriteByte('\t')
+		case 'r':
+			buf.WriteByte('\r')
+		case 'x':
+			if i+2 >= len(s) {
+				return "", s, fmt.Errorf("Invalid escape in: %q", s)
+			}
+			b, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return "", s, fmt.Errorf("Invalid escape in: %q", s)
+			}
+			buf.WriteByte(byte(b))
+			i += 2
+		default:
+			buf.WriteByte(s[i])
+		}
+	}
+	return "", s, fmt.Errorf("Unterminated quote in: %q", s)
+}
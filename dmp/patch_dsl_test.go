@@ -0,0 +1,40 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchDSL(t *testing.T) {
+	ps, err := PatchFromDSL("@12 ='The ' -'quick' +'slow' =' fox'")
+	assert.Nil(t, err, "")
+	assert.Equal(t, "@@ -13,13 +13,12 @@\n The \n-quick\n+slow\n  fox\n", PatchToText(ps), "")
+
+	// Round trip of PatchMake output, with escapes.
+	dmp := New()
+	ps = dmp.PatchMake("It's a\ttab\nand 'quotes' \\ \x01 here, and more text.",
+		"It is a tab\nand \"quotes\" / \x02 here, and more text.")
+	dsl := PatchToDSL(ps)
+	assert.Equal(t, "@0 ='It' -'\\'s a\\t' +' is a ' ='tab\\nand ' ='\\'quo'\n"+
+		"@12 ='and ' -'\\'' +'\"' ='quotes' -'\\' \\\\ \\x01' +'\" / \\x02' =' her'\n",
+		dsl, "")
+	parsed, err := PatchFromDSL(dsl)
+	assert.Nil(t, err, "")
+	assert.Equal(t, ps, parsed, "")
+
+	// Several patches, and a start that differs in the patched text.
+	ps = MustPatchFromDSL("@3 -'a' ; @9,8 +'b'\n")
+	assert.Equal(t, "@3 -'a'\n@9,8 +'b'\n", PatchToDSL(ps), "")
+	assert.Equal(t, 1, ps[0].length1, "")
+	assert.Equal(t, 0, ps[0].length2, "")
+
+	ps, err = PatchFromDSL("")
+	assert.Nil(t, err, "")
+	assert.Equal(t, 0, len(ps), "")
+
+	for _, bad := range []string{"12 ='a'", "@x ='a'", "@1 *'a'", "@1 ='a", "@1 =a", "@1 ='\\x4'"} {
+		_, err = PatchFromDSL(bad)
+		assert.NotNil(t, err, bad)
+	}
+}
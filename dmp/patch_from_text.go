@@ -9,7 +9,9 @@ import (
 )
 
 // PatchFromText parses a textual representation of patches and returns a List
-// of Patch objects.
+// of Patch objects.  The lengths in each hunk header must match its body
+// and the starts of successive patches must not decrease; errors give the
+// line of the offending header.
 func PatchFromText(textline string) ([]Patch, error) {
 	patches := []Patch{}
 	if len(textline) == 0 {
@@ -17,45 +19,18 @@ func PatchFromText(textline string) ([]Patch, error) {
 	}
 	text := strings.Split(textline, "\n")
 	textPointer := 0
-	patchHeader := regexp.MustCompile(
-		"^@@ -(\\d+),?(\\d*) \\+(\\d+),?(\\d*) @@$",
-	)
 
-	var patch Patch
+	var patch, prev Patch
 	sign := uint8(0)
 	line := ""
 	for textPointer < len(text) {
-
-		if !patchHeader.MatchString(text[textPointer]) {
+		var ok bool
+		patch, ok = parsePatchHeader(text[textPointer])
+		if !ok {
 			err := fmt.Errorf("Invalid patch string: %s", text[textPointer])
 			return patches, err
 		}
-
-		patch = Patch{}
-		m := patchHeader.FindStringSubmatch(text[textPointer])
-
-		patch.start1, _ = strconv.Atoi(m[1])
-		if len(m[2]) == 0 {
-			patch.start1--
-			patch.length1 = 1
-		} else if m[2] == "0" {
-			patch.length1 = 0
-		} else {
-			patch.start1--
-			patch.length1, _ = strconv.Atoi(m[2])
-		}
-
-		patch.start2, _ = strconv.Atoi(m[3])
-
-		if len(m[4]) == 0 {
-			patch.start2--
-			patch.length2 = 1
-		} else if m[4] == "0" {
-			patch.length2 = 0
-		} else {
-			patch.start2--
-			patch.length2, _ = strconv.Atoi(m[4])
-		}
+		headerLine := textPointer + 1
 		textPointer++
 
 		for textPointer < len(text) {
@@ -66,9 +41,7 @@ func PatchFromText(textline string) ([]Patch, error) {
 				continue
 			}
 
-			line = text[textPointer][1:]
-			line = strings.Replace(line, "+", "%2b", -1)
-			line, _ = url.QueryUnescape(line)
+			line = unescapePatchText(text[textPointer][1:])
 			if sign == '-' {
 				// Deletion.
 				patch.diffs = append(patch.diffs, Diff{DiffDelete, line})
@@ -90,7 +63,72 @@ func PatchFromText(textline string) ([]Patch, error) {
 			textPointer++
 		}
 
+		if err := checkPatchText(patch, prev, headerLine); err != nil {
+			return patches, err
+		}
 		patches = append(patches, patch)
+		prev = patch
 	}
 	return patches, nil
 }
+
+// unescapePatchText decodes the text of a diff line of patch text.
+func unescapePatchText(line string) string {
+	line = strings.Replace(line, "+", "%2b", -1)
+	line, _ = url.QueryUnescape(line)
+	return line
+}
+
+var patchHeader = regexp.MustCompile(
+	"^@@ -(\\d+),?(\\d*) \\+(\\d+),?(\\d*) @@$",
+)
+
+// parsePatchHeader returns a patch with the starts and lengths of a hunk
+// header.  Headers with numbers that overflow an int are rejected.
+func parsePatchHeader(header string) (Patch, bool) {
+	var patch Patch
+	m := patchHeader.FindStringSubmatch(header)
+	if m == nil {
+		return patch, false
+	}
+	var ok1, ok2 bool
+	patch.start1, patch.length1, ok1 = parsePatchCoords(m[1], m[2])
+	patch.start2, patch.length2, ok2 = parsePatchCoords(m[3], m[4])
+	return patch, ok1 && ok2
+}
+
+// parsePatchCoords parses one side of a hunk header, the inverse of
+// patchCoords.
+func parsePatchCoords(start, length string) (int, int, bool) {
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, false
+	}
+	switch length {
+	case "":
+		return n - 1, 1, true
+	case "0":
+		return n, 0, true
+	}
+	l, err := strconv.Atoi(length)
+	return n - 1, l, err == nil
+}
+
+// checkPatchText checks a parsed patch against its header and against the
+// previous patch.
+func checkPatchText(p, prev Patch, line int) error {
+	length1 := len(DiffText1(p.diffs))
+	length2 := len(DiffText2(p.diffs))
+	if length1 != p.length1 || length2 != p.length2 {
+		return fmt.Errorf(
+			"Patch length mismatch on line %d: header -%d +%d, body -%d +%d",
+			line, p.length1, p.length2, length1, length2,
+		)
+	}
+	if p.start1 < prev.start1 || p.start2 < prev.start2 {
+		return fmt.Errorf(
+			"Patch on line %d starts before the previous patch", line,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package dmp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchFromTextValidation(t *testing.T) {
+	_, err := PatchFromText("@@ -1,4 +1,4 @@\n-abc\n+abcd\n")
+	assert.NotNil(t, err, "Deleted length does not match the header.")
+	assert.True(t, strings.Contains(err.Error(), "line 1"), err.Error())
+
+	_, err = PatchFromText("@@ -1 +1 @@\n-a\n+b\n@@ -3 +3,2 @@\n-c\n+d\n")
+	assert.NotNil(t, err, "Inserted length does not match the header.")
+	assert.True(t, strings.Contains(err.Error(), "line 4"), err.Error())
+
+	_, err = PatchFromText("@@ -5 +5 @@\n-a\n+b\n@@ -1 +1 @@\n-c\n+d\n")
+	assert.NotNil(t, err, "Patches out of order.")
+	assert.True(t, strings.Contains(err.Error(), "line 4"), err.Error())
+
+	ps, err := PatchFromText("@@ -1 +1 @@\n-a\n+b\n@@ -1 +1 @@\n-b\n+c\n")
+	assert.Nil(t, err, "Patches may start at the same place.")
+	assert.Equal(t, 2, len(ps), "")
+
+	dmp := New()
+	text1 := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+	text2 := strings.Replace(text1, "fox", "cat", -1)
+	ps = dmp.PatchMake(text1, text2)
+	ps = dmp.PatchSplitMax(ps)
+	parsed, err := PatchFromText(PatchToText(ps))
+	assert.Nil(t, err, "Output of PatchMake round trips.")
+	assert.Equal(t, PatchToText(ps), PatchToText(parsed), "")
+}
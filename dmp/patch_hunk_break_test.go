@@ -0,0 +1,30 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchHunkBreakThreshold(t *testing.T) {
+	text1 := "The quick brown fox jumps over the lazy dog."
+	text2 := "That quick brown fox jumped over a lazy dog."
+	dmp := New()
+	assert.Equal(t, 2, len(dmp.PatchMake(text1, text2)), "Default.")
+
+	dmp.PatchHunkBreakThreshold = 100
+	ps := dmp.PatchMake(text1, text2)
+	assert.Equal(t, 1, len(ps), "One combined patch.")
+	s, applied := dmp.Apply(ps, text1)
+	assert.Equal(t, text2, s, "")
+	assert.Equal(t, []bool{true, true}, applied, "Split again to fit MatchMaxBits.")
+
+	dmp.PatchHunkBreakThreshold = 1
+	ps = dmp.PatchMake("abcdef", []Diff{
+		{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffInsert, "B"},
+		{DiffEqual, "c"}, {DiffDelete, "d"}, {DiffInsert, "D"},
+		{DiffEqual, "ef"}})
+	assert.Equal(t, 2, len(ps), "One patch per change.")
+	s, _ = dmp.Apply(ps, "abcdef")
+	assert.Equal(t, "aBcDef", s, "")
+}
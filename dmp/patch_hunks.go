@@ -0,0 +1,155 @@
+package dmp
+
+// PatchFilter returns the patches for which keep returns true, given
+// their index and patch, as for staging some hunks of a change.  The
+// starts of the patches kept are moved back by the changes in length of
+// the patches dropped before them, as they count in the text with the
+// earlier patches applied.
+func PatchFilter(ps []Patch, keep func(i int, p Patch) bool) []Patch {
+	ret := []Patch{}
+	shift := 0
+	for i, p := range ps {
+		if !keep(i, p) {
+			shift += p.length2 - p.length1
+			continue
+		}
+		p = PatchDeepCopy([]Patch{p})[0]
+		p.start1 -= shift
+		p.start2 -= shift
+		ret = append(ret, p)
+	}
+	return ret
+}
+
+// PatchSplitHunks splits each patch into patches of one change each: a
+// run of deletions and insertions, with the equalities on both sides of
+// it as context.  Applying all of them is the same as applying the
+// original patches, and PatchFilter can then pick among them.  Changes
+// with less text between them than the padding of Apply stay together,
+// as Apply takes a patch with so little context for one at the edge of
+// the text.
+func (dmp *DMP) PatchSplitHunks(ps []Patch) []Patch {
+	margin := len(patchPadding(dmp))
+	ret := []Patch{}
+	for _, p := range ps {
+		// at[i] is the offset of diff i in the text of p with the changes
+		// before it applied.
+		at := make([]int, len(p.diffs)+1)
+		for i, d := range p.diffs {
+			at[i+1] = at[i]
+			if d.Type != DiffDelete {
+				at[i+1] += len(d.Text)
+			}
+		}
+		hunks := 0
+		for i := 0; i < len(p.diffs); i++ {
+			if p.diffs[i].Type == DiffEqual {
+				continue
+			}
+			lo, hi := i, i
+			for hi < len(p.diffs) && (p.diffs[hi].Type != DiffEqual ||
+				hi+1 < len(p.diffs) && len(p.diffs[hi].Text) < margin) {
+				hi++
+			}
+			i = hi
+			if lo > 0 {
+				lo--
+			}
+			if hi < len(p.diffs) {
+				hi++
+			}
+			diffs := append([]Diff{}, p.diffs[lo:hi]...)
+			ret = append(ret, Patch{
+				diffs:   diffs,
+				start1:  p.start1 + at[lo],
+				start2:  p.start2 + at[lo],
+				length1: len(DiffText1(diffs)),
+				length2: len(DiffText2(diffs)),
+				Replica: p.Replica,
+				Version: p.Version.Copy(),
+			})
+			hunks++
+		}
+		if hunks == 0 {
+			ret = append(ret, PatchDeepCopy([]Patch{p})...)
+		}
+	}
+	return ret
+}
+
+// PatchRetarget moves patches made against a text to where they belong
+// in that text after edit, the diffs of an upstream change to it.  Unlike
+// PatchRebase, it needs neither text, but does not check that the text of
+// the patches is left alone by the edit: Apply finds patches whose
+// context changed by fuzzy matching.
+func PatchRetarget(ps []Patch, edit []Diff) []Patch {
+	edit = withoutMoves(edit)
+	ret := PatchDeepCopy(ps)
+	for i, loc := range patchBaseStarts(ps) {
+		p := &ret[i]
+		moved := DiffXIndex(edit, loc) - loc
+		p.start1 += moved
+		p.start2 += moved
+	}
+	return ret
+}
+
+// patchBaseStarts returns where each of ps starts in the text they were
+// made against.  start1 counts in the text with the earlier patches
+// applied, so the changes in length of those are taken back.
+func patchBaseStarts(ps []Patch) []int {
+	starts := make([]int, len(ps))
+	shift := 0
+	for i, p := range ps {
+		starts[i] = p.start1 - shift
+		shift += p.length2 - p.length1
+	}
+	return starts
+}
+
+// PatchMergeAdjacent merges consecutive patches whose texts touch or
+// overlap into one, such as the context of one with the context of the
+// next.  Patches with text between them are kept apart, as it is not
+// known.
+func PatchMergeAdjacent(ps []Patch) []Patch {
+	ret := []Patch{}
+	for _, p := range PatchDeepCopy(ps) {
+		if len(ret) == 0 {
+			ret = append(ret, p)
+			continue
+		}
+		last := &ret[len(ret)-1]
+		overlap := last.start2 + last.length2 - p.start1
+		if overlap < 0 || !patchTrimContext(&p, last, overlap) {
+			ret = append(ret, p)
+			continue
+		}
+		last.diffs = diffCleanupMerge(append(last.diffs, p.diffs...))
+		last.length1 += p.length1
+		last.length2 += p.length2
+	}
+	return ret
+}
+
+// patchTrimContext drops the first n bytes of the context of p, which the
+// context at the end of last covers, and reports whether it could.
+func patchTrimContext(p, last *Patch, n int) bool {
+	if n == 0 {
+		return true
+	}
+	if len(p.diffs) == 0 || len(last.diffs) == 0 {
+		return false
+	}
+	first, tail := p.diffs[0], last.diffs[len(last.diffs)-1]
+	if first.Type != DiffEqual || tail.Type != DiffEqual ||
+		len(first.Text) < n || len(tail.Text) < n ||
+		first.Text[:n] != tail.Text[len(tail.Text)-n:] {
+		return false
+	}
+	p.diffs[0].Text = first.Text[n:]
+	p.start1 += n
+	p.start2 += n
+	p.length1 -= n
+	p.length2 -= n
+	return true
+}
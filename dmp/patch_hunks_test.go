@@ -0,0 +1,101 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchSplitHunks(t *testing.T) {
+	dmp := New()
+	text1 := "The quick brown fox jumps over the lazy dog."
+	text2 := "That quick brown fox jumped over a lazy dog."
+	ps := dmp.PatchMake(text1, text2)
+	hunks := dmp.PatchSplitHunks(ps)
+	assert.Equal(t, "@@ -1,11 +1,12 @@\n Th\n-e\n+at\n  quick b\n"+
+		"@@ -22,11 +22,12 @@\n jump\n-s\n+ed\n  over \n"+
+		"@@ -28,13 +28,11 @@\n  over \n-the\n+a\n  laz\n",
+		PatchToText(hunks), "")
+	s, applied := dmp.Apply(hunks, text1)
+	assert.Equal(t, text2, s, "")
+	assert.Equal(t, []bool{true, true, true}, applied, "")
+
+	// Patches without changes are kept.
+	p, _ := NewPatch([]Diff{{DiffEqual, "abc"}}, 0, 0)
+	assert.Equal(t, []Patch{p}, dmp.PatchSplitHunks([]Patch{p}), "")
+
+	// Null case.
+	assert.Equal(t, []Patch{}, dmp.PatchSplitHunks(nil), "")
+}
+
+func TestPatchFilter(t *testing.T) {
+	dmp := New()
+	text1 := "The quick brown fox jumps over the lazy dog."
+	hunks := dmp.PatchSplitHunks(dmp.PatchMake(text1,
+		"That quick brown fox jumped over a lazy dog."))
+
+	// Stage the second hunk only.
+	staged := PatchFilter(hunks, func(i int, p Patch) bool { return i == 1 })
+	assert.Equal(t, "@@ -21,11 +21,12 @@\n jump\n-s\n+ed\n  over \n",
+		PatchToText(staged), "")
+	s, _ := dmp.Apply(staged, text1)
+	assert.Equal(t, "The quick brown fox jumped over the lazy dog.", s, "")
+
+	// Stage all but the first.
+	staged = PatchFilter(hunks, func(i int, p Patch) bool { return i > 0 })
+	s, _ = dmp.Apply(staged, text1)
+	assert.Equal(t, "The quick brown fox jumped over a lazy dog.", s, "")
+	assert.Equal(t, 20, staged[0].start1, "")
+
+	assert.Equal(t, []Patch{},
+		PatchFilter(hunks, func(int, Patch) bool { return false }), "")
+
+	// Each hunk applies alone with the context it has.
+	for _, texts := range [][2]string{
+		{text1, "That quick brown fox jumped over a lazy dog."},
+		{"aab-aaa", "aayyaaz"},
+		{"ba ---- ab -b b bbbbba", "bx ---y ab -byb bbbbba"},
+		{"---babaab ba-b ", "y--zabaab bayb "},
+	} {
+		hunks := dmp.PatchSplitHunks(dmp.PatchMake(texts[0], texts[1]))
+		for i := range hunks {
+			staged := PatchFilter(hunks,
+				func(j int, p Patch) bool { return i == j })
+			_, results := dmp.ApplyWithOptions(staged, texts[0],
+				ApplyOptions{ExactContext: true})
+			assert.True(t, results[0].Applied, PatchToText(staged))
+		}
+	}
+}
+
+func TestPatchRetarget(t *testing.T) {
+	dmp := New()
+	text1 := "The quick brown fox jumps over the lazy dog."
+	ps := dmp.PatchMake(text1, "That quick brown fox jumped over a lazy dog.")
+	edit := dmp.DiffMain(text1,
+		"Look. The quick brown fox jumps over the lazy dog.", false)
+	moved := PatchRetarget(ps, edit)
+	assert.Equal(t, "@@ -7,11 +7,12 @@\n Th\n-e\n+at\n  quick b\n"+
+		"@@ -28,18 +28,17 @@\n jump\n-s\n+ed\n  over \n-the\n+a\n  laz\n",
+		PatchToText(moved), "")
+	// The originals are left alone.
+	assert.Equal(t, 0, ps[0].start1, "")
+}
+
+func TestPatchMergeAdjacent(t *testing.T) {
+	dmp := New()
+	text1 := "The quick brown fox jumps over the lazy dog."
+	ps := dmp.PatchMake(text1, "That quick brown fox jumped over a lazy dog.")
+	merged := PatchMergeAdjacent(dmp.PatchSplitHunks(ps))
+	assert.Equal(t, PatchToText(ps), PatchToText(merged), "")
+
+	// Patches with text between them are kept apart.
+	hunks := dmp.PatchSplitHunks(ps)
+	assert.Equal(t, 2, len(PatchMergeAdjacent(hunks[:2])), "")
+
+	// Contexts that overlap but differ are kept apart.
+	hunks[2].diffs[0].Text = " OVER "
+	assert.Equal(t, 2, len(PatchMergeAdjacent(hunks[1:])), "")
+
+	assert.Equal(t, []Patch{}, PatchMergeAdjacent(nil), "")
+}
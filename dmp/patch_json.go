@@ -0,0 +1,69 @@
+package dmp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// patchJSON is the JSON schema of a Patch.  Starts are 0-based byte
+// offsets, as in Patch.  Diff texts are %xx escaped as in PatchToText,
+// since the context PatchMake adds may cut a rune in half, and JSON
+// strings cannot carry the bytes of half a rune.
+type patchJSON struct {
+	Diffs   []Diff `json:"diffs"`
+	Start1  int    `json:"start1"`
+	Start2  int    `json:"start2"`
+	Length1 int    `json:"length1"`
+	Length2 int    `json:"length2"`
+
+	Replica string        `json:"replica,omitempty"`
+	Version VersionVector `json:"version,omitempty"`
+}
+
+// MarshalJSON encodes p as an object with its diffs, starts and lengths,
+// and its replica and version if set:
+// {"diffs":[[0,"The "],[-1,"quick"]],"start1":0,"start2":0,...}.
+func (p Patch) MarshalJSON() ([]byte, error) {
+	diffs := make([]Diff, len(p.diffs))
+	for i, d := range p.diffs {
+		diffs[i] = Diff{d.Type, escapePatchText(d.Text)}
+	}
+	return json.Marshal(patchJSON{
+		diffs, p.start1, p.start2, p.length1, p.length2,
+		p.Replica, p.Version,
+	})
+}
+
+// UnmarshalJSON decodes the output of MarshalJSON.  The lengths must match
+// the diffs.
+func (p *Patch) UnmarshalJSON(data []byte) error {
+	var j patchJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	for i, d := range j.Diffs {
+		text, err := url.QueryUnescape(strings.Replace(d.Text, "+", "%2b", -1))
+		if err != nil {
+			return fmt.Errorf("Invalid patch text: %q", d.Text)
+		}
+		j.Diffs[i].Text = text
+	}
+	length1 := len(DiffText1(j.Diffs))
+	length2 := len(DiffText2(j.Diffs))
+	if j.Length1 != length1 || j.Length2 != length2 {
+		return fmt.Errorf(
+			"Patch length mismatch: header -%d +%d, diffs -%d +%d",
+			j.Length1, j.Length2, length1, length2,
+		)
+	}
+	if j.Start1 < 0 || j.Start2 < 0 {
+		return fmt.Errorf("Invalid patch start: %d,%d", j.Start1, j.Start2)
+	}
+	*p = Patch{
+		j.Diffs, j.Start1, j.Start2, j.Length1, j.Length2,
+		j.Replica, j.Version,
+	}
+	return nil
+}
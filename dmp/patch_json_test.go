@@ -0,0 +1,77 @@
+package dmp
+
+import (
+	"encoding/json"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffJSON(t *testing.T) {
+	diffs := []Diff{{DiffEqual, "The "}, {DiffDelete, "quick"},
+		{DiffInsert, "slow\n"}}
+	data, err := DiffsToJSON(diffs)
+	assert.Nil(t, err, "")
+	assert.Equal(t, `[[0,"The "],[-1,"quick"],[1,"slow\n"]]`, string(data), "")
+
+	// encoding/json uses the same schema.
+	std, err := json.Marshal(diffs)
+	assert.Nil(t, err, "")
+	assert.Equal(t, string(data), string(std), "")
+
+	decoded, err := DiffsFromJSON(data)
+	assert.Nil(t, err, "")
+	assert.Equal(t, diffs, decoded, "")
+	var std2 []Diff
+	assert.Nil(t, json.Unmarshal(data, &std2), "")
+	assert.Equal(t, diffs, std2, "")
+
+	var d Diff
+	assert.NotNil(t, json.Unmarshal([]byte(`[2,"a"]`), &d), "")
+	assert.NotNil(t, json.Unmarshal([]byte(`[0]`), &d), "")
+	assert.NotNil(t, json.Unmarshal([]byte(`{"op":0}`), &d), "")
+}
+
+func TestPatchJSON(t *testing.T) {
+	dmp := New()
+	ps := dmp.PatchMake("The quick brown fox jumps.",
+		"The slow brown fox leaps.")
+	data, err := json.Marshal(ps)
+	assert.Nil(t, err, "")
+	assert.Equal(t, `[{"diffs":[[0,"The "],[-1,"quick"],[1,"slow"],`+
+		`[0," bro"]],"start1":0,"start2":0,"length1":13,"length2":12},`+
+		`{"diffs":[[0,"fox "],[-1,"jum"],[1,"lea"],[0,"ps."]],`+
+		`"start1":15,"start2":15,"length1":10,"length2":10}]`,
+		string(data), "")
+
+	var decoded []Patch
+	assert.Nil(t, json.Unmarshal(data, &decoded), "")
+	assert.Equal(t, PatchToText(ps), PatchToText(decoded), "")
+
+	var p Patch
+	err = json.Unmarshal([]byte(`{"diffs":[[1,"ab"]],"length2":3}`), &p)
+	assert.NotNil(t, err, "Length mismatch.")
+	err = json.Unmarshal([]byte(`{"diffs":[[1,"%zz"]],"length2":3}`), &p)
+	assert.NotNil(t, err, "Invalid escape.")
+	data, _ = json.Marshal(Patch{})
+	assert.Equal(t, `{"diffs":[],"start1":0,"start2":0,"length1":0,`+
+		`"length2":0}`, string(data), "")
+}
+
+func TestPatchJSONMultiByte(t *testing.T) {
+	// The context of the patch cuts é in half.
+	dmp := New()
+	text1, text2 := "éééé x éééé", "éééé y éééé"
+	ps := dmp.PatchMake(text1, text2)
+	assert.False(t, utf8.ValidString(ps[0].diffs[0].Text), "")
+	data, err := json.Marshal(ps)
+	assert.Nil(t, err, "")
+
+	var decoded []Patch
+	assert.Nil(t, json.Unmarshal(data, &decoded), "")
+	assert.Equal(t, ps, decoded, "")
+	text, applied := dmp.Apply(decoded, text1)
+	assert.Equal(t, text2, text, "")
+	assert.Equal(t, []bool{true}, applied, "")
+}
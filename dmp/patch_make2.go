@@ -1,5 +1,9 @@
 package dmp
 
+import (
+	"bytes"
+)
+
 // Compute a list of patches to turn text1 into text2.
 // text2 is not provided, diffs are the delta between text1 and text2.
 func patchMake2(dmp *DMP, text1 string, diffs []Diff) []Patch {
@@ -16,7 +20,8 @@ func patchMake2(dmp *DMP, text1 string, diffs []Diff) []Patch {
 	// text2 (post). We recreate the patches one by one to determine
 	// context info.
 	pre := text1
-	post := text1
+	var buf bytes.Buffer
+	hunkBreak := patchHunkBreak(dmp)
 
 	for i, d := range diffs {
 		if len(p.diffs) == 0 && d.Type != DiffEqual {
@@ -29,15 +34,13 @@ func patchMake2(dmp *DMP, text1 string, diffs []Diff) []Patch {
 		case DiffInsert:
 			p.diffs = append(p.diffs, d)
 			p.length2 += len(d.Text)
-			post = post[:nchar2] + d.Text + post[nchar2:]
 
 		case DiffDelete:
 			p.length1 += len(d.Text)
 			p.diffs = append(p.diffs, d)
-			post = post[:nchar2] + post[nchar2+len(d.Text):]
 
 		case DiffEqual:
-			if len(d.Text) <= 2*dmp.PatchMargin &&
+			if len(d.Text) <= hunkBreak &&
 				len(p.diffs) != 0 && i != len(diffs)-1 {
 				// Small equality inside a patch.
 				p.diffs = append(p.diffs, d)
@@ -45,7 +48,7 @@ func patchMake2(dmp *DMP, text1 string, diffs []Diff) []Patch {
 				p.length2 += len(d.Text)
 			}
 
-			if len(d.Text) >= 2*dmp.PatchMargin {
+			if len(d.Text) >= hunkBreak {
 				// Time for a new patch.
 				if len(p.diffs) != 0 {
 					p = patchAddContext(dmp, p, pre)
@@ -54,8 +57,12 @@ func patchMake2(dmp *DMP, text1 string, diffs []Diff) []Patch {
 					// Unlike Unidiff, our patch lists have a rolling context.
 					// code.google.com/p/google-diff-match-patch/wiki/Unidiff
 					// Update prepatch text & pos to reflect the application
-					// of the just completed patch.
-					pre = post
+					// of the just completed patch: the diffs so far are
+					// applied, the rest are not.
+					buf.Reset()
+					DiffText2To(&buf, diffs[:i])
+					DiffText1To(&buf, diffs[i:])
+					pre = buf.String()
 					nchar1 = nchar2
 				}
 			}
@@ -78,3 +85,12 @@ func patchMake2(dmp *DMP, text1 string, diffs []Diff) []Patch {
 
 	return ps
 }
+
+// patchHunkBreak returns the length of the equalities that end a patch:
+// PatchHunkBreakThreshold, or twice PatchMargin by default.
+func patchHunkBreak(dmp *DMP) int {
+	if dmp.PatchHunkBreakThreshold > 0 {
+		return dmp.PatchHunkBreakThreshold
+	}
+	return 2 * dmp.PatchMargin
+}
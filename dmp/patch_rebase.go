@@ -0,0 +1,68 @@
+package dmp
+
+import (
+	"fmt"
+)
+
+// PatchRebase re-anchors patches made against oldBase so that they apply
+// cleanly to newBase.  The bases are diffed and the location of each patch
+// is moved along; the text a patch covers, context included, must be
+// unchanged between the bases.  Otherwise the patch conflicts with the
+// change of base and an error is returned.  The context of each patch is
+// then made anew from newBase, as PatchMake would.
+func (dmp *DMP) PatchRebase(ps []Patch, oldBase, newBase string) (
+	[]Patch, error,
+) {
+	diffs := dmp.exact().DiffMain(oldBase, newBase, true)
+	ret := PatchDeepCopy(ps)
+	// newBase with the rebased patches before applied, which the context
+	// of the next one comes from.
+	text := newBase
+	for i, loc := range patchBaseStarts(ps) {
+		p := &ret[i]
+		text1 := DiffText1(p.diffs)
+		end := loc + len(text1)
+		if loc < 0 || end > len(oldBase) || oldBase[loc:end] != text1 {
+			return nil, fmt.Errorf("Patch %d does not apply to the old base", i)
+		}
+
+		start := DiffXIndex(diffs, loc)
+		end = start + len(text1)
+		if end > len(newBase) || newBase[start:end] != text1 {
+			return nil, fmt.Errorf(
+				"Patch %d conflicts with the change of base", i,
+			)
+		}
+		*p = patchRecontext(dmp, *p, start+p.start1-loc, text)
+		text = text[:p.start1] + DiffText2(p.diffs) +
+			text[p.start1+p.length1:]
+	}
+	return ret, nil
+}
+
+// patchRecontext moves p to loc in s, which holds the text of p there, and
+// replaces its context with context from s.
+func patchRecontext(dmp *DMP, p Patch, loc int, s string) Patch {
+	diffs := p.diffs
+	pre := 0
+	if len(diffs) > 0 && diffs[0].Type == DiffEqual {
+		pre = len(diffs[0].Text)
+		diffs = diffs[1:]
+	}
+	if n := len(diffs); n > 0 && diffs[n-1].Type == DiffEqual {
+		diffs = diffs[:n-1]
+	}
+	// start2 - start1 is kept as it was.
+	offset := p.start2 - p.start1
+	if len(diffs) == 0 {
+		p.start1, p.start2 = loc, loc+offset
+		return p
+	}
+	p.diffs = append([]Diff{}, diffs...)
+	p.start1, p.start2 = loc+pre, loc+pre
+	p.length1 = len(DiffText1(diffs))
+	p.length2 = len(DiffText2(diffs))
+	p = patchAddContext(dmp, p, s)
+	p.start2 += offset
+	return p
+}
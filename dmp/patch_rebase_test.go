@@ -0,0 +1,50 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchRebase(t *testing.T) {
+	dmp := New()
+	oldBase := "The quick brown fox jumps over the lazy dog."
+	patches := dmp.PatchMake(oldBase, "The quick red fox jumps over the very lazy dog.")
+
+	newBase := "Preface. The quick brown fox jumps over the lazy dog. The end."
+	rebased, err := dmp.PatchRebase(patches, oldBase, newBase)
+	assert.Nil(t, err, "")
+	assert.Equal(t, "@@ -16,13 +16,11 @@\n ick \n-brown\n+red\n  fox\n@@ -35,16 +35,21 @@\n ver the \n+very \n lazy dog\n",
+		PatchToText(rebased), "")
+	assert.Equal(t, "@@ -7,13 +7,11 @@\n ick \n-brown\n+red\n  fox\n@@ -26,16 +26,21 @@\n ver the \n+very \n lazy dog\n",
+		PatchToText(patches), "Original patches are untouched.")
+
+	// Rebased patches apply exactly where expected.
+	dmp.MatchDistance = 0
+	dmp.MatchThreshold = 0
+	s, results := dmp.Apply(rebased, newBase)
+	assert.Equal(t, "Preface. The quick red fox jumps over the very lazy dog. The end.", s, "")
+	assert.Equal(t, []bool{true, true}, results, "")
+
+	// The base changed under the first patch.
+	_, err = dmp.PatchRebase(patches, oldBase, "The quick brown cat jumps over the lazy dog.")
+	assert.NotNil(t, err, "")
+
+	_, err = dmp.PatchRebase(patches, "Something else.", newBase)
+	assert.NotNil(t, err, "Patches not made against the old base.")
+}
+
+func TestPatchRebaseContext(t *testing.T) {
+	// The context of the patch stops at the start of the old base, and
+	// the text before it in the new base is shorter than PatchMargin.
+	dmp := New()
+	oldBase, text := "é \néaé", " éé é"
+	patches := dmp.PatchMake(oldBase, text)
+	rebased, err := dmp.PatchRebase(patches, oldBase, "XYZ"+oldBase)
+	assert.Nil(t, err, "")
+	s, results := dmp.Apply(rebased, "XYZ"+oldBase)
+	assert.Equal(t, "XYZ"+text, s, "")
+	for _, ok := range results {
+		assert.True(t, ok, "")
+	}
+}
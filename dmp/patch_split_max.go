@@ -16,7 +16,7 @@ func patchSplitMax(ps []Patch, size, margin int) []Patch {
 		pre := ""
 		for len(cur.diffs) != 0 {
 			// Create one of several smaller ps.
-			p := Patch{}
+			p := Patch{Replica: cur.Replica, Version: cur.Version}
 			empty := true
 			p.start1 = start1 - len(pre)
 			p.start2 = start2 - len(pre)
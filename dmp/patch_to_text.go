@@ -2,13 +2,23 @@ package dmp
 
 import (
 	"bytes"
+	"io"
 )
 
 // PatchToText takes a list of patches and returns a textual representation.
 func PatchToText(patches []Patch) string {
 	var text bytes.Buffer
+	PatchWriteText(&text, patches)
+	return text.String()
+}
+
+// PatchWriteText writes the text of PatchToText to w one patch at a time.
+// It returns the first write error.
+func PatchWriteText(w io.Writer, patches []Patch) error {
 	for _, p := range patches {
-		text.WriteString(p.String())
+		if _, err := io.WriteString(w, p.String()); err != nil {
+			return err
+		}
 	}
-	return text.String()
+	return nil
 }
@@ -0,0 +1,41 @@
+package dmp
+
+import (
+	"fmt"
+)
+
+// PatchValidate checks that patches are well formed: their operations are
+// known, their starts and lengths are not negative, and their lengths are
+// those of the text their diffs cover.  Patches from PatchMake and
+// PatchFromText of its output always are.  Apply tolerates malformed
+// patches, applying what it can of them, but ApplyChecked rejects them.
+func PatchValidate(ps []Patch) error {
+	for i, p := range ps {
+		if p.start1 < 0 || p.start2 < 0 {
+			return fmt.Errorf("Patch %d has a negative start: %d, %d",
+				i, p.start1, p.start2)
+		}
+		length1, length2 := 0, 0
+		for _, d := range p.diffs {
+			switch d.Type {
+			case DiffDelete:
+				length1 += len(d.Text)
+			case DiffInsert:
+				length2 += len(d.Text)
+			case DiffEqual:
+				length1 += len(d.Text)
+				length2 += len(d.Text)
+			default:
+				return fmt.Errorf("Patch %d has an invalid operation %d",
+					i, d.Type)
+			}
+		}
+		if length1 != p.length1 || length2 != p.length2 {
+			return fmt.Errorf(
+				"Patch %d length mismatch: header -%d +%d, diffs -%d +%d",
+				i, p.length1, p.length2, length1, length2,
+			)
+		}
+	}
+	return nil
+}
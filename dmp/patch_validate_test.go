@@ -0,0 +1,117 @@
+package dmp
+
+import (
+	"math/rand"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchValidate(t *testing.T) {
+	dmp := New()
+	ps := dmp.PatchMake("The quick brown fox jumps over the lazy dog.",
+		"That quick brown fox jumped over a lazy dog.")
+	assert.Nil(t, PatchValidate(ps), "")
+	parsed, _ := PatchFromText(PatchToText(ps))
+	assert.Nil(t, PatchValidate(parsed), "")
+
+	bad := PatchDeepCopy(ps)
+	bad[1].length1++
+	assert.Equal(t,
+		"Patch 1 length mismatch: header -19 +17, diffs -18 +17",
+		PatchValidate(bad).Error(), "")
+
+	bad = PatchDeepCopy(ps)
+	bad[0].start2 = -3
+	assert.Equal(t, "Patch 0 has a negative start: 0, -3",
+		PatchValidate(bad).Error(), "")
+
+	bad = PatchDeepCopy(ps)
+	bad[0].diffs[0].Type = 5
+	assert.Equal(t, "Patch 0 has an invalid operation 5",
+		PatchValidate(bad).Error(), "")
+
+	_, _, err := dmp.ApplyChecked(bad, "The quick brown fox.")
+	assert.NotNil(t, err, "")
+}
+
+func TestApplyMalformed(t *testing.T) {
+	dmp := New()
+	// A deletion past the end of the text, found at the end of it, is
+	// left out.
+	ps := []Patch{{
+		diffs:  []Diff{{DiffEqual, "fox"}, {DiffDelete, " jumps"}},
+		start1: 10, start2: 10, length1: 2, length2: 9,
+	}}
+	s, _ := dmp.Apply(ps, "The brown fox")
+	assert.Equal(t, "The brown fox", s, "")
+	_, _, err := dmp.ApplyChecked(ps, "The brown fox")
+	assert.NotNil(t, err, "")
+}
+
+// randomPatches makes up to 3 patches of random diffs, with random starts
+// and lengths that need not agree with them.
+func randomPatches(rnd *rand.Rand) []Patch {
+	text := func(n int) string {
+		b := make([]byte, rnd.Intn(n))
+		for i := range b {
+			b[i] = "ab\n\x01"[rnd.Intn(4)]
+		}
+		return string(b)
+	}
+	var ps []Patch
+	for k := rnd.Intn(3); k >= 0; k-- {
+		p := Patch{
+			start1: rnd.Intn(80) - 10, start2: rnd.Intn(80) - 10,
+			length1: rnd.Intn(80) - 10, length2: rnd.Intn(80) - 10,
+		}
+		for n := rnd.Intn(4); n > 0; n-- {
+			op := Operation(rnd.Intn(3) - 1)
+			p.diffs = append(p.diffs, Diff{op, text(60)})
+		}
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+func TestApplyRandomPatches(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	dmp := New()
+	for n := 0; n < 2000; n++ {
+		ps := randomPatches(rnd)
+		s := "a\nbab\x01ba\nab\x01\nbbaab\na\x01ab\n"[:rnd.Intn(24)]
+		dmp.PatchAmbiguity = AmbiguityPolicy(n % 3)
+		dmp.Apply(ps, s)
+		dmp.ApplyProtected(ps, s, []Range{{n % 20, n%20 + n%7}})
+	}
+}
+
+func FuzzApply(f *testing.F) {
+	f.Add("The quick brown fox.", "That quick fox jumped.",
+		"The quick brown fox.", 0, 0, 0)
+	f.Add("abc", "abxc", "xyz", 40, -2, 7)
+	dmp := New()
+	f.Fuzz(func(t *testing.T, text1, text2, s string,
+		start, length, op int) {
+		if !utf8.ValidString(text1) || !utf8.ValidString(text2) {
+			t.Skip()
+		}
+		ps := dmp.PatchMake(text1, text2)
+		if len(ps) == 0 {
+			return
+		}
+		// Tamper with the first patch.
+		ps[0].start1 += start
+		ps[0].start2 += start
+		ps[0].length1 += length
+		if len(ps[0].diffs) > 0 {
+			ps[0].diffs[0].Type = Operation(op%3 - 1)
+		}
+		dmp.Apply(ps, s)
+		if PatchValidate(ps) != nil {
+			_, _, err := dmp.ApplyChecked(ps, s)
+			assert.NotNil(t, err, "")
+		}
+	})
+}
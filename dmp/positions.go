@@ -0,0 +1,87 @@
+package dmp
+
+import (
+	"strings"
+)
+
+// TextPos is a position in a text as a line and a column, both starting
+// at 1, the column counting bytes, as in go/token.
+type TextPos struct {
+	Line int
+	Col  int
+}
+
+// DiffPos is where a diff starts and ends in text1 and in text2.  A diff
+// that does not occur in a text, such as an insertion in text1, starts and
+// ends at the position it falls on.
+type DiffPos struct {
+	Start1, End1 TextPos
+	Start2, End2 TextPos
+}
+
+// advance returns pos moved over text.
+func (pos TextPos) advance(text string) TextPos {
+	if i := strings.LastIndexByte(text, '\n'); i != -1 {
+		pos.Line += strings.Count(text, "\n")
+		pos.Col = 1
+		text = text[i+1:]
+	}
+	pos.Col += len(text)
+	return pos
+}
+
+// DiffPositions returns the DiffPos of each of diffs, for editors placing
+// decorations on both versions of a text.
+func DiffPositions(diffs []Diff) []DiffPos {
+	diffs = withoutMoves(diffs)
+	ret := make([]DiffPos, len(diffs))
+	pos1, pos2 := TextPos{1, 1}, TextPos{1, 1}
+	for i, d := range diffs {
+		end1, end2 := pos1, pos2
+		if d.Type <= 0 {
+			end1 = pos1.advance(d.Text)
+		}
+		if d.Type >= 0 {
+			end2 = pos2.advance(d.Text)
+		}
+		ret[i] = DiffPos{pos1, end1, pos2, end2}
+		pos1, pos2 = end1, end2
+	}
+	return ret
+}
+
+// DiffXPosition is DiffXIndex for positions: it returns the position in
+// text2 of the position pos in text1.  Positions past the end of a line
+// or of the text are taken as its end.
+func DiffXPosition(diffs []Diff, pos TextPos) TextPos {
+	diffs = withoutMoves(diffs)
+	loc := DiffXIndex(diffs, posOffset(DiffText1(diffs), pos))
+	return TextPos{1, 1}.advance(DiffText2(diffs)[:loc])
+}
+
+// DiffXPositionReverse is DiffXPosition from text2 to text1.
+func DiffXPositionReverse(diffs []Diff, pos TextPos) TextPos {
+	diffs = withoutMoves(diffs)
+	swapped := make([]Diff, len(diffs))
+	for i, d := range diffs {
+		swapped[i] = Diff{-d.Type, d.Text}
+	}
+	return DiffXPosition(swapped, pos)
+}
+
+// posOffset returns the byte offset of pos in text.
+func posOffset(text string, pos TextPos) int {
+	offset := 0
+	for line := 1; line < pos.Line; line++ {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i == -1 {
+			return len(text)
+		}
+		offset += i + 1
+	}
+	end := len(text)
+	if i := strings.IndexByte(text[offset:], '\n'); i != -1 {
+		end = offset + i
+	}
+	return min(offset+max(pos.Col-1, 0), end)
+}
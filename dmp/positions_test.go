@@ -0,0 +1,40 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffPositions(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "ab\nc"},
+		{DiffDelete, "d\ne"},
+		{DiffInsert, "xy"},
+		{DiffEqual, "f\n"},
+	}
+	assert.Equal(t, []DiffPos{
+		{TextPos{1, 1}, TextPos{2, 2}, TextPos{1, 1}, TextPos{2, 2}},
+		{TextPos{2, 2}, TextPos{3, 2}, TextPos{2, 2}, TextPos{2, 2}},
+		{TextPos{3, 2}, TextPos{3, 2}, TextPos{2, 2}, TextPos{2, 4}},
+		{TextPos{3, 2}, TextPos{4, 1}, TextPos{2, 4}, TextPos{3, 1}},
+	}, DiffPositions(diffs), "")
+	assert.Equal(t, []DiffPos{}, DiffPositions(nil), "")
+}
+
+func TestDiffXPosition(t *testing.T) {
+	// "ab\ncd\nef\n" -> "ab\nX\ncd\nef\n"
+	diffs := []Diff{
+		{DiffEqual, "ab\n"}, {DiffInsert, "X\n"}, {DiffEqual, "cd\nef\n"},
+	}
+	assert.Equal(t, TextPos{1, 2}, DiffXPosition(diffs, TextPos{1, 2}), "")
+	assert.Equal(t, TextPos{3, 2}, DiffXPosition(diffs, TextPos{2, 2}), "")
+	assert.Equal(t, TextPos{4, 3}, DiffXPosition(diffs, TextPos{3, 9}), "")
+	assert.Equal(t, TextPos{5, 1}, DiffXPosition(diffs, TextPos{9, 1}), "")
+
+	assert.Equal(t, TextPos{2, 2},
+		DiffXPositionReverse(diffs, TextPos{3, 2}), "")
+	// The inserted line maps to where it was inserted.
+	assert.Equal(t, TextPos{2, 1},
+		DiffXPositionReverse(diffs, TextPos{2, 2}), "")
+}
@@ -0,0 +1,153 @@
+package dmp
+
+import (
+	"sort"
+)
+
+// PreparedBase is a base text indexed once for many diffs against it, such
+// as the revisions of a document compared with its original.  It holds a
+// suffix array of the base, which DiffPrepared uses to find substrings of
+// the base without scanning it.  A PreparedBase is read-only and may be
+// shared by goroutines.
+type PreparedBase struct {
+	text []rune
+	// Start of each suffix of text, in lexicographic order.
+	sa []int32
+}
+
+// NewPreparedBase indexes base.  It takes O(n log² n) time for a base of
+// n runes.
+func NewPreparedBase(base string) *PreparedBase {
+	text := []rune(base)
+	n := len(text)
+	sa := make([]int32, n)
+	rank := make([]int, n)
+	for i, r := range text {
+		sa[i] = int32(i)
+		rank[i] = int(r)
+	}
+	// Prefix doubling: sort the suffixes by their first k runes, then by
+	// their first 2k runes using the ranks of the previous round.
+	tmp := make([]int, n)
+	for k := 1; n > 1; k *= 2 {
+		key := func(i int32) (int, int) {
+			second := -1
+			if int(i)+k < n {
+				second = rank[int(i)+k]
+			}
+			return rank[i], second
+		}
+		sort.Slice(sa, func(a, b int) bool {
+			a1, a2 := key(sa[a])
+			b1, b2 := key(sa[b])
+			return a1 < b1 || a1 == b1 && a2 < b2
+		})
+		tmp[sa[0]] = 0
+		for i := 1; i < n; i++ {
+			p1, p2 := key(sa[i-1])
+			c1, c2 := key(sa[i])
+			tmp[sa[i]] = tmp[sa[i-1]]
+			if p1 != c1 || p2 != c2 {
+				tmp[sa[i]]++
+			}
+		}
+		rank, tmp = tmp, rank
+		if rank[sa[n-1]] == n-1 {
+			break
+		}
+	}
+	return &PreparedBase{text: text, sa: sa}
+}
+
+// String returns the base text.
+func (b *PreparedBase) String() string {
+	return string(b.text)
+}
+
+// DiffPrepared finds the differences between the prepared base and text,
+// like DiffMain(base.String(), text, checkLines).  While the pieces left
+// to diff are parts of the base, the checks for a text inside the other
+// and for a common half of both look the pieces of text up in the index
+// instead of scanning the base, which pays off when the base is long and
+// diffed against many texts.  A Differ gets the plain texts.
+func (dmp *DMP) DiffPrepared(
+	base *PreparedBase, text string, checkLines bool,
+) []Diff {
+	if dmp.Differ != nil {
+		return dmp.DiffMainRunes(base.text, []rune(text), checkLines)
+	}
+	e := *dmp
+	e.base = base
+	return e.diffMainRunes(
+		base.text, []rune(text), checkLines, deadline(e.DiffTimeout),
+	)
+}
+
+// offset returns where s starts in the base if s is a slice of the base
+// text itself, as the pieces of the base cut by diffRun are.
+func (b *PreparedBase) offset(s []rune) (int, bool) {
+	i := len(b.text) - cap(s)
+	if len(s) == 0 || i < 0 || i >= len(b.text) || &b.text[i] != &s[0] {
+		return 0, false
+	}
+	return i, true
+}
+
+// occurrences returns the sorted offsets of pattern within the size runes
+// of the base starting at off, relative to off.
+func (b *PreparedBase) occurrences(off, size int, pattern []rune) []int {
+	// The suffixes starting with pattern are a range of the array.
+	compare := func(i int) int {
+		s := b.text[b.sa[i]:]
+		for j, r := range pattern {
+			if j == len(s) || s[j] < r {
+				return -1
+			} else if s[j] > r {
+				return 1
+			}
+		}
+		return 0
+	}
+	lo := sort.Search(len(b.sa), func(i int) bool { return compare(i) >= 0 })
+	hi := sort.Search(len(b.sa), func(i int) bool { return compare(i) > 0 })
+	found := []int{}
+	for _, p := range b.sa[lo:hi] {
+		if int(p) >= off && int(p)+len(pattern) <= off+size {
+			found = append(found, int(p)-off)
+		}
+	}
+	sort.Ints(found)
+	return found
+}
+
+// runesIndexAll returns the sorted offsets of pattern within target, using
+// the index of the prepared base when target is a piece of it.
+func (dmp *DMP) runesIndexAll(target, pattern []rune) []int {
+	if dmp.base != nil && len(pattern) > 0 {
+		if off, ok := dmp.base.offset(target); ok {
+			return dmp.base.occurrences(off, len(target), pattern)
+		}
+	}
+	found := []int{}
+	for j := runesIndex(target, pattern); j != -1; {
+		found = append(found, j)
+		j = runesIndexOf(target, pattern, j+1)
+	}
+	return found
+}
+
+// runesIndex is the package-level runesIndex, using the index of the
+// prepared base when target is a piece of it.
+func (dmp *DMP) runesIndex(target, pattern []rune) int {
+	if dmp.base != nil && len(pattern) > 0 {
+		if off, ok := dmp.base.offset(target); ok {
+			if found := dmp.base.occurrences(
+				off, len(target), pattern,
+			); len(found) > 0 {
+				return found[0]
+			}
+			return -1
+		}
+	}
+	return runesIndex(target, pattern)
+}
@@ -0,0 +1,100 @@
+package dmp
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPreparedBaseIndex(t *testing.T) {
+	base := NewPreparedBase("banana bandana é banana")
+	assert.Equal(t, "banana bandana é banana", base.String(), "")
+	suffixes := make([]string, len(base.sa))
+	for i, p := range base.sa {
+		suffixes[i] = string(base.text[p:])
+	}
+	assert.True(t, sort.StringsAreSorted(suffixes), "")
+
+	for _, pattern := range []string{"ana", "ban", "é b", "x", "a"} {
+		for off := 0; off < len(base.text); off += 5 {
+			piece := base.text[off:]
+			assert.Equal(t,
+				runesIndex(piece, []rune(pattern)),
+				New().runesIndex(piece, []rune(pattern)), pattern)
+			e := New()
+			e.base = base
+			assert.Equal(t,
+				New().runesIndexAll(piece, []rune(pattern)),
+				e.runesIndexAll(piece, []rune(pattern)), pattern)
+			assert.Equal(t,
+				runesIndex(piece[:len(piece)/2], []rune(pattern)),
+				e.runesIndex(piece[:len(piece)/2], []rune(pattern)),
+				pattern)
+		}
+	}
+	// Copies of the base are not looked up in the index.
+	_, ok := base.offset([]rune(base.String()))
+	assert.False(t, ok, "")
+	_, ok = NewPreparedBase("").offset(nil)
+	assert.False(t, ok, "")
+}
+
+func TestDiffPrepared(t *testing.T) {
+	dmp := New()
+	// Without a deadline, so the diffs compared do not depend on timing.
+	dmp.DiffTimeout = 0
+	text := readFile("speedtest1.txt", t)
+	base := NewPreparedBase(text)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		// Cut, duplicate and alter pieces of the base.
+		start := rnd.Intn(len(text) / 2)
+		end := start + rnd.Intn(len(text)-start)
+		other := text[start:end]
+		switch i % 4 {
+		case 1:
+			other = strings.Replace(other, "the", "a", -1)
+		case 2:
+			other = other + text[:rnd.Intn(len(text))]
+		case 3:
+			other = "Preface\n" + other + "\nThe end.\n"
+		}
+		assert.Equal(t,
+			dmp.DiffMain(text, other, false),
+			dmp.DiffPrepared(base, other, false), "")
+	}
+	assert.Equal(t,
+		dmp.DiffMain(text, text[100:]+"!", true),
+		dmp.DiffPrepared(base, text[100:]+"!", true), "")
+}
+
+func benchmarkDiffBase(b *testing.B, prepared bool) {
+	dmp := New()
+	text := strings.Repeat(readFile("speedtest1.txt", b), 4)
+	base := NewPreparedBase(text)
+	others := []string{}
+	for i := 0; i < 10; i++ {
+		others = append(others, "<"+text[i*100:len(text)-i*100]+">")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, other := range others {
+			if prepared {
+				dmp.DiffPrepared(base, other, false)
+				continue
+			}
+			dmp.DiffMain(text, other, false)
+		}
+	}
+}
+
+func Benchmark_DiffBase(b *testing.B) {
+	benchmarkDiffBase(b, false)
+}
+
+func Benchmark_DiffPrepared(b *testing.B) {
+	benchmarkDiffBase(b, true)
+}
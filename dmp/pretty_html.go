@@ -3,32 +3,186 @@ package dmp
 import (
 	"bytes"
 	"html"
+	"html/template"
+	"io"
 	"strings"
 )
 
-// DiffPrettyHtml converts a []Diff into a pretty HTML report.
+// HtmlOptions controls the HTML produced by DiffWriteHtml.
+type HtmlOptions struct {
+	// HidePilcrows renders line breaks as a plain <br> instead of
+	// "&para;<br>".
+	HidePilcrows bool
+
+	// Accessible emits markup that does not rely on color alone: edits
+	// carry ARIA roles, a visually hidden "inserted" or "deleted" label for
+	// screen readers, and are underlined or struck through.  Pilcrows are
+	// hidden from screen readers.
+	Accessible bool
+
+	// Theme gives the inline styles and markers (nil for ThemeDefault).
+	Theme *Theme
+}
+
+// srOnly hides an element visually while keeping it for screen readers.
+const srOnly = "position:absolute;width:1px;height:1px;overflow:hidden;" +
+	"clip:rect(0 0 0 0);white-space:nowrap;"
+
+// HtmlFormatter renders diffs as HTML.  The zero value renders like
+// DiffPrettyHtml, with inline styles; Classes replaces them with CSS
+// classes, as a Content-Security-Policy without 'unsafe-inline' requires.
+type HtmlFormatter struct {
+	Options HtmlOptions
+
+	// Classes, if not nil, holds the CSS class of the element of each
+	// operation, and no inline styles are written.  Operations without a
+	// class get a bare element.
+	Classes map[Operation]string
+
+	// SrOnlyClass is the class of the screen reader labels of
+	// Options.Accessible when Classes is set.  Defaults to "sr-only".
+	SrOnlyClass string
+
+	// Tags overrides the element of each operation, such as "mark" for
+	// insertions.  The defaults are del, ins and span.
+	Tags map[Operation]string
+
+	// Template, if not nil, renders each diff instead, with an HtmlDiff as
+	// data.  The other fields only set the Tag and Class it gets.
+	Template *template.Template
+}
+
+// HtmlDiff is the data of HtmlFormatter.Template for one diff.
+type HtmlDiff struct {
+	Diff
+	// Tag and Class are those the formatter would give the element.
+	Tag   string
+	Class string
+}
+
+// DiffPrettyHtml converts a []Diff into a pretty HTML report.  Moved
+// blocks, see DiffDetectMoves, are shown in blue at both ends.
 // It is intended as an example from which to write one's own
 // display functions.
 func DiffPrettyHtml(diffs []Diff) string {
 	var buf bytes.Buffer
+	DiffWriteHtml(&buf, diffs, HtmlOptions{})
+	return buf.String()
+}
+
+// DiffWriteHtml writes the HTML report of DiffPrettyHtml to w one diff at a
+// time, so large reports need not be held in memory.  It returns the first
+// write error.
+func DiffWriteHtml(w io.Writer, diffs []Diff, opts HtmlOptions) error {
+	f := HtmlFormatter{Options: opts}
+	return f.Write(w, diffs)
+}
+
+// Format returns the HTML of diffs.
+func (f *HtmlFormatter) Format(diffs []Diff) string {
+	var buf bytes.Buffer
+	f.Write(&buf, diffs)
+	return buf.String()
+}
+
+// Write writes the HTML of diffs to w one diff at a time.  It returns the
+// first error.
+func (f *HtmlFormatter) Write(w io.Writer, diffs []Diff) error {
+	br := "&para;<br>"
+	if f.Options.HidePilcrows {
+		br = "<br>"
+	} else if f.Options.Accessible {
+		br = "<span aria-hidden=\"true\">&para;</span><br>"
+	}
+
 	for _, d := range diffs {
+		tag := f.Tags[d.Type]
+		if tag == "" {
+			tag = htmlTag(d.Type)
+		}
+		if f.Template != nil {
+			err := f.Template.Execute(w, HtmlDiff{d, tag, f.Classes[d.Type]})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		st := f.Options.Theme.orDefault().style(d.Type)
 		text := strings.Replace(
-			html.EscapeString(d.Text), "\n", "&para;<br>", -1,
+			html.EscapeString(st.Prefix+d.Text+st.Suffix), "\n", br, -1,
 		)
-		switch d.Type {
-		case DiffInsert:
-			buf.WriteString("<ins style=\"background:#e6ffe6;\">")
-			buf.WriteString(text)
-			buf.WriteString("</ins>")
-		case DiffDelete:
-			buf.WriteString("<del style=\"background:#ffe6e6;\">")
-			buf.WriteString(text)
-			buf.WriteString("</del>")
-		case DiffEqual:
-			buf.WriteString("<span>")
-			buf.WriteString(text)
-			buf.WriteString("</span>")
+		open := "<" + tag
+		label := ""
+		if f.Options.Accessible && d.Type != DiffEqual {
+			open += " role=\"" + htmlRole(d.Type) + "\""
+			label = "<span style=\"" + srOnly + "\">"
+			if f.Classes != nil {
+				class := f.SrOnlyClass
+				if class == "" {
+					class = "sr-only"
+				}
+				label = "<span class=\"" + html.EscapeString(class) + "\">"
+			}
+			label += htmlLabel(d.Type) + ": </span>"
+		}
+		if f.Classes != nil {
+			if class := f.Classes[d.Type]; class != "" {
+				open += " class=\"" + html.EscapeString(class) + "\""
+			}
+		} else if style := htmlStyle(
+			st.CSS, d.Type, f.Options.Accessible,
+		); style != "" {
+			open += " style=\"" + style + "\""
+		}
+		open += ">" + label
+		if _, err := io.WriteString(w, open+text+"</"+tag+">"); err != nil {
+			return err
 		}
 	}
-	return buf.String()
+	return nil
+}
+
+// htmlTag returns the default element of op.
+func htmlTag(op Operation) string {
+	switch {
+	case op < 0:
+		return "del"
+	case op > 0:
+		return "ins"
+	}
+	return "span"
+}
+
+// htmlRole returns the ARIA role of an edit.
+func htmlRole(op Operation) string {
+	if op < 0 {
+		return "deletion"
+	}
+	return "insertion"
+}
+
+// htmlLabel returns the screen reader label of an edit.
+func htmlLabel(op Operation) string {
+	switch op {
+	case DiffDelete:
+		return "deleted"
+	case DiffMoveFrom:
+		return "moved away"
+	case DiffMove:
+		return "moved here"
+	}
+	return "inserted"
+}
+
+// htmlStyle returns the inline style of op given that of its theme, or ""
+// for none.
+func htmlStyle(style string, op Operation, accessible bool) string {
+	if !accessible || op == DiffEqual {
+		return style
+	}
+	if op < 0 {
+		return style + "text-decoration:line-through;"
+	}
+	return style + "text-decoration:underline;"
 }
@@ -0,0 +1,94 @@
+package dmp
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+// failWriter fails after n writes.
+type failWriter struct {
+	n int
+}
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if w.n == 0 {
+		return 0, errors.New("write failed")
+	}
+	w.n--
+	return len(p), nil
+}
+
+func TestDiffWriteHtml(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "a\n"},
+		{DiffDelete, "<B>b</B>"},
+		{DiffInsert, "c&d"}}
+
+	var buf bytes.Buffer
+	assert.Nil(t, DiffWriteHtml(&buf, diffs, HtmlOptions{}), "")
+	assert.Equal(t, DiffPrettyHtml(diffs), buf.String(), "")
+
+	buf.Reset()
+	assert.Nil(t, DiffWriteHtml(&buf, diffs, HtmlOptions{HidePilcrows: true}), "")
+	assert.Equal(t, "<span>a<br></span><del style=\"background:#ffe6e6;\">&lt;B&gt;b&lt;/B&gt;</del><ins style=\"background:#e6ffe6;\">c&amp;d</ins>",
+		buf.String(), "")
+
+	w := &failWriter{1}
+	assert.NotNil(t, DiffWriteHtml(w, diffs, HtmlOptions{}), "")
+	assert.Equal(t, 0, w.n, "Stops at the first error.")
+}
+
+func TestDiffWriteHtmlAccessible(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "a\n"},
+		{DiffDelete, "b"},
+		{DiffInsert, "c"}}
+
+	var buf bytes.Buffer
+	assert.Nil(t, DiffWriteHtml(&buf, diffs, HtmlOptions{Accessible: true}), "")
+	assert.Equal(t, "<span>a<span aria-hidden=\"true\">&para;</span><br></span>"+
+		"<del role=\"deletion\" style=\"background:#ffe6e6;text-decoration:line-through;\">"+
+		"<span style=\""+srOnly+"\">deleted: </span>b</del>"+
+		"<ins role=\"insertion\" style=\"background:#e6ffe6;text-decoration:underline;\">"+
+		"<span style=\""+srOnly+"\">inserted: </span>c</ins>",
+		buf.String(), "")
+
+	buf.Reset()
+	DiffWriteHtml(&buf, diffs[:1], HtmlOptions{Accessible: true, HidePilcrows: true})
+	assert.Equal(t, "<span>a<br></span>", buf.String(), "")
+}
+
+func TestHtmlFormatter(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "a\n"},
+		{DiffDelete, "<b>"},
+		{DiffInsert, "c"}}
+
+	var f HtmlFormatter
+	assert.Equal(t, DiffPrettyHtml(diffs), f.Format(diffs), "")
+
+	f = HtmlFormatter{
+		Classes: map[Operation]string{DiffDelete: "del", DiffInsert: "ins"},
+		Tags:    map[Operation]string{DiffInsert: "mark"},
+	}
+	assert.Equal(t, "<span>a&para;<br></span><del class=\"del\">&lt;b&gt;</del>"+
+		"<mark class=\"ins\">c</mark>", f.Format(diffs), "")
+
+	f.Options.Accessible = true
+	assert.Equal(t, "<del role=\"deletion\" class=\"del\">"+
+		"<span class=\"sr-only\">deleted: </span>&lt;b&gt;</del>",
+		f.Format(diffs[1:2]), "")
+
+	f.Template = template.Must(template.New("diff").Parse(
+		"<span class=\"{{.Class}}\" data-op=\"{{.Type}}\">{{.Text}}</span>"))
+	assert.Equal(t, "<span class=\"del\" data-op=\"-1\">&lt;b&gt;</span>"+
+		"<span class=\"ins\" data-op=\"1\">c</span>",
+		f.Format(diffs[1:]), "")
+
+	w := &failWriter{0}
+	assert.NotNil(t, f.Write(w, diffs), "")
+}
@@ -0,0 +1,82 @@
+package dmp
+
+import (
+	"bytes"
+	"io"
+)
+
+// ANSI escape codes of the colors of ThemeDefault.
+const (
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+	ansiReset = "\x1b[0m"
+)
+
+// TextOptions controls the text produced by DiffWriteText.
+type TextOptions struct {
+	// NoColor leaves out the ANSI color codes, for output that does not go
+	// to a terminal.
+	NoColor bool
+
+	// DeletePrefix and DeleteSuffix enclose deleted text, and InsertPrefix
+	// and InsertSuffix inserted text.  With NoColor, empty ones default to
+	// "[-", "-]", "{+" and "+}", as in git diff --word-diff.
+	DeletePrefix string
+	DeleteSuffix string
+	InsertPrefix string
+	InsertSuffix string
+
+	// Theme gives the colors and markers (nil for ThemeDefault).  The
+	// fields above override its markers.
+	Theme *Theme
+}
+
+// DiffPrettyText converts a []Diff into text for a terminal, with
+// deletions in red and insertions in green, the way DiffPrettyHtml does
+// for a browser.  Moved blocks, see DiffDetectMoves, are cyan.
+func DiffPrettyText(diffs []Diff) string {
+	var buf bytes.Buffer
+	DiffWriteText(&buf, diffs, TextOptions{})
+	return buf.String()
+}
+
+// DiffWriteText writes the text of DiffPrettyText to w one diff at a time.
+// It returns the first write error.
+func DiffWriteText(w io.Writer, diffs []Diff, opts TextOptions) error {
+	theme := opts.Theme.orDefault()
+	for _, d := range diffs {
+		st := theme.style(d.Type)
+		open, close := st.Prefix, st.Suffix
+		if d.Type < 0 {
+			open = defaultString(opts.DeletePrefix, open)
+			close = defaultString(opts.DeleteSuffix, close)
+			if opts.NoColor {
+				open = defaultString(open, "[-")
+				close = defaultString(close, "-]")
+			}
+		} else if d.Type > 0 {
+			open = defaultString(opts.InsertPrefix, open)
+			close = defaultString(opts.InsertSuffix, close)
+			if opts.NoColor {
+				open = defaultString(open, "{+")
+				close = defaultString(close, "+}")
+			}
+		}
+		if st.ANSI != "" && !opts.NoColor {
+			open, close = st.ANSI+open, close+ansiReset
+		}
+		if _, err := io.WriteString(w, open+d.Text+close); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// defaultString returns s, or def if s is empty.
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,31 @@
+package dmp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffPrettyText(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "a\n"},
+		{DiffDelete, "b"},
+		{DiffInsert, "c&d"},
+		{DiffMove, "e"},
+	}
+	assert.Equal(t, "a\n\x1b[31mb\x1b[0m\x1b[32mc&d\x1b[0m\x1b[36me\x1b[0m",
+		DiffPrettyText(diffs), "")
+
+	var buf bytes.Buffer
+	DiffWriteText(&buf, diffs, TextOptions{NoColor: true})
+	assert.Equal(t, "a\n[-b-]{+c&d+}{+e+}", buf.String(), "")
+
+	buf.Reset()
+	DiffWriteText(&buf, diffs, TextOptions{DeletePrefix: "-", InsertPrefix: "+"})
+	assert.Equal(t, "a\n\x1b[31m-b\x1b[0m\x1b[32m+c&d\x1b[0m\x1b[36m+e\x1b[0m",
+		buf.String(), "")
+
+	err := DiffWriteText(&failWriter{n: 1}, diffs, TextOptions{})
+	assert.Equal(t, "write failed", err.Error(), "")
+}
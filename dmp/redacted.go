@@ -0,0 +1,238 @@
+package dmp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RedactedPatch is a patch whose context has been reduced to its length,
+// and optionally a hash, so that the structure of an edit can be sent
+// without the surrounding text of the document.  The deleted and inserted
+// texts are kept.  Without context, a redacted patch can only be applied
+// at its exact offset, see ApplyRedacted.
+type RedactedPatch struct {
+	diffs   []redactedDiff
+	start1  int
+	start2  int
+	length1 int
+	length2 int
+}
+
+// redactedDiff is a diff of a RedactedPatch.  Equalities have no text.
+type redactedDiff struct {
+	Diff
+	// Length of an equality.
+	length int
+	// Hash of the text of an equality, or "".
+	hash string
+}
+
+// contextHash hashes an equality for a redacted patch.  Short equalities
+// can be guessed from their hash, so hashes only let the receiver check
+// that a patch is applied to the right text.
+func contextHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// PatchRedact strips the equalities of patches down to their lengths, and
+// their hashes if hash is set.
+func PatchRedact(ps []Patch, hash bool) []RedactedPatch {
+	rps := make([]RedactedPatch, len(ps))
+	for i, p := range ps {
+		rp := RedactedPatch{
+			start1: p.start1, start2: p.start2,
+			length1: p.length1, length2: p.length2,
+		}
+		for _, d := range p.diffs {
+			if d.Type != DiffEqual {
+				rp.diffs = append(rp.diffs, redactedDiff{Diff: d})
+				continue
+			}
+			rd := redactedDiff{Diff: Diff{Type: DiffEqual}}
+			rd.length = len(d.Text)
+			if hash {
+				rd.hash = contextHash(d.Text)
+			}
+			rp.diffs = append(rp.diffs, rd)
+		}
+		rps[i] = rp
+	}
+	return rps
+}
+
+// String emulates Patch.String, with equalities written as "=length" or
+// "=length:hash".
+func (p *RedactedPatch) String() string {
+	coords1 := patchCoords(p.start1, p.length1)
+	coords2 := patchCoords(p.start2, p.length2)
+
+	var text bytes.Buffer
+	text.WriteString("@@ -" + coords1 + " +" + coords2 + " @@\n")
+	for _, d := range p.diffs {
+		switch d.Type {
+		case DiffInsert:
+			text.WriteString("+")
+		case DiffDelete:
+			text.WriteString("-")
+		case DiffEqual:
+			text.WriteString("=" + strconv.Itoa(d.length))
+			if d.hash != "" {
+				text.WriteString(":" + d.hash)
+			}
+			text.WriteString("\n")
+			continue
+		}
+		text.WriteString(
+			strings.Replace(url.QueryEscape(d.Text), "+", " ", -1),
+		)
+		text.WriteString("\n")
+	}
+	return unescaper.Replace(text.String())
+}
+
+// RedactedPatchesToText returns the textual representation of redacted
+// patches.
+func RedactedPatchesToText(ps []RedactedPatch) string {
+	var text bytes.Buffer
+	for _, p := range ps {
+		text.WriteString(p.String())
+	}
+	return text.String()
+}
+
+// RedactedPatchesFromText parses the output of RedactedPatchesToText.
+func RedactedPatchesFromText(text string) ([]RedactedPatch, error) {
+	ps := []RedactedPatch{}
+	if len(text) == 0 {
+		return ps, nil
+	}
+	lines := strings.Split(text, "\n")
+	for i := 0; i < len(lines); {
+		header, ok := parsePatchHeader(lines[i])
+		if !ok {
+			return ps, fmt.Errorf("Invalid patch string: %s", lines[i])
+		}
+		headerLine := i + 1
+		p := RedactedPatch{
+			start1: header.start1, start2: header.start2,
+			length1: header.length1, length2: header.length2,
+		}
+		length1, length2 := 0, 0
+		for i++; i < len(lines) && !strings.HasPrefix(lines[i], "@"); i++ {
+			line := lines[i]
+			if line == "" {
+				continue
+			}
+			var d redactedDiff
+			switch line[0] {
+			case '=':
+				n, hash := line[1:], ""
+				if j := strings.IndexByte(n, ':'); j >= 0 {
+					n, hash = n[:j], n[j+1:]
+				}
+				length, err := strconv.Atoi(n)
+				if err != nil || length < 0 {
+					return ps, fmt.Errorf(
+						"Invalid context length on line %d: %q", i+1, line,
+					)
+				}
+				d = redactedDiff{Diff{DiffEqual, ""}, length, hash}
+				length1 += length
+				length2 += length
+			case '-', '+':
+				body := strings.Replace(line[1:], "+", "%2b", -1)
+				body, _ = url.QueryUnescape(body)
+				d.Diff = Diff{DiffDelete, body}
+				if line[0] == '+' {
+					d.Type = DiffInsert
+					length2 += len(body)
+				} else {
+					length1 += len(body)
+				}
+			default:
+				return ps, fmt.Errorf(
+					"Invalid patch mode %q in: %q", line[0], line,
+				)
+			}
+			p.diffs = append(p.diffs, d)
+		}
+		if length1 != p.length1 || length2 != p.length2 {
+			return ps, fmt.Errorf(
+				"Patch length mismatch on line %d: "+
+					"header -%d +%d, body -%d +%d",
+				headerLine, p.length1, p.length2, length1, length2,
+			)
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+// ApplyRedacted applies redacted patches to s.  As they have no context
+// to search for, each patch must be at its exact offset: the text it
+// deletes must be there, and its hashed equalities must match.  Returns
+// the patched text and which patches were applied.
+func (dmp *DMP) ApplyRedacted(ps []RedactedPatch, s string) (
+	string, []bool,
+) {
+	applied := make([]bool, len(ps))
+	// Offset of the text due to the patches that failed.
+	delta := 0
+	for i, p := range ps {
+		start := p.start2 + delta
+		if !redactedMatch(p, s, start) {
+			delta -= p.length2 - p.length1
+			continue
+		}
+		var text bytes.Buffer
+		pos := start
+		for _, d := range p.diffs {
+			switch d.Type {
+			case DiffEqual:
+				text.WriteString(s[pos : pos+d.length])
+				pos += d.length
+			case DiffDelete:
+				pos += len(d.Text)
+			case DiffInsert:
+				text.WriteString(d.Text)
+			}
+		}
+		s = s[:start] + text.String() + s[pos:]
+		applied[i] = true
+	}
+	return s, applied
+}
+
+// redactedMatch tells whether p can be applied to s at start.
+func redactedMatch(p RedactedPatch, s string, start int) bool {
+	// Lengths come from the patch text; compare them against what is
+	// left of s so that they cannot overflow.
+	if start < 0 || start > len(s) || p.length1 > len(s)-start {
+		return false
+	}
+	pos := start
+	for _, d := range p.diffs {
+		switch d.Type {
+		case DiffEqual:
+			if d.length > len(s)-pos {
+				return false
+			}
+			if d.hash != "" && contextHash(s[pos:pos+d.length]) != d.hash {
+				return false
+			}
+			pos += d.length
+		case DiffDelete:
+			if !strings.HasPrefix(s[pos:], d.Text) {
+				return false
+			}
+			pos += len(d.Text)
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package dmp
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchRedact(t *testing.T) {
+	dmp := New()
+	text1 := "The quick brown fox jumps over the lazy dog."
+	text2 := "That quick brown fox jumped over a lazy dog."
+	ps := dmp.PatchMake(text1, text2)
+
+	rps := PatchRedact(ps, false)
+	text := RedactedPatchesToText(rps)
+	assert.Equal(t, "@@ -1,11 +1,12 @@\n=2\n-e\n+at\n=8\n"+
+		"@@ -22,18 +22,17 @@\n=4\n-s\n+ed\n=6\n-the\n+a\n=4\n", text, "")
+	assert.False(t, strings.Contains(text, "quick"), "")
+
+	parsed, err := RedactedPatchesFromText(text)
+	assert.Nil(t, err, "")
+	assert.Equal(t, rps, parsed, "")
+	patched, applied := dmp.ApplyRedacted(parsed, text1)
+	assert.Equal(t, text2, patched, "")
+	assert.Equal(t, []bool{true, true}, applied, "")
+
+	// Without context, patches only apply at their exact offsets.
+	_, applied = dmp.ApplyRedacted(parsed, "A prefix. "+text1)
+	assert.Equal(t, []bool{false, false}, applied, "")
+
+	// Hashed context is checked.
+	rps = PatchRedact(ps, true)
+	parsed, err = RedactedPatchesFromText(RedactedPatchesToText(rps))
+	assert.Nil(t, err, "")
+	assert.Equal(t, rps, parsed, "")
+	patched, applied = dmp.ApplyRedacted(parsed, text1)
+	assert.Equal(t, text2, patched, "")
+	assert.Equal(t, []bool{true, true}, applied, "")
+	text3 := "The quick brown fox jumps over the lady dog."
+	patched, applied = dmp.ApplyRedacted(parsed, text3)
+	assert.Equal(t, "That quick brown fox jumps over the lady dog.", patched, "")
+	assert.Equal(t, []bool{true, false}, applied, "")
+
+	_, err = RedactedPatchesFromText("@@ -1,3 +1,3 @@\n=x\n")
+	assert.NotNil(t, err, "")
+	_, err = RedactedPatchesFromText("@@ -1,3 +1,4 @@\n=3\n")
+	assert.NotNil(t, err, "")
+	_, err = RedactedPatchesFromText("@@ -1,3 +1,3 @@\n 3\n")
+	assert.NotNil(t, err, "")
+	_, err = RedactedPatchesFromText(
+		"@@ -1,99999999999999999999 +1,3 @@\n=3\n")
+	assert.NotNil(t, err, "")
+
+	// Lengths too long for the text are not applied.
+	n := strconv.Itoa(math.MaxInt)
+	parsed, err = RedactedPatchesFromText(
+		"@@ -3," + n + " +3," + n + " @@\n=" + n + "\n")
+	assert.Nil(t, err, "")
+	patched, applied = dmp.ApplyRedacted(parsed, text1)
+	assert.Equal(t, text1, patched, "")
+	assert.Equal(t, []bool{false}, applied, "")
+}
@@ -0,0 +1,81 @@
+package dmp
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// reDiffMargin is the number of unchanged bytes around an edit that
+// ReDiff diffs again with it, so that the diff of the edit can merge with
+// the changes nearby.
+const reDiffMargin = 64
+
+// ReDiff updates diffs from text1 to text2 after text2[r.Start:r.End] was
+// replaced by newText, as when a text being compared with its saved
+// version is edited.  Only the part of the diff around the edit is
+// computed again, up to the nearest equalities, so small edits to large
+// texts are quick to follow.  r must be a range of text2 that does not
+// split a rune.
+//
+// The result is a diff from text1 to the edited text2, but not always the
+// one DiffMain would make of the whole texts, and it is not cleaned up.
+func (dmp *DMP) ReDiff(
+	prevDiffs []Diff, r Range, newText string,
+) ([]Diff, error) {
+	prevDiffs = withoutMoves(prevDiffs)
+	text1, text2 := DiffText1(prevDiffs), DiffText2(prevDiffs)
+	if r.Start < 0 || r.Start > r.End || r.End > len(text2) {
+		return nil, fmt.Errorf("ReDiff range %d-%d out of text2 of length %d",
+			r.Start, r.End, len(text2))
+	}
+	if !runeBoundary(text2, r.Start) || !runeBoundary(text2, r.End) {
+		return nil, fmt.Errorf("ReDiff range %d-%d splits a rune",
+			r.Start, r.End)
+	}
+	// Cut the diffs between runes only, so that DiffMain gets whole ones.
+	lo, hi := max(r.Start-reDiffMargin, 0), r.End+reDiffMargin
+	for !runeBoundary(text2, lo) {
+		lo--
+	}
+	for hi < len(text2) && !runeBoundary(text2, hi) {
+		hi++
+	}
+
+	// Cut the diffs inside the last equality starting at or before lo, and
+	// inside the first one ending at or after hi.
+	var prefix, suffix []Diff
+	p1, p2 := 0, 0
+	q1, q2 := len(text1), len(text2)
+	pos1, pos2 := 0, 0
+	cut := false
+	for i, d := range prevDiffs {
+		n := len(d.Text)
+		if d.Type == DiffEqual && pos2 <= lo {
+			k := min(lo-pos2, n)
+			prefix = append(prevDiffs[:i:i], Diff{DiffEqual, d.Text[:k]})
+			p1, p2 = pos1+k, pos2+k
+		}
+		if d.Type == DiffEqual && pos2+n >= hi && !cut {
+			k := max(hi-pos2, 0)
+			suffix = diffPrepend(Diff{DiffEqual, d.Text[k:]}, prevDiffs[i+1:])
+			q1, q2 = pos1+k, pos2+k
+			cut = true
+		}
+		if d.Type <= 0 {
+			pos1 += n
+		}
+		if d.Type >= 0 {
+			pos2 += n
+		}
+	}
+
+	region2 := text2[p2:r.Start] + newText + text2[r.End:q2]
+	diffs := append(prefix, dmp.exact().DiffMain(text1[p1:q1], region2, true)...)
+	return diffCleanupMerge(append(diffs, suffix...)), nil
+}
+
+// runeBoundary reports whether i is a valid offset of s that does not
+// split a rune.
+func runeBoundary(s string, i int) bool {
+	return i >= 0 && i <= len(s) && (i == len(s) || utf8.RuneStart(s[i]))
+}
@@ -0,0 +1,102 @@
+package dmp
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestReDiff(t *testing.T) {
+	dmp := New()
+	text1 := strings.Repeat("The quick brown fox jumps over the lazy dog.\n", 50)
+	text2 := strings.Replace(text1, "lazy", "sleepy", 3)
+	diffs := dmp.DiffMain(text1, text2, false)
+
+	// An edit far from the other changes.
+	at := strings.LastIndex(text2, "brown")
+	edited := text2[:at] + "red" + text2[at+len("brown"):]
+	rediffs, err := dmp.ReDiff(diffs, Range{at, at + len("brown")}, "red")
+	assert.Nil(t, err, "")
+	assert.Equal(t, text1, DiffText1(rediffs), "")
+	assert.Equal(t, edited, DiffText2(rediffs), "")
+	assert.Equal(t,
+		DiffLevenshtein(diffs)+
+			DiffLevenshtein(dmp.DiffMain("brown", "red", false)),
+		DiffLevenshtein(rediffs), "")
+	// The diffs before the edit are kept.
+	assert.Equal(t, diffs[:6], rediffs[:6], "")
+
+	// Undoing a change.
+	at = strings.Index(text2, "sleepy")
+	rediffs, _ = dmp.ReDiff(diffs, Range{at, at + len("sleepy")}, "lazy")
+	assert.Equal(t,
+		strings.Replace(text2, "sleepy", "lazy", 1), DiffText2(rediffs), "")
+	assert.Equal(t,
+		DiffLevenshtein(diffs)-
+			DiffLevenshtein(dmp.DiffMain("lazy", "sleepy", false)),
+		DiffLevenshtein(rediffs), "")
+
+	// Edits at the ends and of empty texts.
+	rediffs, _ = dmp.ReDiff(diffs, Range{0, 0}, ">")
+	assert.Equal(t, ">"+text2, DiffText2(rediffs), "")
+	rediffs, _ = dmp.ReDiff(diffs, Range{len(text2), len(text2)}, "<")
+	assert.Equal(t, text2+"<", DiffText2(rediffs), "")
+	rediffs, _ = dmp.ReDiff([]Diff{}, Range{0, 0}, "abc")
+	assert.Equal(t, []Diff{{DiffInsert, "abc"}}, rediffs, "")
+	rediffs, _ = dmp.ReDiff([]Diff{{DiffEqual, "abc"}}, Range{0, 3}, "")
+	assert.Equal(t, []Diff{{DiffDelete, "abc"}}, rediffs, "")
+
+	// Bad ranges.
+	_, err = dmp.ReDiff(diffs, Range{0, len(text2) + 1}, "")
+	assert.NotNil(t, err, "Past the end.")
+	_, err = dmp.ReDiff(diffs, Range{2, 1}, "")
+	assert.NotNil(t, err, "Reversed.")
+	_, err = dmp.ReDiff([]Diff{{DiffEqual, "é"}}, Range{1, 2}, "")
+	assert.NotNil(t, err, "Splits a rune.")
+
+	// Random edits keep the texts right.
+	r := rand.New(rand.NewSource(1))
+	text := func(n int) string {
+		b := make([]byte, r.Intn(n))
+		for i := range b {
+			b[i] = "ab \n"[r.Intn(4)]
+		}
+		return string(b)
+	}
+	for i := 0; i < 300; i++ {
+		text1, text2 := text(400), text(400)
+		diffs := dmp.DiffMain(text1, text2, false)
+		start := r.Intn(len(text2) + 1)
+		end := start + r.Intn(len(text2)-start+1)
+		s := text(10)
+		rediffs, err := dmp.ReDiff(diffs, Range{start, end}, s)
+		assert.Nil(t, err, "")
+		assert.Equal(t, text1, DiffText1(rediffs), "")
+		assert.Equal(t, text2[:start]+s+text2[end:], DiffText2(rediffs), "")
+	}
+
+	// Random edits of non-ASCII texts cut the diffs between runes.
+	runes := []string{"a", "é", "語", "😀", " ", "\n"}
+	utext := func(n int) string {
+		var b strings.Builder
+		for i := r.Intn(n); i > 0; i-- {
+			b.WriteString(runes[r.Intn(len(runes))])
+		}
+		return b.String()
+	}
+	for i := 0; i < 300; i++ {
+		text1, text2 := utext(200), utext(200)
+		diffs := dmp.DiffMain(text1, text2, false)
+		rs := []rune(text2)
+		start := r.Intn(len(rs) + 1)
+		end := start + r.Intn(len(rs)-start+1)
+		lo, hi := len(string(rs[:start])), len(string(rs[:end]))
+		s := utext(5)
+		rediffs, err := dmp.ReDiff(diffs, Range{lo, hi}, s)
+		assert.Nil(t, err, "")
+		assert.Equal(t, text1, DiffText1(rediffs), "")
+		assert.Equal(t, text2[:lo]+s+text2[hi:], DiffText2(rediffs), "")
+	}
+}
@@ -0,0 +1,43 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffSemanticOptions(t *testing.T) {
+	dmp := New()
+	diffs := []Diff{
+		{DiffDelete, "abcd"}, {DiffEqual, "xyz"}, {DiffInsert, "efgh"},
+	}
+	assert.Equal(t, []Diff{{DiffDelete, "abcdxyz"}, {DiffInsert, "xyzefgh"}},
+		dmp.DiffCleanupSemantic(diffs), "")
+	dmp.DiffSemanticMinEquality = 3
+	assert.Equal(t, diffs, dmp.DiffCleanupSemantic(diffs), "")
+	dmp.DiffSemanticMinEquality = 4
+	assert.Equal(t, []Diff{{DiffDelete, "abcdxyz"}, {DiffInsert, "xyzefgh"}},
+		dmp.DiffCleanupSemantic(diffs), "")
+
+	dmp = New()
+	diffs = []Diff{{DiffDelete, "abcxxx"}, {DiffInsert, "xxxdef"}}
+	assert.Equal(t, []Diff{
+		{DiffDelete, "abc"}, {DiffEqual, "xxx"}, {DiffInsert, "def"},
+	}, dmp.DiffCleanupSemantic(diffs), "")
+	dmp.DiffSemanticNoOverlaps = true
+	assert.Equal(t, diffs, dmp.DiffCleanupSemantic(diffs), "")
+
+	diffs = []Diff{
+		{DiffEqual, "今日は。明"}, {DiffInsert, "日は晴れ。明"}, {DiffEqual, "日は雨。"},
+	}
+	assert.Equal(t, []Diff{
+		{DiffEqual, "今日は。"}, {DiffInsert, "明日は晴れ。"}, {DiffEqual, "明日は雨。"},
+	}, dmp.DiffCleanupSemanticLossless(diffs), "")
+	// Without a preference, edits shift as far right as they can.
+	dmp.DiffSemanticScore = func(one, two string) int { return 0 }
+	shifted := []Diff{
+		{DiffEqual, "今日は。明日は"}, {DiffInsert, "晴れ。明日は"}, {DiffEqual, "雨。"},
+	}
+	assert.Equal(t, shifted, dmp.DiffCleanupSemanticLossless(diffs), "")
+	assert.Equal(t, shifted, dmp.DiffCleanupSemantic(diffs), "")
+}
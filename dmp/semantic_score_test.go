@@ -0,0 +1,50 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestSemanticBoundaryScore(t *testing.T) {
+	for _, c := range []struct {
+		one, two string
+		score    int
+	}{
+		{"", "a", 6},
+		{"a\n\n", "b", 5},
+		{"a", "\r\n\r\nb", 5},
+		{"a ", "b", 4},
+		{"a.", " b", 3},
+		{"今日は。", "明日", 3},
+		{"Привет ", "мир", 2},
+		{"a", "-b", 1},
+		{"日本", "語", 1},
+		{"مرحبا", "بك", 0},
+		{"cafe", "́", 0},
+		{"👩‍", "💻", 0},
+		{"👍", "\U0001F3FD", 0},
+		{"👍", "👍", 1},
+	} {
+		assert.Equal(t, c.score, SemanticBoundaryScore(c.one, c.two),
+			c.one+"|"+c.two)
+	}
+}
+
+func TestDiffCleanupSemanticLosslessUnicode(t *testing.T) {
+	// Edits slide to the spaces of Cyrillic words.
+	diffs := []Diff{
+		{DiffEqual, "Привет б"}, {DiffInsert, "ольшой б"}, {DiffEqual, "елый мир"},
+	}
+	assert.Equal(t, []Diff{
+		{DiffEqual, "Привет "}, {DiffInsert, "большой "}, {DiffEqual, "белый мир"},
+	}, DiffCleanupSemanticLossless(diffs), "")
+
+	// Emoji sequences stay whole.
+	diffs = []Diff{
+		{DiffEqual, "a👩"}, {DiffInsert, "‍💻 b👩"}, {DiffEqual, "‍💻"},
+	}
+	assert.Equal(t, []Diff{
+		{DiffEqual, "a👩‍💻"}, {DiffInsert, " b👩‍💻"},
+	}, DiffCleanupSemanticLossless(diffs), "")
+}
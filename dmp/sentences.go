@@ -0,0 +1,136 @@
+package dmp
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// splitSentences cuts text after each run of sentence terminators (., !
+// and ?) that is followed by whitespace.  The whitespace belongs to the
+// sentence it ends.
+func splitSentences(text string) []string {
+	sentences := []string{}
+	start := 0
+	for i := 0; i < len(text); {
+		if !isSentenceEnd(text[i]) {
+			_, size := utf8.DecodeRuneInString(text[i:])
+			i += size
+			continue
+		}
+		for i < len(text) && isSentenceEnd(text[i]) {
+			i++
+		}
+		end := i
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if !unicode.IsSpace(r) {
+				break
+			}
+			end += size
+		}
+		if end > i || end == len(text) {
+			sentences = append(sentences, text[start:end])
+			start = end
+		}
+		i = end
+	}
+	if start < len(text) {
+		sentences = append(sentences, text[start:])
+	}
+	return sentences
+}
+
+// splitWords cuts text into runs of letters and digits, runs of
+// whitespace and single other characters.
+func splitWords(text string) []string {
+	class := func(r rune) int {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 0
+	}
+	words := []string{}
+	start, prev := 0, -1
+	for i, r := range text {
+		c := class(r)
+		if i > start && (c != prev || c == 0) {
+			words = append(words, text[start:i])
+			start = i
+		}
+		prev = c
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+func isSentenceEnd(c byte) bool {
+	return c == '.' || c == '!' || c == '?'
+}
+
+// DiffSentencesToChars is like DiffLinesToChars, but each Unicode
+// character of the result represents one sentence: text up to a run of
+// ., ! or ? followed by whitespace, including the whitespace.  Diffing the
+// results gives a coarse sentence-level diff of prose, which
+// DiffCharsToLines turns back into text.
+func DiffSentencesToChars(s1, s2 string) (string, string, []string) {
+	return DiffTokensToChars(SentenceTokenizer, s1, s2)
+}
+
+// DiffSentences diffs prose for review: sentences are compared as a
+// whole first, then the words of changed sentences are diffed, so edits
+// line up with words instead of scattered characters.
+func (dmp *DMP) DiffSentences(text1, text2 string) []Diff {
+	end := deadline(dmp.DiffTimeout)
+	coarse := dmp.diffTokens(SentenceTokenizer, text1, text2, end)
+	diffs := []Diff{}
+	var deleted, inserted string
+	flush := func() {
+		diffs = append(diffs,
+			dmp.diffTokens(WordTokenizer, deleted, inserted, end)...)
+		deleted, inserted = "", ""
+	}
+	for _, d := range coarse {
+		switch d.Type {
+		case DiffDelete:
+			deleted += d.Text
+		case DiffInsert:
+			inserted += d.Text
+		case DiffEqual:
+			flush()
+			diffs = append(diffs, d)
+		}
+	}
+	flush()
+	return diffCleanupMerge(diffs)
+}
+
+// DiffCriticMarkup renders diffs as track changes in CriticMarkup:
+// {++inserted++}, {--deleted--} and {~~old~>new~~} for a deletion followed
+// by an insertion.
+func DiffCriticMarkup(diffs []Diff) string {
+	diffs = withoutMoves(diffs)
+	var buf bytes.Buffer
+	for i := 0; i < len(diffs); i++ {
+		d := diffs[i]
+		switch d.Type {
+		case DiffEqual:
+			buf.WriteString(d.Text)
+		case DiffInsert:
+			buf.WriteString("{++" + d.Text + "++}")
+		case DiffDelete:
+			if i+1 < len(diffs) && diffs[i+1].Type == DiffInsert {
+				buf.WriteString("{~~" + d.Text + "~>" + diffs[i+1].Text + "~~}")
+				i++
+				continue
+			}
+			buf.WriteString("{--" + d.Text + "--}")
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,83 @@
+package dmp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestSplitSentences(t *testing.T) {
+	assert.Equal(t, []string{"One. ", "Two!? ", "Three...\n", "3.5 stays"},
+		splitSentences("One. Two!? Three...\n3.5 stays"), "")
+	assert.Equal(t, []string{"End."}, splitSentences("End."), "")
+	assert.Equal(t, []string{}, splitSentences(""), "")
+}
+
+func TestDiffSentencesToChars(t *testing.T) {
+	text1 := "The cat sat. It was happy. The end."
+	text2 := "The cat sat. It was sad. The end."
+	chars1, chars2, sentences := DiffSentencesToChars(text1, text2)
+	assert.Equal(t, "\x01\x02\x03", chars1, "")
+	assert.Equal(t, "\x01\x04\x03", chars2, "")
+	assert.Equal(t, []string{
+		"", "The cat sat. ", "It was happy. ", "The end.", "It was sad. ",
+	}, sentences, "")
+
+	dmp := New()
+	diffs := DiffCharsToLines(dmp.DiffMain(chars1, chars2, false), sentences)
+	assert.Equal(t, []Diff{
+		{DiffEqual, "The cat sat. "},
+		{DiffDelete, "It was happy. "},
+		{DiffInsert, "It was sad. "},
+		{DiffEqual, "The end."},
+	}, diffs, "")
+}
+
+func TestSplitWords(t *testing.T) {
+	assert.Equal(t,
+		[]string{"Don", "'", "t", "  ", "stop", ",", " ", "née", "!", "!", "\n"},
+		splitWords("Don't  stop, née!!\n"), "")
+	assert.Equal(t, []string{}, splitWords(""), "")
+}
+
+func TestDiffSentences(t *testing.T) {
+	dmp := New()
+	text1 := "The cat sat on the mat. It was happy. The end."
+	text2 := "The cat sat on the mat. It was very sad. The end."
+	diffs := dmp.DiffSentences(text1, text2)
+	assert.Equal(t, []Diff{
+		{DiffEqual, "The cat sat on the mat. It was "},
+		{DiffDelete, "happy"},
+		{DiffInsert, "very sad"},
+		{DiffEqual, ". The end."},
+	}, diffs, "")
+	assert.Equal(t,
+		"The cat sat on the mat. It was {~~happy~>very sad~~}. The end.",
+		DiffCriticMarkup(diffs), "")
+
+	diffs = dmp.DiffSentences("One. Two.", "One. Two. Three.")
+	assert.Equal(t, "One. Two.{++ Three.++}", DiffCriticMarkup(diffs), "")
+	assert.Equal(t, "{--gone--} kept",
+		DiffCriticMarkup([]Diff{{DiffDelete, "gone"}, {DiffEqual, " kept"}}),
+		"")
+}
+
+func TestDiffSentencesManyTokens(t *testing.T) {
+	var b1, b2 strings.Builder
+	for i := 0; i < 0xE000; i++ {
+		fmt.Fprintf(&b1, "Sentence %d. ", i)
+		if i == 0xD900 {
+			b2.WriteString("Sentence changed. ")
+		} else {
+			fmt.Fprintf(&b2, "Sentence %d. ", i)
+		}
+	}
+	dmp := New()
+	dmp.DiffTimeout = 0
+	diffs := dmp.DiffSentences(b1.String(), b2.String())
+	assert.Equal(t, b1.String(), DiffText1(diffs), "")
+	assert.Equal(t, b2.String(), DiffText2(diffs), "")
+	assert.Equal(t, 4, len(diffs), "")
+}
@@ -0,0 +1,140 @@
+package dmp
+
+import (
+	"time"
+)
+
+// SliceDiff is one operation of a diff of two slices: a run of elements
+// that is inserted, deleted or kept.
+type SliceDiff[T comparable] struct {
+	Type  Operation
+	Items []T
+}
+
+// DiffSlices finds the differences between two slices of any comparable
+// type, such as tokens, lines or hashes of syntax tree nodes, with the
+// default settings.  Unlike DiffLinesToRunes, it needs no mapping of the
+// elements to runes, so the number of distinct elements is not limited.
+func DiffSlices[T comparable](a, b []T) []SliceDiff[T] {
+	return DiffSlicesWith(New(), a, b)
+}
+
+// DiffSlicesWith is like DiffSlices, with the DiffTimeout, DiffAlgorithm
+// and DiffBand of dmp.  The Items of the diffs are copies, which do not
+// share memory with a and b.
+func DiffSlicesWith[T comparable](dmp *DMP, a, b []T) []SliceDiff[T] {
+	return diffSlices(dmp, a, b, deadline(dmp.DiffTimeout))
+}
+
+// diffSlices diffs a and b like diffRun, splitting them with the middle
+// snake of Myers's algorithm and pushing the halves back on an explicit
+// stack.
+func diffSlices[T comparable](
+	dmp *DMP, a, b []T, deadline time.Time,
+) []SliceDiff[T] {
+	type task struct{ a, b []T }
+	diffs := []SliceDiff[T]{}
+	stack := []task{{a, b}}
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		n := 0
+		for n < len(t.a) && n < len(t.b) && t.a[n] == t.b[n] {
+			n++
+		}
+		diffs = appendSliceDiff(diffs, DiffEqual, t.a[:n])
+		a, b := t.a[n:], t.b[n:]
+		m := 0
+		for m < len(a) && m < len(b) &&
+			a[len(a)-1-m] == b[len(b)-1-m] {
+			m++
+		}
+		suffix := a[len(a)-m:]
+		a, b = a[:len(a)-m], b[:len(b)-m]
+
+		if len(a) > 0 && len(b) > 0 {
+			if x, y, ok := sliceSplitPoint(dmp, a, b, deadline); ok {
+				// The suffix is pushed as two equal slices, whose common
+				// prefix is all of them.
+				stack = append(stack,
+					task{suffix, suffix},
+					task{a[x:], b[y:]},
+					task{a[:x], b[:y]},
+				)
+				continue
+			}
+		}
+		diffs = appendSliceDiff(diffs, DiffDelete, a)
+		diffs = appendSliceDiff(diffs, DiffInsert, b)
+		diffs = appendSliceDiff(diffs, DiffEqual, suffix)
+	}
+	return sliceCleanupMerge(diffs)
+}
+
+// sliceSplitPoint returns where to split a and b like diffSplitPoint,
+// which needs two elements in each, or where the one element of a or b is
+// in the other.
+func sliceSplitPoint[T comparable](
+	dmp *DMP, a, b []T, deadline time.Time,
+) (int, int, bool) {
+	if len(a) == 1 {
+		for y, e := range b {
+			if e == a[0] {
+				return 0, y, true
+			}
+		}
+		return 0, 0, false
+	}
+	if len(b) == 1 {
+		for x, e := range a {
+			if e == b[0] {
+				return x, 0, true
+			}
+		}
+		return 0, 0, false
+	}
+	return diffSplitPoint(dmp, a, b, deadline)
+}
+
+// appendSliceDiff appends a diff of items to diffs, unless items is empty.
+func appendSliceDiff[T comparable](
+	diffs []SliceDiff[T], op Operation, items []T,
+) []SliceDiff[T] {
+	if len(items) == 0 {
+		return diffs
+	}
+	return append(diffs, SliceDiff[T]{op, items})
+}
+
+// sliceCleanupMerge copies diffs, joining adjacent equalities, and the
+// edits between two equalities into one deletion followed by one
+// insertion.
+func sliceCleanupMerge[T comparable](diffs []SliceDiff[T]) []SliceDiff[T] {
+	ret := []SliceDiff[T]{}
+	var deleted, inserted []T
+	flush := func() {
+		ret = appendSliceDiff(ret, DiffDelete, deleted)
+		ret = appendSliceDiff(ret, DiffInsert, inserted)
+		deleted, inserted = nil, nil
+	}
+	for _, d := range diffs {
+		switch d.Type {
+		case DiffDelete:
+			deleted = append(deleted, d.Items...)
+		case DiffInsert:
+			inserted = append(inserted, d.Items...)
+		case DiffEqual:
+			flush()
+			if last := len(ret) - 1; last >= 0 && ret[last].Type == DiffEqual {
+				ret[last].Items = append(ret[last].Items, d.Items...)
+			} else {
+				ret = append(ret, SliceDiff[T]{
+					DiffEqual, append([]T(nil), d.Items...),
+				})
+			}
+		}
+	}
+	flush()
+	return ret
+}
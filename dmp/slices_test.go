@@ -0,0 +1,81 @@
+package dmp
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffSlices(t *testing.T) {
+	a := strings.Split("the quick brown fox jumps over the lazy dog", " ")
+	b := strings.Split("the quick red fox jumps over the dog", " ")
+	assert.Equal(t, []SliceDiff[string]{
+		{DiffEqual, []string{"the", "quick"}},
+		{DiffDelete, []string{"brown"}},
+		{DiffInsert, []string{"red"}},
+		{DiffEqual, []string{"fox", "jumps", "over", "the"}},
+		{DiffDelete, []string{"lazy"}},
+		{DiffEqual, []string{"dog"}},
+	}, DiffSlices(a, b), "")
+
+	assert.Equal(t, []SliceDiff[int]{}, DiffSlices([]int{}, nil), "")
+	assert.Equal(t,
+		[]SliceDiff[int]{{DiffInsert, []int{1, 2}}},
+		DiffSlices(nil, []int{1, 2}), "")
+	assert.Equal(t,
+		[]SliceDiff[int]{{DiffDelete, []int{1}}, {DiffInsert, []int{2}}},
+		DiffSlices([]int{1}, []int{2}), "")
+
+	// Any comparable type will do.
+	type node struct {
+		kind string
+		hash uint64
+	}
+	x, y, z := node{"call", 1}, node{"ident", 2}, node{"call", 3}
+	assert.Equal(t, []SliceDiff[node]{
+		{DiffEqual, []node{x}},
+		{DiffInsert, []node{z}},
+		{DiffEqual, []node{y}},
+	}, DiffSlices([]node{x, y}, []node{x, z, y}), "")
+
+	// The diffs do not share memory with the inputs.
+	in := []int{1, 2, 3}
+	diffs := DiffSlices(in, []int{1, 2, 4})
+	in[0] = 9
+	assert.Equal(t, []int{1, 2}, diffs[0].Items, "")
+
+	// The diffs are as long as those of the texts.
+	r := rand.New(rand.NewSource(1))
+	text := func() string {
+		b := make([]byte, r.Intn(40))
+		for i := range b {
+			b[i] = "abcd"[r.Intn(4)]
+		}
+		return string(b)
+	}
+	dmp := New()
+	dmp.DiffTimeout = 0
+	for i := 0; i < 500; i++ {
+		s1, s2 := text(), text()
+		diffs := DiffSlicesWith(dmp, []byte(s1), []byte(s2))
+		var text1, text2 []byte
+		edits := 0
+		for _, d := range diffs {
+			if d.Type <= 0 {
+				text1 = append(text1, d.Items...)
+			}
+			if d.Type >= 0 {
+				text2 = append(text2, d.Items...)
+			}
+			if d.Type != DiffEqual {
+				edits += len(d.Items)
+			}
+		}
+		assert.Equal(t, s1, string(text1), "")
+		assert.Equal(t, s2, string(text2), "")
+		assert.Equal(t,
+			diffEdits(dmp.DiffMain(s1, s2, false)), edits, s1+" "+s2)
+	}
+}
@@ -0,0 +1,139 @@
+package dmp
+
+import (
+	"unicode/utf8"
+)
+
+// Span is a range of a text, as offsets in bytes and in runes.
+type Span struct {
+	Start     int
+	End       int
+	RuneStart int
+	RuneEnd   int
+}
+
+// SpanDiff is a Diff that refers to its text in the two texts diffed
+// instead of holding a copy.  Text1 is the range of text1 it deletes or
+// keeps and Text2 the range of text2 it inserts or keeps; an insertion has
+// an empty Text1 at the point of insertion, and a deletion an empty Text2.
+type SpanDiff struct {
+	Type  Operation
+	Text1 Span
+	Text2 Span
+}
+
+// Text returns the text of d, taken from text1 or text2.
+func (d SpanDiff) Text(text1, text2 string) string {
+	if d.Type == DiffInsert {
+		return text2[d.Text2.Start:d.Text2.End]
+	}
+	return text1[d.Text1.Start:d.Text1.End]
+}
+
+// DiffMainSpans diffs s1 and s2 as DiffMain with checklines, and returns
+// the diffs as spans of the texts.  The result keeps no copies of the
+// texts, which for large inputs would take several times their memory
+// once the diffs are retained, and its offsets map onto lines and columns
+// of the texts directly.  With NormalizeEOL or the DiffIgnore... settings,
+// the spans of an equality in s1 and s2 may differ in length.
+func (dmp *DMP) DiffMainSpans(s1, s2 string) []SpanDiff {
+	// The texts compared, and the offsets in s1 and s2 of their bytes, as
+	// line endings and ignored differences are rewritten in each.
+	c1, c2 := s1, s2
+	orig1, orig2 := identityOffset, identityOffset
+	e := dmp
+	if dmp.NormalizeEOL || dmp.ignoring() {
+		t1, t2 := dmp.comparedText(s1), dmp.comparedText(s2)
+		c1, c2, orig1, orig2 = t1.text, t2.text, t1.origOffset, t2.origOffset
+		e = dmp.exact()
+	}
+	diffs := e.DiffMain(c1, c2, true)
+
+	spans := make([]SpanDiff, 0, len(diffs)+1)
+	var pos1, pos2 Span
+	if c1 == "" && s1 != "" {
+		// All of s1 is ignored, and equal to all of s2 that is, as in
+		// DiffMain.
+		end1, end2 := spanTo(s1, pos1, len(s1)), pos2
+		if c2 == "" {
+			end2 = spanTo(s2, pos2, len(s2))
+		}
+		spans = append(spans, SpanDiff{DiffEqual,
+			Span{0, end1.End, 0, end1.RuneEnd},
+			Span{0, end2.End, 0, end2.RuneEnd},
+		})
+		pos1, pos2 = end1, end2
+	}
+	// Offsets in the texts compared.
+	at1, at2 := 0, 0
+	for _, d := range diffs {
+		if d.Type != DiffInsert {
+			at1 = dmp.offsetAfter(c1, at1, d.Text)
+		}
+		if d.Type != DiffDelete {
+			at2 = dmp.offsetAfter(c2, at2, d.Text)
+		}
+		end1 := spanTo(s1, pos1, max(orig1(at1), pos1.End))
+		end2 := spanTo(s2, pos2, max(orig2(at2), pos2.End))
+		spans = append(spans, SpanDiff{
+			d.Type,
+			Span{pos1.End, end1.End, pos1.RuneEnd, end1.RuneEnd},
+			Span{pos2.End, end2.End, pos2.RuneEnd, end2.RuneEnd},
+		})
+		pos1, pos2 = end1, end2
+	}
+	return spans
+}
+
+func identityOffset(i int) int {
+	return i
+}
+
+// comparedText returns the text DiffMain compares for s, with the line
+// endings and differences rewritten as the settings of dmp ask, and the
+// offsets in s of its bytes.
+func (dmp *DMP) comparedText(s string) *ignoredText {
+	if !dmp.NormalizeEOL {
+		return dmp.ignoreText(s)
+	}
+	t := eolText(s)
+	if !dmp.ignoring() {
+		return t
+	}
+	it := dmp.ignoreText(t.text)
+	for i, o := range it.offsets {
+		it.offsets[i] = t.origOffset(o)
+	}
+	it.orig = t.orig
+	return it
+}
+
+// offsetAfter returns the offset in s after i of the text that a diff of
+// the given text covers.  The diff text may differ from s where s is not
+// valid UTF-8, but not in its number of runes, or of bytes with DiffBytes.
+func (dmp *DMP) offsetAfter(s string, i int, text string) int {
+	if dmp.DiffBytes {
+		return i + len(text)
+	}
+	for n := utf8.RuneCountInString(text); n > 0; n-- {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return i
+}
+
+// spanTo returns pos extended to offset end of s.
+func spanTo(s string, pos Span, end int) Span {
+	pos.RuneEnd += utf8.RuneCountInString(s[pos.End:end])
+	pos.End = end
+	return pos
+}
+
+// SpansToDiffs turns spans of text1 and text2 back into diffs.
+func SpansToDiffs(spans []SpanDiff, text1, text2 string) []Diff {
+	diffs := make([]Diff, len(spans))
+	for i, d := range spans {
+		diffs[i] = Diff{d.Type, d.Text(text1, text2)}
+	}
+	return diffs
+}
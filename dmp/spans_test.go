@@ -0,0 +1,81 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffMainSpans(t *testing.T) {
+	dmp := New()
+	s1, s2 := "héllo wörld", "héllo, big wörld"
+	spans := dmp.DiffMainSpans(s1, s2)
+	assert.Equal(t, []SpanDiff{
+		{DiffEqual, Span{0, 6, 0, 5}, Span{0, 6, 0, 5}},
+		{DiffInsert, Span{6, 6, 5, 5}, Span{6, 11, 5, 10}},
+		{DiffEqual, Span{6, 13, 5, 11}, Span{11, 18, 10, 16}},
+	}, spans, "")
+	assert.Equal(t, dmp.DiffMain(s1, s2, true), SpansToDiffs(spans, s1, s2), "")
+
+	// Offsets count the bytes of the texts, even where they are not valid
+	// UTF-8.
+	s1, s2 = "a\xffb", "a\xffc"
+	spans = dmp.DiffMainSpans(s1, s2)
+	assert.Equal(t, []SpanDiff{
+		{DiffEqual, Span{0, 2, 0, 2}, Span{0, 2, 0, 2}},
+		{DiffDelete, Span{2, 3, 2, 3}, Span{2, 2, 2, 2}},
+		{DiffInsert, Span{3, 3, 3, 3}, Span{2, 3, 2, 3}},
+	}, spans, "")
+	assert.Equal(t, []Diff{
+		{DiffEqual, "a\xff"}, {DiffDelete, "b"}, {DiffInsert, "c"},
+	}, SpansToDiffs(spans, s1, s2), "")
+
+	dmp.DiffBytes = true
+	spans = dmp.DiffMainSpans("é", "è")
+	assert.Equal(t, SpanDiff{DiffEqual, Span{0, 1, 0, 1}, Span{0, 1, 0, 1}},
+		spans[0], "")
+	assert.Equal(t, 0, len(dmp.DiffMainSpans("", "")), "")
+}
+
+func TestDiffMainSpansRewritten(t *testing.T) {
+	// Equalities take their own length in each text.
+	dmp := New()
+	dmp.DiffIgnoreWhitespace = true
+	s1, s2 := "a  b\nc d\n", "a b\nc X\n"
+	spans := dmp.DiffMainSpans(s1, s2)
+	assert.Equal(t, []SpanDiff{
+		{DiffEqual, Span{0, 7, 0, 7}, Span{0, 6, 0, 6}},
+		{DiffDelete, Span{7, 8, 7, 8}, Span{6, 6, 6, 6}},
+		{DiffInsert, Span{8, 8, 8, 8}, Span{6, 7, 6, 7}},
+		{DiffEqual, Span{8, 9, 8, 9}, Span{7, 8, 7, 8}},
+	}, spans, "")
+	assert.Equal(t, dmp.DiffMain(s1, s2, true), SpansToDiffs(spans, s1, s2), "")
+	assert.Equal(t, "X", spans[2].Text(s1, s2), "")
+
+	dmp = New()
+	dmp.NormalizeEOL = true
+	s1, s2 = "one\r\ntwö\r\nthree\r\n", "one\ntwö\nTHREE\n"
+	spans = dmp.DiffMainSpans(s1, s2)
+	assert.Equal(t, []SpanDiff{
+		{DiffEqual, Span{0, 11, 0, 10}, Span{0, 9, 0, 8}},
+		{DiffDelete, Span{11, 16, 10, 15}, Span{9, 9, 8, 8}},
+		{DiffInsert, Span{16, 16, 15, 15}, Span{9, 14, 8, 13}},
+		{DiffEqual, Span{16, 18, 15, 17}, Span{14, 15, 13, 14}},
+	}, spans, "")
+	assert.Equal(t, []Diff{
+		{DiffEqual, "one\r\ntwö\r\n"}, {DiffDelete, "three"},
+		{DiffInsert, "THREE"}, {DiffEqual, "\r\n"},
+	}, SpansToDiffs(spans, s1, s2), "")
+
+	// Both together.
+	dmp.DiffIgnoreWhitespace = true
+	assert.Equal(t, []SpanDiff{
+		{DiffEqual, Span{0, 2, 0, 2}, Span{0, 1, 0, 1}},
+		{DiffInsert, Span{2, 2, 2, 2}, Span{1, 2, 1, 2}},
+	}, dmp.DiffMainSpans(" \r", "\nX"), "")
+	// All of text1 is ignored.
+	assert.Equal(t, []SpanDiff{
+		{DiffEqual, Span{0, 2, 0, 2}, Span{0, 0, 0, 0}},
+		{DiffInsert, Span{2, 2, 2, 2}, Span{0, 1, 0, 1}},
+	}, dmp.DiffMainSpans("  ", "X"), "")
+}
@@ -0,0 +1,40 @@
+package dmp
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// longLineBreaks are the characters after which an overlong line may be
+// split.
+const longLineBreaks = ",;{}[]"
+
+// splitLongLine cuts a line longer than maxLen bytes into tokens.  A token
+// ends after one of longLineBreaks, or after maxLen bytes if no break is
+// found sooner.  Cuts never fall inside a UTF-8 sequence.
+func splitLongLine(line string, maxLen int) []string {
+	if maxLen <= 0 || len(line) <= maxLen {
+		return []string{line}
+	}
+
+	tokens := []string{}
+	for len(line) > maxLen {
+		end := strings.IndexAny(line[:maxLen], longLineBreaks) + 1
+		if end == 0 {
+			end = maxLen
+			for end > 0 && !utf8.RuneStart(line[end]) {
+				end--
+			}
+			if end == 0 {
+				// A single rune wider than maxLen.
+				_, end = utf8.DecodeRuneInString(line)
+			}
+		}
+		tokens = append(tokens, line[:end])
+		line = line[end:]
+	}
+	if len(line) != 0 {
+		tokens = append(tokens, line)
+	}
+	return tokens
+}
@@ -0,0 +1,48 @@
+package dmp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestSplitLongLine(t *testing.T) {
+	for _, test := range []struct {
+		line   string
+		maxLen int
+		want   []string
+	}{
+		{"abc", 0, []string{"abc"}},
+		{"abc", 3, []string{"abc"}},
+		{"abcdefgh", 3, []string{"abc", "def", "gh"}},
+		{`{"a":1,"b":2}`, 5, []string{"{", `"a":1`, `,`, `"b":2`, "}"}},
+		{`[1,2,3,4]`, 4, []string{"[", "1,", "2,", "3,4]"}},
+		{"日本語", 4, []string{"日", "本", "語"}},
+		{"日本語", 2, []string{"日", "本", "語"}},
+	} {
+		assert.Equal(t, test.want, splitLongLine(test.line, test.maxLen), test.line)
+	}
+}
+
+func TestDiffMaxLineLength(t *testing.T) {
+	dmp := New()
+	dmp.DiffMaxLineLength = 16
+	var b1, b2 []string
+	for i := 0; i < 200; i++ {
+		b1 = append(b1, `{"id":1,"name":"x"}`)
+		b2 = append(b2, `{"id":1,"name":"x"}`)
+	}
+	b2[100] = `{"id":2,"name":"y"}`
+	text1 := strings.Join(b1, ",")
+	text2 := strings.Join(b2, ",")
+
+	diffs := dmp.DiffMain(text1, text2, true)
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.Equal(t, 2, DiffLevenshtein(diffs), "")
+
+	r1, r2, lines := diffTokensToIndices(lineTokenizer{16}, text1, text2)
+	assert.True(t, len(r1) > 500 && len(r2) > 500, "Long line split into tokens.")
+	assert.True(t, len(lines) < 20, "Tokens are shared.")
+}
@@ -0,0 +1,147 @@
+package dmp
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Stats counts what diffs insert, delete and keep.  Characters are runes;
+// words are runs of letters and digits.  A line or word of text1 counts as
+// deleted if the diffs delete any of it, and one of text2 as inserted if
+// they insert any of it; the other lines and words of text2 are equal.  A
+// changed line thus counts as both deleted and inserted, as in diff -u.
+type Stats struct {
+	InsertedChars int
+	DeletedChars  int
+	EqualChars    int
+
+	InsertedLines int
+	DeletedLines  int
+	EqualLines    int
+
+	InsertedWords int
+	DeletedWords  int
+	EqualWords    int
+
+	// Ratio measures the similarity of the texts from 0 to 1, like
+	// ratio() of Python's difflib: twice the equal characters over the
+	// characters of both texts, or 1 if both are empty.
+	Ratio float64
+}
+
+// DiffStats computes the Stats of diffs.
+func DiffStats(diffs []Diff) Stats {
+	diffs = withoutMoves(diffs)
+	var st Stats
+	// Changed bytes of text1 and text2.
+	var changed1, changed2 []bool
+	for _, d := range diffs {
+		n := utf8.RuneCountInString(d.Text)
+		switch d.Type {
+		case DiffInsert:
+			st.InsertedChars += n
+			changed2 = appendBools(changed2, true, len(d.Text))
+		case DiffDelete:
+			st.DeletedChars += n
+			changed1 = appendBools(changed1, true, len(d.Text))
+		case DiffEqual:
+			st.EqualChars += n
+			changed1 = appendBools(changed1, false, len(d.Text))
+			changed2 = appendBools(changed2, false, len(d.Text))
+		}
+	}
+	text1, text2 := DiffText1(diffs), DiffText2(diffs)
+
+	pos := 0
+	for _, l := range splitLinesAfter(text1) {
+		if anyTrue(changed1[pos : pos+len(l)]) {
+			st.DeletedLines++
+		}
+		pos += len(l)
+	}
+	pos = 0
+	for _, l := range splitLinesAfter(text2) {
+		if anyTrue(changed2[pos : pos+len(l)]) {
+			st.InsertedLines++
+		} else {
+			st.EqualLines++
+		}
+		pos += len(l)
+	}
+	for _, w := range wordRanges(text1) {
+		if anyTrue(changed1[w.Start:w.End]) {
+			st.DeletedWords++
+		}
+	}
+	for _, w := range wordRanges(text2) {
+		if anyTrue(changed2[w.Start:w.End]) {
+			st.InsertedWords++
+		} else {
+			st.EqualWords++
+		}
+	}
+
+	st.Ratio = 1
+	if total := 2*st.EqualChars + st.InsertedChars + st.DeletedChars; total > 0 {
+		st.Ratio = float64(2*st.EqualChars) / float64(total)
+	}
+	return st
+}
+
+// appendBools appends n copies of b to bs.
+func appendBools(bs []bool, b bool, n int) []bool {
+	for ; n > 0; n-- {
+		bs = append(bs, b)
+	}
+	return bs
+}
+
+// anyTrue tells whether any of bs is true.
+func anyTrue(bs []bool) bool {
+	for _, b := range bs {
+		if b {
+			return true
+		}
+	}
+	return false
+}
+
+// wordRanges returns the byte ranges of the runs of letters and digits of
+// text.
+func wordRanges(text string) []Range {
+	ret := []Range{}
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if word && start == -1 {
+			start = i
+		} else if !word && start != -1 {
+			ret = append(ret, Range{start, i})
+			start = -1
+		}
+	}
+	if start != -1 {
+		ret = append(ret, Range{start, len(text)})
+	}
+	return ret
+}
+
+// Similarity diffs s1 and s2, within DiffTimeout, and scores how similar
+// they are from 0 to 1: the bytes the diff keeps equal over those bytes plus
+// the diff's Levenshtein distance.  The distance of a diff never exceeds
+// the bytes it changes, so the score stays in range.  Two empty texts are
+// identical.
+func (dmp *DMP) Similarity(s1, s2 string) float64 {
+	diffs := dmp.DiffMain(s1, s2, false)
+	equal := 0
+	for _, d := range diffs {
+		if d.Type == DiffEqual {
+			equal += len(d.Text)
+		}
+	}
+	n := equal + DiffLevenshtein(diffs)
+	if n == 0 {
+		return 1
+	}
+	return float64(equal) / float64(n)
+}
@@ -0,0 +1,53 @@
+package dmp
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffStats(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "The quick\nbrown "},
+		{DiffDelete, "fox"},
+		{DiffInsert, "dog"},
+		{DiffEqual, "\njumps.\n"},
+		{DiffInsert, "The end.\n"},
+	}
+	assert.Equal(t, Stats{
+		InsertedChars: 12, DeletedChars: 3, EqualChars: 24,
+		InsertedLines: 2, DeletedLines: 1, EqualLines: 2,
+		InsertedWords: 3, DeletedWords: 1, EqualWords: 4,
+		Ratio: 48.0 / 63,
+	}, DiffStats(diffs), "")
+
+	// Characters are runes.
+	st := DiffStats([]Diff{{DiffEqual, "é"}, {DiffInsert, "è"}})
+	assert.Equal(t, 1, st.EqualChars, "")
+	assert.Equal(t, 1, st.InsertedChars, "")
+	assert.Equal(t, 2.0/3, st.Ratio, "")
+
+	assert.Equal(t, Stats{Ratio: 1}, DiffStats(nil), "")
+}
+
+func TestSimilarity(t *testing.T) {
+	dmp := New()
+	assert.Equal(t, 1.0, dmp.Similarity("", ""), "")
+	assert.Equal(t, 1.0, dmp.Similarity("kitten", "kitten"), "")
+	assert.Equal(t, 0.0, dmp.Similarity("abc", ""), "")
+	assert.Equal(t, 0.0, dmp.Similarity("abc", "xyz"), "")
+	// Two substitutions and an insertion.
+	assert.Equal(t, 1-3.0/7, dmp.Similarity("kitten", "sitting"), "")
+	// The distance of [-abc, =Q, +xyz] is longer than either text.
+	assert.Equal(t, 1.0/7, dmp.Similarity("abcQ", "Qxyz"), "")
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		diffs := randomDiffs(r, 6)
+		s1, s2 := DiffText1(diffs), DiffText2(diffs)
+		sim := dmp.Similarity(s1, s2)
+		assert.True(t, sim >= 0 && sim <= 1, fmt.Sprintf("%q %q: %v", s1, s2, sim))
+	}
+}
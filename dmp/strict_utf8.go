@@ -0,0 +1,118 @@
+package dmp
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// InvalidUTF8Error reports an input that is not valid UTF-8, in StrictUTF8
+// mode.
+type InvalidUTF8Error struct {
+	// Which input: "text1", "text2", "text", "diffs" or "patch N".
+	Input string
+	// Byte offset of the first invalid sequence in the input.
+	Offset int
+}
+
+func (e *InvalidUTF8Error) Error() string {
+	return fmt.Sprintf("Invalid UTF-8 in %s at byte %d", e.Input, e.Offset)
+}
+
+// checkUTF8 returns an *InvalidUTF8Error if s is not valid UTF-8.
+func checkUTF8(input, s string) error {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			return &InvalidUTF8Error{Input: input, Offset: i}
+		}
+		i += size
+	}
+	return nil
+}
+
+// checkTexts checks pairs of input names and texts in StrictUTF8 mode.
+func (dmp *DMP) checkTexts(inputs ...string) error {
+	if !dmp.StrictUTF8 {
+		return nil
+	}
+	for i := 0; i+1 < len(inputs); i += 2 {
+		if err := checkUTF8(inputs[i], inputs[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDiffs checks the texts of diffs in StrictUTF8 mode.  Offsets are
+// relative to the text of each diff.
+func (dmp *DMP) checkDiffs(input string, diffs []Diff) error {
+	if !dmp.StrictUTF8 {
+		return nil
+	}
+	for _, d := range diffs {
+		if err := checkUTF8(input, d.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPatches checks the texts of patches in StrictUTF8 mode.
+func (dmp *DMP) checkPatches(ps []Patch) error {
+	if !dmp.StrictUTF8 {
+		return nil
+	}
+	for i, p := range ps {
+		err := dmp.checkDiffs(fmt.Sprintf("patch %d", i), p.diffs)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffMainChecked is like DiffMain, but fails on invalid UTF-8 in
+// StrictUTF8 mode.
+func (dmp *DMP) DiffMainChecked(s1, s2 string, checkLines bool) (
+	[]Diff, error,
+) {
+	if err := dmp.checkTexts("text1", s1, "text2", s2); err != nil {
+		return nil, err
+	}
+	return dmp.DiffMain(s1, s2, checkLines), nil
+}
+
+// PatchMakeChecked is like PatchMake, but fails on invalid UTF-8 in the
+// texts or diffs in StrictUTF8 mode.
+func (dmp *DMP) PatchMakeChecked(opt ...interface{}) ([]Patch, error) {
+	for i, o := range opt {
+		var err error
+		switch o := o.(type) {
+		case string:
+			err = dmp.checkTexts(fmt.Sprintf("text%d", i+1), o)
+		case []Diff:
+			err = dmp.checkDiffs("diffs", o)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dmp.PatchMake(opt...), nil
+}
+
+// ApplyChecked is like Apply, but fails on malformed patches, see
+// PatchValidate, and on invalid UTF-8 in the patches or the text in
+// StrictUTF8 mode.
+func (dmp *DMP) ApplyChecked(ps []Patch, s string) (string, []bool, error) {
+	if err := PatchValidate(ps); err != nil {
+		return s, nil, err
+	}
+	if err := dmp.checkPatches(ps); err != nil {
+		return s, nil, err
+	}
+	if err := dmp.checkTexts("text", s); err != nil {
+		return s, nil, err
+	}
+	patched, applied := dmp.Apply(ps, s)
+	return patched, applied, nil
+}
@@ -0,0 +1,45 @@
+package dmp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestStrictUTF8(t *testing.T) {
+	dmp := New()
+	bad := "ab\xffcd"
+
+	// Without StrictUTF8, invalid bytes are diffed as U+FFFD.
+	diffs, err := dmp.DiffMainChecked(bad, "abcd", false)
+	assert.Nil(t, err, "")
+	assert.Equal(t, dmp.DiffMain(bad, "abcd", false), diffs, "")
+
+	dmp.StrictUTF8 = true
+	_, err = dmp.DiffMainChecked("abcd", bad, false)
+	assert.Equal(t, &InvalidUTF8Error{Input: "text2", Offset: 2}, err, "")
+	assert.Equal(t, "Invalid UTF-8 in text2 at byte 2", err.Error(), "")
+	_, err = dmp.DiffMainContext(context.Background(), bad, "é", false)
+	assert.Equal(t, &InvalidUTF8Error{Input: "text1", Offset: 2}, err, "")
+	diffs, err = dmp.DiffMainChecked("é", "è", false)
+	assert.Nil(t, err, "")
+	assert.Equal(t, dmp.DiffMain("é", "è", false), diffs, "")
+
+	_, err = dmp.PatchMakeChecked("abc", bad)
+	assert.Equal(t, &InvalidUTF8Error{Input: "text2", Offset: 2}, err, "")
+	_, err = dmp.PatchMakeChecked([]Diff{{DiffInsert, "é\xe9"}})
+	assert.Equal(t, &InvalidUTF8Error{Input: "diffs", Offset: 2}, err, "")
+	ps, err := dmp.PatchMakeChecked("The cat", "The hat")
+	assert.Nil(t, err, "")
+
+	_, _, err = dmp.ApplyChecked(ps, "The cat\xc3")
+	assert.Equal(t, &InvalidUTF8Error{Input: "text", Offset: 7}, err, "")
+	badPatches := append(ps, Patch{diffs: []Diff{{DiffInsert, "\x80"}}, length2: 1})
+	_, _, err = dmp.ApplyContext(context.Background(), badPatches, "x")
+	assert.Equal(t, &InvalidUTF8Error{Input: "patch 1", Offset: 0}, err, "")
+	patched, applied, err := dmp.ApplyChecked(ps, "The cat")
+	assert.Nil(t, err, "")
+	assert.Equal(t, "The hat", patched, "")
+	assert.Equal(t, []bool{true}, applied, "")
+}
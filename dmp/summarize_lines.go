@@ -0,0 +1,127 @@
+package dmp
+
+import (
+	"bytes"
+	"strings"
+)
+
+// LineChangeKind tells how a line changed.
+type LineChangeKind int8
+
+const (
+	LineAdded LineChangeKind = iota
+	LineDeleted
+	LineModified
+)
+
+// LineChange describes one changed line.  Line numbers are 1-based; OldLine
+// is 0 for added lines and NewLine is 0 for deleted lines.  Texts do not
+// include the line break.
+type LineChange struct {
+	Kind    LineChangeKind
+	OldLine int
+	NewLine int
+	OldText string
+	NewText string
+}
+
+// SummarizeByLines aggregates a character diff into per-line change
+// records, in document order.  Lines are compared within the regions
+// delimited by line breaks the diff leaves unchanged, so the alignment of
+// the character diff is kept.
+func SummarizeByLines(diffs []Diff) []LineChange {
+	diffs = withoutMoves(diffs)
+	ret := []LineChange{}
+	oldLine, newLine := 1, 1
+	var old, cur bytes.Buffer
+	changed := false
+
+	flush := func() {
+		if changed {
+			ret = append(ret, summarizeRegion(
+				old.String(), cur.String(), oldLine, newLine,
+			)...)
+		}
+		oldLine += strings.Count(old.String(), "\n")
+		newLine += strings.Count(cur.String(), "\n")
+		old.Reset()
+		cur.Reset()
+		changed = false
+	}
+
+	for _, d := range diffs {
+		switch d.Type {
+		case DiffEqual:
+			text := d.Text
+			for {
+				i := strings.Index(text, "\n")
+				if i == -1 {
+					break
+				}
+				old.WriteString(text[:i+1])
+				cur.WriteString(text[:i+1])
+				flush()
+				text = text[i+1:]
+			}
+			old.WriteString(text)
+			cur.WriteString(text)
+		case DiffDelete:
+			old.WriteString(d.Text)
+			changed = true
+		case DiffInsert:
+			cur.WriteString(d.Text)
+			changed = true
+		}
+	}
+	flush()
+	return ret
+}
+
+// summarizeRegion line-diffs two versions of a changed region, starting at
+// the given line numbers.  Deleted and inserted lines between unchanged
+// lines are paired up as modifications.
+func summarizeRegion(text1, text2 string, line1, line2 int) []LineChange {
+	ids1, ids2, lines := DiffLinesToTokens(text1, text2)
+	diffs := DiffSlices(ids1, ids2)
+
+	ret := []LineChange{}
+	var dels, ins []string
+	pair := func() {
+		for i := 0; i < len(dels) || i < len(ins); i++ {
+			c := LineChange{Kind: LineModified}
+			if i < len(dels) {
+				c.OldLine = line1 - len(dels) + i
+				c.OldText = strings.TrimSuffix(dels[i], "\n")
+			} else {
+				c.Kind = LineAdded
+			}
+			if i < len(ins) {
+				c.NewLine = line2 - len(ins) + i
+				c.NewText = strings.TrimSuffix(ins[i], "\n")
+			} else {
+				c.Kind = LineDeleted
+			}
+			ret = append(ret, c)
+		}
+		dels, ins = nil, nil
+	}
+
+	for _, d := range diffs {
+		for _, id := range d.Items {
+			switch d.Type {
+			case DiffEqual:
+				pair()
+				line1++
+				line2++
+			case DiffDelete:
+				dels = append(dels, lines[id])
+				line1++
+			case DiffInsert:
+				ins = append(ins, lines[id])
+				line2++
+			}
+		}
+	}
+	pair()
+	return ret
+}
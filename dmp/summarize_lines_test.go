@@ -0,0 +1,42 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestSummarizeByLines(t *testing.T) {
+	dmp := New()
+	for _, test := range []struct {
+		text1, text2 string
+		want         []LineChange
+	}{
+		{"a\nb\n", "a\nb\n", []LineChange{}},
+		{"a\nb\nc\n", "a\nB\nc\n", []LineChange{
+			{LineModified, 2, 2, "b", "B"},
+		}},
+		{"a\nc\n", "a\nb\nc\n", []LineChange{
+			{LineAdded, 0, 2, "", "b"},
+		}},
+		{"a\nb\nc\n", "a\nc\n", []LineChange{
+			{LineDeleted, 2, 0, "b", ""},
+		}},
+		{"one\ntwo\nthree", "one\nTWO\nthree\nfour", []LineChange{
+			{LineModified, 2, 2, "two", "TWO"},
+			{LineModified, 3, 3, "three", "three"},
+			{LineAdded, 0, 4, "", "four"},
+		}},
+		{"", "x\ny\n", []LineChange{
+			{LineAdded, 0, 1, "", "x"},
+			{LineAdded, 0, 2, "", "y"},
+		}},
+		{"head\nthe cat sat\nmid\nold\ntail\n", "head\nthe dog sat\nmid\ntail\n", []LineChange{
+			{LineModified, 2, 2, "the cat sat", "the dog sat"},
+			{LineDeleted, 4, 0, "old", ""},
+		}},
+	} {
+		diffs := dmp.DiffMain(test.text1, test.text2, false)
+		assert.Equal(t, test.want, SummarizeByLines(diffs), test.text1)
+	}
+}
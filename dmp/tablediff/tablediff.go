@@ -0,0 +1,205 @@
+// Package tablediff compares two tables, such as spreadsheets or database
+// exports, row by row and, within changed rows, cell by cell.  Both
+// alignments run the dmp diff over sequences of whole rows or cells.
+package tablediff
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+// Kind tags a row or cell of a table diff.
+type Kind int8
+
+const (
+	// Equal rows or cells are the same in both tables.
+	Equal Kind = iota
+	// Deleted rows or cells only exist in the old table.
+	Deleted
+	// Inserted rows or cells only exist in the new table.
+	Inserted
+	// Modified rows or cells were replaced by a different value.
+	Modified
+)
+
+// CellDiff is the change of one cell of a modified row.  OldCol or NewCol
+// is -1 for an inserted or deleted cell.
+type CellDiff struct {
+	Kind   Kind
+	OldCol int
+	NewCol int
+	Old    string
+	New    string
+}
+
+// RowDiff is the change of one row.  OldRow or NewRow is -1 for an
+// inserted or deleted row.  Cells is only set for modified rows.
+type RowDiff struct {
+	Kind   Kind
+	OldRow int
+	NewRow int
+	Cells  []CellDiff
+}
+
+// Diff aligns the rows of old and cur, then the cells of rows that
+// changed.  Within a run of changed rows, a deleted and an inserted row are
+// paired as a modified row if at least half of their cells match; other
+// rows are reported as deleted or inserted.  Within a modified row,
+// changed cells are paired in order.
+func Diff(old, cur [][]string) []RowDiff {
+	keys := newKeyer()
+	oldKeys := make([]rune, len(old))
+	for i, row := range old {
+		oldKeys[i] = keys.rune(rowKey(row))
+	}
+	newKeys := make([]rune, len(cur))
+	for i, row := range cur {
+		newKeys[i] = keys.rune(rowKey(row))
+	}
+
+	// Cell diffs computed while testing rows for similarity.
+	cells := map[[2]int][]CellDiff{}
+	similar := func(i, j int) bool {
+		c := diffCells(old[i], cur[j])
+		equal := 0
+		for _, cell := range c {
+			if cell.Kind == Equal {
+				equal++
+			}
+		}
+		if 2*equal < len(old[i]) || 2*equal < len(cur[j]) {
+			return false
+		}
+		cells[[2]int{i, j}] = c
+		return true
+	}
+
+	ret := []RowDiff{}
+	align(oldKeys, newKeys, similar, func(k Kind, i, j int) {
+		r := RowDiff{Kind: k, OldRow: i, NewRow: j}
+		if k == Modified {
+			r.Cells = cells[[2]int{i, j}]
+		}
+		ret = append(ret, r)
+	})
+	return ret
+}
+
+func diffCells(old, cur []string) []CellDiff {
+	keys := newKeyer()
+	oldKeys := make([]rune, len(old))
+	for i, cell := range old {
+		oldKeys[i] = keys.rune(cell)
+	}
+	newKeys := make([]rune, len(cur))
+	for i, cell := range cur {
+		newKeys[i] = keys.rune(cell)
+	}
+
+	ret := []CellDiff{}
+	always := func(i, j int) bool { return true }
+	align(oldKeys, newKeys, always, func(k Kind, i, j int) {
+		c := CellDiff{Kind: k, OldCol: i, NewCol: j}
+		if i >= 0 {
+			c.Old = old[i]
+		}
+		if j >= 0 {
+			c.New = cur[j]
+		}
+		ret = append(ret, c)
+	})
+	return ret
+}
+
+// rowKey encodes the cells of a row unambiguously.
+func rowKey(row []string) string {
+	quoted := make([]string, len(row))
+	for i, cell := range row {
+		quoted[i] = strconv.Quote(cell)
+	}
+	return strings.Join(quoted, ",")
+}
+
+// keyer maps distinct strings to distinct runes so that sequences of them
+// can be diffed.
+type keyer map[string]rune
+
+func newKeyer() keyer {
+	return keyer{}
+}
+
+func (k keyer) rune(s string) rune {
+	r, ok := k[s]
+	if !ok {
+		r = rune(len(k) + 1)
+		if r >= 0xD800 {
+			// Skip the surrogates, which do not survive a round trip
+			// through a string.
+			r += 0x800
+		}
+		k[s] = r
+	}
+	return r
+}
+
+// align diffs two key sequences and calls emit for each element in order.
+// Within a run of changes, each deleted element is paired as a
+// modification with the next inserted element it is similar to, keeping
+// the order of both sequences.  Indexes that do not apply are -1.
+func align(
+	a, b []rune, similar func(i, j int) bool, emit func(k Kind, i, j int),
+) {
+	engine := dmp.New()
+	engine.DiffTimeout = 0
+	diffs := engine.DiffMainRunes(a, b, false)
+
+	i, j := 0, 0
+	deleted, inserted := 0, 0
+	flush := func() {
+		next := j - inserted
+		for del := i - deleted; del < i; del++ {
+			match := -1
+			for ins := next; ins < j; ins++ {
+				if similar(del, ins) {
+					match = ins
+					break
+				}
+			}
+			if match == -1 {
+				emit(Deleted, del, -1)
+				continue
+			}
+			for ; next < match; next++ {
+				emit(Inserted, -1, next)
+			}
+			emit(Modified, del, match)
+			next++
+		}
+		for ; next < j; next++ {
+			emit(Inserted, -1, next)
+		}
+		deleted, inserted = 0, 0
+	}
+	for _, d := range diffs {
+		// The diff text holds one rune per element; only counts matter.
+		n := len([]rune(d.Text))
+		switch d.Type {
+		case dmp.DiffDelete:
+			deleted += n
+			i += n
+		case dmp.DiffInsert:
+			inserted += n
+			j += n
+		case dmp.DiffEqual:
+			flush()
+			for x := 0; x < n; x++ {
+				emit(Equal, i+x, j+x)
+			}
+			i += n
+			j += n
+		}
+	}
+	flush()
+}
@@ -0,0 +1,63 @@
+package tablediff
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	old := [][]string{
+		{"id", "name", "city"},
+		{"1", "Ann", "Oslo"},
+		{"2", "Bob", "Rome"},
+		{"3", "Cid", "Lima"},
+	}
+	cur := [][]string{
+		{"id", "name", "country", "city"},
+		{"1", "Ann", "Oslo"},
+		{"3", "Cid", "Quito"},
+		{"4", "Dee", "Kyiv"},
+	}
+
+	assert.Equal(t, []RowDiff{
+		{Modified, 0, 0, []CellDiff{
+			{Equal, 0, 0, "id", "id"},
+			{Equal, 1, 1, "name", "name"},
+			{Inserted, -1, 2, "", "country"},
+			{Equal, 2, 3, "city", "city"},
+		}},
+		{Equal, 1, 1, nil},
+		{Deleted, 2, -1, nil},
+		{Modified, 3, 2, []CellDiff{
+			{Equal, 0, 0, "3", "3"},
+			{Equal, 1, 1, "Cid", "Cid"},
+			{Modified, 2, 2, "Lima", "Quito"},
+		}},
+		{Inserted, -1, 3, nil},
+	}, Diff(old, cur), "")
+
+	assert.Equal(t, []RowDiff{}, Diff(nil, nil), "")
+	assert.Equal(t, []RowDiff{{Inserted, -1, 0, nil}}, Diff(nil, old[:1]), "")
+	assert.Equal(t, []RowDiff{
+		{Modified, 0, 0, []CellDiff{
+			{Equal, 0, 0, "k", "k"},
+			{Equal, 1, 1, "x", "x"},
+			{Equal, 2, 2, "y", "y"},
+			{Modified, 3, 3, "a", "c"},
+			{Inserted, -1, 4, "", "e"},
+		}},
+	}, Diff([][]string{{"k", "x", "y", "a"}}, [][]string{{"k", "x", "y", "c", "e"}}), "")
+	assert.Equal(t, []RowDiff{{Deleted, 0, -1, nil}, {Inserted, -1, 0, nil}},
+		Diff([][]string{{"a", "b"}}, [][]string{{"c", "d"}}), "Dissimilar rows are not paired.")
+}
+
+func TestKeyer(t *testing.T) {
+	k := newKeyer()
+	for i := 1; i < 0xD800; i++ {
+		k[string(rune(i))+"x"] = rune(i + 1)
+	}
+	r := k.rune("next")
+	assert.Equal(t, rune(0xE000), r, "Surrogates are skipped.")
+	assert.Equal(t, r, k.rune("next"), "")
+}
@@ -0,0 +1,92 @@
+package dmp
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// TailPatcher applies patches to the recent end of an append-only stream,
+// such as a log being annotated, without keeping the whole stream.  Text
+// written to it is held in a window of the last bytes of the stream, where
+// patches can still change it, and passed on to the underlying writer once
+// it leaves the window.
+//
+// Patches locate text by its offset in the whole stream, counting the
+// changes of the patches applied before them, as for Apply.  A patch that
+// reaches back before the window can not be applied.
+type TailPatcher struct {
+	dmp    DMP
+	w      io.Writer
+	window int
+	// The text of the window, with the patches applied.
+	buf string
+	// Offset in the stream of the start of buf.
+	offset int
+}
+
+// NewTailPatcher returns a TailPatcher writing to w that keeps the last
+// window bytes of the stream open to patches.  It applies patches with the
+// settings of dmp.
+func (dmp *DMP) NewTailPatcher(w io.Writer, window int) *TailPatcher {
+	return &TailPatcher{dmp: *dmp, w: w, window: window}
+}
+
+// Write appends p to the stream, and writes the text that leaves the
+// window to the underlying writer.
+func (tp *TailPatcher) Write(p []byte) (int, error) {
+	tp.buf += string(p)
+	if len(tp.buf) <= tp.window {
+		return len(p), nil
+	}
+	// Keep whole runes in the window, so that patches can match them.
+	cut := len(tp.buf) - tp.window
+	for cut < len(tp.buf) && !utf8.RuneStart(tp.buf[cut]) {
+		cut++
+	}
+	if _, err := io.WriteString(tp.w, tp.buf[:cut]); err != nil {
+		return 0, err
+	}
+	tp.buf = tp.buf[cut:]
+	tp.offset += cut
+	return len(p), nil
+}
+
+// Apply applies patches to the window, and returns which were applied.
+func (tp *TailPatcher) Apply(ps []Patch) []bool {
+	applied := make([]bool, len(ps))
+	for i, p := range ps {
+		if p.start1 < tp.offset || p.start2 < tp.offset {
+			continue
+		}
+		p = PatchDeepCopy([]Patch{p})[0]
+		p.start1 -= tp.offset
+		p.start2 -= tp.offset
+		buf, ok := tp.dmp.Apply([]Patch{p}, tp.buf)
+		if allTrue(ok) {
+			tp.buf = buf
+			applied[i] = true
+		}
+	}
+	return applied
+}
+
+// Flush writes the whole window to the underlying writer, after which
+// nothing written so far can be patched.
+func (tp *TailPatcher) Flush() error {
+	if _, err := io.WriteString(tp.w, tp.buf); err != nil {
+		return err
+	}
+	tp.offset += len(tp.buf)
+	tp.buf = ""
+	return nil
+}
+
+// allTrue tells whether all of bs are true.
+func allTrue(bs []bool) bool {
+	for _, b := range bs {
+		if !b {
+			return false
+		}
+	}
+	return true
+}
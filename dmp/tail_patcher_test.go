@@ -0,0 +1,47 @@
+package dmp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestTailPatcher(t *testing.T) {
+	dmp := New()
+	var out bytes.Buffer
+	tp := dmp.NewTailPatcher(&out, 60)
+
+	line1 := "10:00 service started\n"
+	line2 := "10:01 user logged in\n"
+	line3 := "10:02 disk usage at 95%\n"
+	tp.Write([]byte(line1 + line2))
+	assert.Equal(t, "", out.String(), "")
+
+	// Correct the second line while it is in the window.
+	ps := dmp.PatchMake(line1+line2, line1+"10:01 user alice logged in\n")
+	assert.Equal(t, []bool{true}, tp.Apply(ps), "")
+	line2 = "10:01 user alice logged in\n"
+
+	tp.Write([]byte(line3))
+	assert.Equal(t, line1+line2+line3, out.String()+tp.buf, "")
+	assert.True(t, out.Len() > 0, "")
+
+	// The first line has left the window.
+	ps = dmp.PatchMake(line1+line2+line3,
+		"10:00 service restarted\n"+line2+line3)
+	assert.Equal(t, []bool{false}, tp.Apply(ps), "")
+
+	// The last line has not.
+	ps = dmp.PatchMake(line1+line2+line3,
+		line1+line2+"10:02 disk usage at 96%\n")
+	assert.Equal(t, []bool{true}, tp.Apply(ps), "")
+
+	assert.Nil(t, tp.Flush(), "")
+	assert.Equal(t, line1+line2+"10:02 disk usage at 96%\n", out.String(), "")
+
+	w := &failWriter{0}
+	tp = dmp.NewTailPatcher(w, 2)
+	_, err := tp.Write([]byte("abc"))
+	assert.NotNil(t, err, "")
+}
@@ -0,0 +1,40 @@
+// Writes js.json from input.json with the formatting code of the
+// JavaScript port of diff-match-patch:  node gen.js > js.json
+var cases = JSON.parse(require('fs').readFileSync('input.json', 'utf8'));
+
+function coords(start, length) {
+  if (length === 0) return start + ',0';
+  if (length == 1) return String(start + 1);
+  return (start + 1) + ',' + length;
+}
+
+function toDelta(diffs) {
+  var text = [];
+  for (var x = 0; x < diffs.length; x++) {
+    switch (diffs[x][0]) {
+      case 1: text[x] = '+' + encodeURI(diffs[x][1]); break;
+      case -1: text[x] = '-' + diffs[x][1].length; break;
+      case 0: text[x] = '=' + diffs[x][1].length; break;
+    }
+  }
+  return text.join('\t').replace(/%20/g, ' ');
+}
+
+function patchText(p) {
+  var text1 = '', text2 = '';
+  p.diffs.forEach(function(d) {
+    if (d[0] !== 1) text1 += d[1];
+    if (d[0] !== -1) text2 += d[1];
+  });
+  var text = ['@@ -' + coords(p.prefix1.length, text1.length) + ' +' +
+      coords(p.prefix2.length, text2.length) + ' @@\n'];
+  p.diffs.forEach(function(d) {
+    var op = d[0] == 1 ? '+' : d[0] == -1 ? '-' : ' ';
+    text.push(op + encodeURI(d[1]) + '\n');
+  });
+  return text.join('').replace(/%20/g, ' ');
+}
+
+console.log(JSON.stringify(cases.map(function(c) {
+  return {delta: toDelta(c.diffs), patch: c.patches.map(patchText).join('')};
+}), null, 2));
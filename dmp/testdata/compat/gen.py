@@ -0,0 +1,44 @@
+# Writes python.json from input.json with the formatting code of the
+# Python 3 port of diff-match-patch:  python3 gen.py > python.json
+import json
+import urllib.parse
+
+SAFE = "!~*'();/?:@&=+$,# "
+
+
+def coords(start, length):
+    if length == 0:
+        return str(start) + ",0"
+    if length == 1:
+        return str(start + 1)
+    return str(start + 1) + "," + str(length)
+
+
+def to_delta(diffs):
+    text = []
+    for op, data in diffs:
+        if op == 1:
+            text.append("+" + urllib.parse.quote(data.encode("utf-8"), SAFE))
+        elif op == -1:
+            text.append("-%d" % len(data))
+        else:
+            text.append("=%d" % len(data))
+    return "\t".join(text)
+
+
+def patch_text(p):
+    text1 = "".join(d for op, d in p["diffs"] if op != 1)
+    text2 = "".join(d for op, d in p["diffs"] if op != -1)
+    text = ["@@ -", coords(len(p["prefix1"]), len(text1)), " +",
+            coords(len(p["prefix2"]), len(text2)), " @@\n"]
+    for op, data in p["diffs"]:
+        text.append({1: "+", -1: "-", 0: " "}[op])
+        text.append(urllib.parse.quote(data.encode("utf-8"), SAFE) + "\n")
+    return "".join(text)
+
+
+with open("input.json") as f:
+    cases = json.load(f)
+print(json.dumps([{"delta": to_delta(c["diffs"]),
+                   "patch": "".join(patch_text(p) for p in c["patches"])}
+                  for c in cases], indent=2))
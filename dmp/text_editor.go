@@ -0,0 +1,79 @@
+package dmp
+
+// Range is a half-open range [Start, End) of byte offsets in a text.
+type Range struct {
+	Start int
+	End   int
+}
+
+// shiftRanges returns the ranges moved by n bytes.
+func shiftRanges(rs []Range, n int) []Range {
+	if len(rs) == 0 {
+		return nil
+	}
+	ret := make([]Range, len(rs))
+	for i, r := range rs {
+		ret[i] = Range{r.Start + n, r.End + n}
+	}
+	return ret
+}
+
+// textEditor applies a sequence of replacements to a text while keeping a
+// set of protected ranges up to date.  A replacement touching a protected
+// range blocks the editor, and one outside the text fails it; either way
+// all later replacements are ignored.
+type textEditor struct {
+	text      string
+	protected []Range
+	blocked   bool
+	failed    bool
+}
+
+func newTextEditor(text string, protected []Range) *textEditor {
+	return &textEditor{
+		text:      text,
+		protected: append([]Range(nil), protected...),
+	}
+}
+
+// replace replaces text[start:end] with s.
+func (e *textEditor) replace(start, end int, s string) {
+	if e.blocked || e.failed {
+		return
+	}
+	if start < 0 || start > end || end > len(e.text) {
+		e.failed = true
+		return
+	}
+	for _, r := range e.protected {
+		if start < r.End && end > r.Start ||
+			start == end && len(s) != 0 && r.Start < start && start < r.End {
+			e.blocked = true
+			return
+		}
+	}
+
+	e.text = e.text[:start] + s + e.text[end:]
+	growth := len(s) - (end - start)
+	for i, r := range e.protected {
+		if r.Start >= end {
+			e.protected[i] = Range{r.Start + growth, r.End + growth}
+		}
+	}
+}
+
+// replaceDiffs applies the edits of diffs, whose source text is found at
+// loc, one at a time.
+func (e *textEditor) replaceDiffs(loc int, diffs []Diff) {
+	for _, d := range diffs {
+		switch d.Type {
+		case DiffEqual:
+			loc += len(d.Text)
+		case DiffDelete:
+			e.replace(loc, loc+len(d.Text), "")
+		case DiffInsert:
+			e.replace(loc, loc, d.Text)
+			loc += len(d.Text)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestTextEditor(t *testing.T) {
+	e := newTextEditor("0123456789", []Range{{2, 4}, {6, 8}})
+	e.replace(4, 6, "xyz")
+	assert.False(t, e.blocked, "")
+	assert.Equal(t, "0123xyz6789", e.text, "")
+	assert.Equal(t, []Range{{2, 4}, {7, 9}}, e.protected, "")
+
+	// Insertions on the edges of a range are fine, inside are not.
+	e.replace(2, 2, "<")
+	e.replace(5, 5, ">")
+	assert.Equal(t, "01<23>xyz6789", e.text, "")
+	assert.Equal(t, []Range{{3, 5}, {9, 11}}, e.protected, "")
+	e.replace(4, 4, "!")
+	assert.True(t, e.blocked, "")
+
+	// Once blocked, nothing changes.
+	e.replace(0, 1, "")
+	assert.Equal(t, "01<23>xyz6789", e.text, "")
+
+	e = newTextEditor("0123456789", []Range{{2, 4}})
+	e.replace(3, 5, "")
+	assert.True(t, e.blocked, "Overlapping deletion.")
+}
+
+func TestApplyProtected(t *testing.T) {
+	dmp := New()
+	header := "// Copyright 2012. All rights reserved.\n"
+	text1 := header + "func main() {\n\tprintln(\"hi\")\n}\n"
+	text2 := "// Copyright 2013. All rights reserved.\n" +
+		"func main() {\n\tprintln(\"hello\")\n}\n"
+	patches := dmp.PatchMake(text1, text2)
+	assert.Equal(t, 2, len(patches), "")
+
+	protected := []Range{{0, len(header)}}
+	s, results := dmp.ApplyProtected(patches, text1, protected)
+	assert.Equal(t, header+"func main() {\n\tprintln(\"hello\")\n}\n", s, "")
+	assert.True(t, results[0].Blocked, "")
+	assert.False(t, results[0].Applied, "")
+	assert.True(t, results[1].Applied, "")
+	assert.False(t, results[1].Blocked, "")
+
+	// Imperfect matches are checked too.
+	shifted := "package main\n\n" + text1
+	protected = []Range{{14, 14 + len(header)}}
+	s, results = dmp.ApplyProtected(patches, shifted, protected)
+	assert.Equal(t, "package main\n\n"+header+"func main() {\n\tprintln(\"hello\")\n}\n", s, "")
+	assert.True(t, results[0].Blocked, "")
+	assert.True(t, results[1].Applied, "")
+
+	// Without protection everything applies.
+	s, results = dmp.ApplyProtected(patches, text1, nil)
+	assert.Equal(t, text2, s, "")
+	assert.True(t, results[0].Applied && results[1].Applied, "")
+}
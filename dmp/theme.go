@@ -0,0 +1,123 @@
+package dmp
+
+// Style is how the renderers show one kind of diff.
+type Style struct {
+	// ANSI is the escape sequence that starts the text in a terminal, and
+	// CSS the inline style of its element in HTML ("" for none).
+	ANSI string
+	CSS  string
+
+	// Prefix and Suffix enclose the text, in a terminal and in HTML.
+	Prefix string
+	Suffix string
+}
+
+// Theme holds the styles of DiffWriteText and DiffWriteHtml, which select
+// one with the Theme field of their options.  Moved blocks, see
+// DiffDetectMoves, take the markers of deletions and insertions unless
+// Move has its own.
+type Theme struct {
+	Name   string
+	Delete Style
+	Insert Style
+	Equal  Style
+	Move   Style
+}
+
+// Built-in themes.
+var (
+	// ThemeDefault is the theme of DiffPrettyText and DiffPrettyHtml.
+	ThemeDefault = Theme{
+		Name:   "default",
+		Delete: Style{ANSI: ansiRed, CSS: "background:#ffe6e6;"},
+		Insert: Style{ANSI: ansiGreen, CSS: "background:#e6ffe6;"},
+		Move:   Style{ANSI: ansiCyan, CSS: "background:#e6e6ff;"},
+	}
+
+	// ThemeGitHub takes the colors of the diffs of GitHub.
+	ThemeGitHub = Theme{
+		Name:   "github",
+		Delete: Style{ANSI: ansiRed, CSS: "background:#ffebe9;"},
+		Insert: Style{ANSI: ansiGreen, CSS: "background:#e6ffec;"},
+		Move:   Style{ANSI: ansiCyan, CSS: "background:#ddf4ff;"},
+	}
+
+	// ThemeSolarized takes the accent colors of Solarized, in 24-bit
+	// color in terminals.
+	ThemeSolarized = Theme{
+		Name: "solarized",
+		Delete: Style{
+			ANSI: "\x1b[38;2;220;50;47m", CSS: "color:#dc322f;",
+		},
+		Insert: Style{
+			ANSI: "\x1b[38;2;133;153;0m", CSS: "color:#859900;",
+		},
+		Equal: Style{
+			ANSI: "\x1b[38;2;101;123;131m", CSS: "color:#657b83;",
+		},
+		Move: Style{
+			ANSI: "\x1b[38;2;38;139;210m", CSS: "color:#268bd2;",
+		},
+	}
+
+	// ThemeMonochrome shows edits without color, by markers, strike
+	// through, underline and italics, for monochrome displays and color
+	// blind readers.
+	ThemeMonochrome = Theme{
+		Name: "monochrome",
+		Delete: Style{
+			ANSI: "\x1b[9m", CSS: "text-decoration:line-through;",
+			Prefix: "[-", Suffix: "-]",
+		},
+		Insert: Style{
+			ANSI: "\x1b[4m", CSS: "text-decoration:underline;",
+			Prefix: "{+", Suffix: "+}",
+		},
+		Move: Style{ANSI: "\x1b[3m", CSS: "font-style:italic;"},
+	}
+)
+
+// Themes lists the built-in themes.
+var Themes = []*Theme{
+	&ThemeDefault, &ThemeGitHub, &ThemeSolarized, &ThemeMonochrome,
+}
+
+// ThemeByName returns the built-in theme of the given name, such as
+// "github", as command line flags may name them.
+func ThemeByName(name string) (*Theme, bool) {
+	for _, t := range Themes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// orDefault returns t, or ThemeDefault if t is nil.
+func (t *Theme) orDefault() *Theme {
+	if t == nil {
+		return &ThemeDefault
+	}
+	return t
+}
+
+// style returns the Style of op, with the markers of moves filled in from
+// those of deletions and insertions.
+func (t *Theme) style(op Operation) Style {
+	switch op {
+	case DiffDelete:
+		return t.Delete
+	case DiffInsert:
+		return t.Insert
+	case DiffMoveFrom, DiffMove:
+		st := t.Move
+		edit := t.Delete
+		if op > 0 {
+			edit = t.Insert
+		}
+		st.Prefix = defaultString(st.Prefix, edit.Prefix)
+		st.Suffix = defaultString(st.Suffix, edit.Suffix)
+		return st
+	}
+	return t.Equal
+}
@@ -0,0 +1,53 @@
+package dmp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestTheme(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "a"},
+		{DiffDelete, "b"},
+		{DiffInsert, "<c>"},
+		{DiffMove, "d"},
+	}
+
+	var buf bytes.Buffer
+	DiffWriteText(&buf, diffs, TextOptions{Theme: &ThemeMonochrome})
+	assert.Equal(t, "a\x1b[9m[-b-]\x1b[0m\x1b[4m{+<c>+}\x1b[0m"+
+		"\x1b[3m{+d+}\x1b[0m", buf.String(), "")
+
+	buf.Reset()
+	DiffWriteText(&buf, diffs, TextOptions{
+		Theme: &ThemeSolarized, NoColor: true, InsertPrefix: ">",
+	})
+	assert.Equal(t, "a[-b-]><c>+}>d+}", buf.String(), "")
+
+	html := (&HtmlFormatter{Options: HtmlOptions{Theme: &ThemeGitHub}}).
+		Format(diffs)
+	assert.Equal(t, "<span>a</span>"+
+		"<del style=\"background:#ffebe9;\">b</del>"+
+		"<ins style=\"background:#e6ffec;\">&lt;c&gt;</ins>"+
+		"<ins style=\"background:#ddf4ff;\">d</ins>", html, "")
+
+	buf.Reset()
+	DiffWriteHtml(&buf, diffs[:3], HtmlOptions{Theme: &ThemeMonochrome})
+	assert.Equal(t, "<span>a</span>"+
+		"<del style=\"text-decoration:line-through;\">[-b-]</del>"+
+		"<ins style=\"text-decoration:underline;\">{+&lt;c&gt;+}</ins>",
+		buf.String(), "")
+
+	// The default theme renders as before.
+	assert.Equal(t, DiffPrettyHtml(diffs),
+		(&HtmlFormatter{Options: HtmlOptions{Theme: &ThemeDefault}}).
+			Format(diffs), "")
+
+	theme, ok := ThemeByName("solarized")
+	assert.True(t, ok, "")
+	assert.Equal(t, &ThemeSolarized, theme, "")
+	_, ok = ThemeByName("neon")
+	assert.False(t, ok, "")
+}
@@ -0,0 +1,138 @@
+package dmp
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// TokenStore holds the table of the distinct tokens of a coarse diff, such
+// as the lines of a line-mode diff, which DiffTokensToRunes keeps in
+// memory.  Backing it with an on-disk key-value store or a memory-mapped
+// index keeps the memory of diffs of enormous files from growing with
+// their number of distinct lines.
+type TokenStore interface {
+	// Intern returns the id of token, adding it under the next free id,
+	// counting from 1, if the store does not hold it yet.
+	Intern(token string) (int, error)
+	// Token returns the token of id.
+	Token(id int) (string, error)
+}
+
+// MemoryTokenStore is a TokenStore in memory, as used by
+// DiffTokensToRunes.
+type MemoryTokenStore struct {
+	ids    map[string]int
+	tokens []string
+}
+
+// NewMemoryTokenStore returns an empty MemoryTokenStore.
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{ids: map[string]int{}, tokens: []string{""}}
+}
+
+// Intern implements TokenStore.
+func (s *MemoryTokenStore) Intern(token string) (int, error) {
+	id, ok := s.ids[token]
+	if !ok {
+		id = len(s.tokens)
+		s.tokens = append(s.tokens, token)
+		s.ids[token] = id
+	}
+	return id, nil
+}
+
+// Token implements TokenStore.
+func (s *MemoryTokenStore) Token(id int) (string, error) {
+	if id <= 0 || id >= len(s.tokens) {
+		return "", fmt.Errorf("Unknown token id: %d", id)
+	}
+	return s.tokens[id], nil
+}
+
+// Ids are written as runes skipping the surrogates, which do not survive
+// the conversion of the diffs to strings.
+const (
+	surrogateMin = 0xD800
+	surrogateEnd = 0xE000
+	maxTokenID   = utf8.MaxRune - (surrogateEnd - surrogateMin)
+)
+
+// tokenRune returns the rune standing for the token of id.
+func tokenRune(id int) (rune, error) {
+	if id <= 0 || id > maxTokenID {
+		return 0, fmt.Errorf("Token id out of range: %d", id)
+	}
+	if id >= surrogateMin {
+		id += surrogateEnd - surrogateMin
+	}
+	return rune(id), nil
+}
+
+// DiffTokensToRunesStore is DiffTokensToRunes keeping the table of tokens
+// in store, in which the runes are ids.
+func DiffTokensToRunesStore(
+	t Tokenizer, s1, s2 string, store TokenStore,
+) ([]rune, []rune, error) {
+	munge := func(text string) ([]rune, error) {
+		tokens := t.Tokenize(text)
+		runes := make([]rune, len(tokens))
+		for i, token := range tokens {
+			id, err := store.Intern(token)
+			if err != nil {
+				return nil, err
+			}
+			if runes[i], err = tokenRune(id); err != nil {
+				return nil, err
+			}
+		}
+		return runes, nil
+	}
+	runes1, err := munge(s1)
+	if err != nil {
+		return nil, nil, err
+	}
+	runes2, err := munge(s2)
+	if err != nil {
+		return nil, nil, err
+	}
+	return runes1, runes2, nil
+}
+
+// DiffCharsToTokens is DiffCharsToLines for the runes of
+// DiffTokensToRunesStore, looking the tokens up in store.
+func DiffCharsToTokens(diffs []Diff, store TokenStore) ([]Diff, error) {
+	diffs = withoutMoves(diffs)
+	hydrated := make([]Diff, 0, len(diffs))
+	for _, d := range diffs {
+		var text bytes.Buffer
+		for _, r := range d.Text {
+			id := int(r)
+			if id >= surrogateEnd {
+				id -= surrogateEnd - surrogateMin
+			}
+			token, err := store.Token(id)
+			if err != nil {
+				return nil, err
+			}
+			text.WriteString(token)
+		}
+		d.Text = text.String()
+		hydrated = append(hydrated, d)
+	}
+	return hydrated, nil
+}
+
+// DiffLinesStore diffs two texts line by line, keeping the table of their
+// lines in store.  The diffs insert, delete and keep whole lines.
+func (dmp *DMP) DiffLinesStore(
+	text1, text2 string, store TokenStore,
+) ([]Diff, error) {
+	runes1, runes2, err := DiffTokensToRunesStore(
+		LineTokenizer, text1, text2, store,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return DiffCharsToTokens(dmp.DiffMainRunes(runes1, runes2, false), store)
+}
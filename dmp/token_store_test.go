@@ -0,0 +1,69 @@
+package dmp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+// countingStore is a MemoryTokenStore that fails past a number of tokens.
+type countingStore struct {
+	*MemoryTokenStore
+	max int
+}
+
+func (s countingStore) Intern(token string) (int, error) {
+	id, err := s.MemoryTokenStore.Intern(token)
+	if id > s.max {
+		return 0, fmt.Errorf("Store full")
+	}
+	return id, err
+}
+
+func TestDiffLinesStore(t *testing.T) {
+	dmp := New()
+	s1 := readFile("speedtest1.txt", t)
+	s2 := readFile("speedtest2.txt", t)
+	runes1, runes2, lines := DiffLinesToRunes(s1, s2)
+	want := DiffCharsToLines(dmp.DiffMainRunes(runes1, runes2, false), lines)
+
+	diffs, err := dmp.DiffLinesStore(s1, s2, NewMemoryTokenStore())
+	assert.Nil(t, err, "")
+	assert.Equal(t, want, diffs, "")
+
+	_, err = dmp.DiffLinesStore(s1, s2, countingStore{NewMemoryTokenStore(), 10})
+	assert.Equal(t, "Store full", err.Error(), "")
+
+	_, err = DiffCharsToTokens(
+		[]Diff{{DiffEqual, "\x01\x02"}}, NewMemoryTokenStore(),
+	)
+	assert.Equal(t, "Unknown token id: 1", err.Error(), "")
+}
+
+func TestDiffTokensToRunesStoreSurrogates(t *testing.T) {
+	// Enough lines for ids in and past the surrogates.
+	var b strings.Builder
+	for i := 0; i < 0xE100; i++ {
+		fmt.Fprintf(&b, "%d\n", i)
+	}
+	s1 := b.String()
+	s2 := strings.Replace(s1, "\n55300\n", "\nx\n", 1)
+	store := NewMemoryTokenStore()
+	runes1, runes2, err := DiffTokensToRunesStore(LineTokenizer, s1, s2, store)
+	assert.Nil(t, err, "")
+	diffs, err := DiffCharsToTokens(
+		[]Diff{{DiffDelete, string(runes1)}, {DiffInsert, string(runes2)}},
+		store,
+	)
+	assert.Nil(t, err, "")
+	assert.Equal(t, s1, diffs[0].Text, "")
+	assert.Equal(t, s2, diffs[1].Text, "")
+
+	_, err = tokenRune(maxTokenID + 1)
+	assert.NotNil(t, err, "")
+	r, err := tokenRune(maxTokenID)
+	assert.Nil(t, err, "")
+	assert.Equal(t, rune(0x10FFFF), r, "")
+}
@@ -0,0 +1,125 @@
+package dmp
+
+import "fmt"
+
+// Tokenizer cuts a text into the tokens a coarse diff works on, such as
+// lines, sentences, CSV fields or source code tokens.  The tokens must
+// concatenate back to the text.
+type Tokenizer interface {
+	Tokenize(text string) []string
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface.
+type TokenizerFunc func(text string) []string
+
+// Tokenize calls f(text).
+func (f TokenizerFunc) Tokenize(text string) []string {
+	return f(text)
+}
+
+// LineTokenizer cuts a text after each newline.
+var LineTokenizer Tokenizer = lineTokenizer{}
+
+// SentenceTokenizer cuts a text after each run of ., ! or ? followed by
+// whitespace, including the whitespace.
+var SentenceTokenizer Tokenizer = TokenizerFunc(splitSentences)
+
+// WordTokenizer cuts a text into words, runs of whitespace and single
+// other characters.
+var WordTokenizer Tokenizer = TokenizerFunc(splitWords)
+
+// lineTokenizer cuts a text into lines, and lines longer than maxLen
+// bytes into smaller tokens with splitLongLine (0 for no limit).
+type lineTokenizer struct {
+	maxLen int
+}
+
+func (t lineTokenizer) Tokenize(text string) []string {
+	tokens := []string{}
+	// Walk the text, pulling out a substring for each line.
+	// text.split('\n') would would temporarily double our memory footprint.
+	lineStart := 0
+	for lineStart < len(text) {
+		lineEnd := indexOf(text, "\n", lineStart) + 1
+		if lineEnd == 0 {
+			lineEnd = len(text)
+		}
+		tokens = append(tokens, splitLongLine(text[lineStart:lineEnd],
+			t.maxLen)...)
+		lineStart = lineEnd
+	}
+	return tokens
+}
+
+// DiffTokensToRunes cuts two texts into tokens with t and reduces them to
+// []runes where each rune represents one distinct token.  The returned
+// array maps the runes back to the tokens, for DiffCharsToLines.  The
+// runes skip the surrogates, which do not survive the conversion of the
+// diffs to strings, so up to 1,112,063 distinct tokens fit; more panic,
+// see DiffTokensToRunesChecked.
+func DiffTokensToRunes(t Tokenizer, s1, s2 string) ([]rune, []rune, []string) {
+	chars1, chars2, tokenArray, err := DiffTokensToRunesChecked(t, s1, s2)
+	if err != nil {
+		panic("dmp: " + err.Error())
+	}
+	return chars1, chars2, tokenArray
+}
+
+// DiffTokensToRunesChecked is like DiffTokensToRunes, but fails on texts
+// of more distinct tokens than the runes can stand for.  Such texts need
+// the indices of DiffLinesToTokens.
+func DiffTokensToRunesChecked(t Tokenizer, s1, s2 string) (
+	[]rune, []rune, []string, error,
+) {
+	// '\x00' is a valid character, but various debuggers don't like it.
+	// So we'll insert a junk entry to avoid generating a null character.
+	tokenArray := []string{""}    // e.g. tokenArray[4] == 'Hello\n'
+	tokenHash := map[string]int{} // e.g. tokenHash['Hello\n'] == 4
+
+	chars1, err := diffTokensToRunesMunge(
+		t.Tokenize(s1), &tokenArray, tokenHash,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	chars2, err := diffTokensToRunesMunge(
+		t.Tokenize(s2), &tokenArray, tokenHash,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return chars1, chars2, tokenArray, nil
+}
+
+// DiffTokensToChars is DiffTokensToRunes returning strings, like
+// DiffLinesToChars for lines.
+func DiffTokensToChars(t Tokenizer, s1, s2 string) (string, string, []string) {
+	chars1, chars2, tokenArray := DiffTokensToRunes(t, s1, s2)
+	return string(chars1), string(chars2), tokenArray
+}
+
+// diffTokensToRunesMunge reduces tokens to a []rune where each rune
+// stands for one distinct token, adding new tokens to tokenArray.  The
+// runes skip the surrogates, as tokenRune does, and run out past
+// maxTokenID distinct tokens.
+func diffTokensToRunesMunge(
+	tokens []string, tokenArray *[]string, tokenHash map[string]int,
+) ([]rune, error) {
+	runes := make([]rune, len(tokens))
+	for i, token := range tokens {
+		value, ok := tokenHash[token]
+		if !ok {
+			if len(*tokenArray) > maxTokenID {
+				return nil, fmt.Errorf(
+					"Too many distinct tokens for runes: more than %d",
+					maxTokenID,
+				)
+			}
+			*tokenArray = append(*tokenArray, token)
+			value = len(*tokenArray) - 1
+			tokenHash[token] = value
+		}
+		runes[i], _ = tokenRune(value)
+	}
+	return runes, nil
+}
@@ -0,0 +1,99 @@
+package dmp
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffTokensToChars(t *testing.T) {
+	// CSV fields, each with its separator.
+	fields := TokenizerFunc(func(text string) []string {
+		tokens := []string{}
+		for len(text) > 0 {
+			end := strings.IndexAny(text, ",\n") + 1
+			if end == 0 {
+				end = len(text)
+			}
+			tokens = append(tokens, text[:end])
+			text = text[end:]
+		}
+		return tokens
+	})
+	text1 := "id,name,age\n1,alice,30\n"
+	text2 := "id,name,age\n1,alicia,30\n"
+	chars1, chars2, tokens := DiffTokensToChars(fields, text1, text2)
+	assert.Equal(t, "\x01\x02\x03\x04\x05\x06", chars1, "")
+	assert.Equal(t, "\x01\x02\x03\x04\x07\x06", chars2, "")
+
+	dmp := New()
+	diffs := DiffCharsToLines(dmp.DiffMain(chars1, chars2, false), tokens)
+	assert.Equal(t, []Diff{
+		{DiffEqual, "id,name,age\n1,"},
+		{DiffDelete, "alice,"},
+		{DiffInsert, "alicia,"},
+		{DiffEqual, "30\n"},
+	}, diffs, "")
+
+	// The built-in tokenizers.
+	assert.Equal(t, []string{"a\n", "b\n", "c"},
+		LineTokenizer.Tokenize("a\nb\nc"), "")
+	assert.Equal(t, []string{"A b. ", "C?"},
+		SentenceTokenizer.Tokenize("A b. C?"), "")
+	c1, c2, lines := DiffLinesToChars("a\nb\n", "b\n")
+	assert.Equal(t, "\x01\x02", c1, "")
+	assert.Equal(t, "\x02", c2, "")
+	assert.Equal(t, []string{"", "a\n", "b\n"}, lines, "")
+}
+
+func TestDiffTokensToCharsManyTokens(t *testing.T) {
+	// More tokens than runes below the surrogates.
+	var b1, b2 strings.Builder
+	for i := 0; i < 0xE000; i++ {
+		fmt.Fprintf(&b1, "line %d\n", i)
+		if i == 0xD900 {
+			b2.WriteString("changed\n")
+		} else {
+			fmt.Fprintf(&b2, "line %d\n", i)
+		}
+	}
+	text1, text2 := b1.String(), b2.String()
+	chars1, chars2, tokens := DiffTokensToChars(LineTokenizer, text1, text2)
+	assert.True(t, utf8.ValidString(chars1), "")
+	assert.True(t, utf8.ValidString(chars2), "")
+
+	dmp := New()
+	dmp.DiffTimeout = 0
+	diffs := DiffCharsToLines(dmp.DiffMain(chars1, chars2, false), tokens)
+	assert.Equal(t, text1, DiffText1(diffs), "")
+	assert.Equal(t, text2, DiffText2(diffs), "")
+	assert.Equal(t, []Diff{
+		{DiffDelete, fmt.Sprintf("line %d\n", 0xD900)},
+		{DiffInsert, "changed\n"},
+	}, diffs[1:3], "")
+}
+
+func TestDiffTokensToRunesChecked(t *testing.T) {
+	runes1, runes2, tokens, err := DiffLinesToRunesChecked("a\nb\n", "b\n")
+	assert.Nil(t, err, "")
+	assert.Equal(t, []rune{1, 2}, runes1, "")
+	assert.Equal(t, []rune{2}, runes2, "")
+	assert.Equal(t, []string{"", "a\n", "b\n"}, tokens, "")
+
+	// One distinct token more than the runes stand for.
+	n := 0
+	many := TokenizerFunc(func(text string) []string {
+		tokens := make([]string, maxTokenID+1)
+		for i := range tokens {
+			tokens[i] = fmt.Sprint(n)
+			n++
+		}
+		return tokens
+	})
+	_, _, _, err = DiffTokensToRunesChecked(many, "", "")
+	assert.NotNil(t, err, "")
+	assert.Panics(t, func() { DiffTokensToRunes(many, "", "") }, "")
+}
@@ -0,0 +1,66 @@
+package dmp
+
+import (
+	"fmt"
+)
+
+// Transform transforms two concurrent diffs of the same text against each
+// other, as in operational transformation: a2 makes the change of a to the
+// text b produced, and b2 makes the change of b to the text a produced, so
+// that both orders converge on the same text.  When a and b insert at the
+// same place, the insertion of a goes first.
+func Transform(a, b []Diff) (a2, b2 []Diff, err error) {
+	a = withoutMoves(a)
+	b = withoutMoves(b)
+	if DiffText1(a) != DiffText1(b) {
+		return nil, nil, fmt.Errorf("Diffs do not start from the same text")
+	}
+	a2, b2 = []Diff{}, []Diff{}
+	// Bytes consumed of the current diffs of a and b.
+	i, j, off1, off2 := 0, 0, 0, 0
+	for i < len(a) || j < len(b) {
+		// Empty diffs cover nothing, and would leave the other side to
+		// run out first.
+		if i < len(a) && a[i].Text == "" {
+			i++
+			continue
+		}
+		if j < len(b) && b[j].Text == "" {
+			j++
+			continue
+		}
+		if i < len(a) && a[i].Type == DiffInsert {
+			a2 = append(a2, a[i])
+			b2 = append(b2, Diff{DiffEqual, a[i].Text})
+			i++
+			continue
+		}
+		if j < len(b) && b[j].Type == DiffInsert {
+			b2 = append(b2, b[j])
+			a2 = append(a2, Diff{DiffEqual, b[j].Text})
+			j++
+			continue
+		}
+		// Both diffs cover the original text from here; its lengths
+		// agree, so neither has run out.
+		d1, d2 := a[i], b[j]
+		n := min(len(d1.Text)-off1, len(d2.Text)-off2)
+		piece := d1.Text[off1 : off1+n]
+		switch {
+		case d1.Type == DiffEqual && d2.Type == DiffEqual:
+			a2 = append(a2, Diff{DiffEqual, piece})
+			b2 = append(b2, Diff{DiffEqual, piece})
+		case d1.Type == DiffDelete && d2.Type == DiffEqual:
+			a2 = append(a2, Diff{DiffDelete, piece})
+		case d1.Type == DiffEqual && d2.Type == DiffDelete:
+			b2 = append(b2, Diff{DiffDelete, piece})
+		}
+		if off1 += n; off1 == len(d1.Text) {
+			i, off1 = i+1, 0
+		}
+		if off2 += n; off2 == len(d2.Text) {
+			j, off2 = j+1, 0
+		}
+	}
+	return diffCleanupMerge(a2), diffCleanupMerge(b2), nil
+}
@@ -0,0 +1,71 @@
+package dmp
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestTransform(t *testing.T) {
+	dmp := New()
+	text := "The quick brown fox."
+	a := dmp.DiffMain(text, "The slow brown fox.", false)
+	b := dmp.DiffMain(text, "The quick brown fox jumps.", false)
+	a2, b2, err := Transform(a, b)
+	assert.Nil(t, err, "")
+	assert.Equal(t, "The slow brown fox jumps.", DiffText2(a2), "")
+	assert.Equal(t, "The slow brown fox jumps.", DiffText2(b2), "")
+
+	// Concurrent insertions at the same place: a goes first.
+	a2, b2, err = Transform(
+		[]Diff{{DiffEqual, "ab"}, {DiffInsert, "X"}},
+		[]Diff{{DiffEqual, "ab"}, {DiffInsert, "Y"}},
+	)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []Diff{{DiffEqual, "ab"}, {DiffInsert, "X"}, {DiffEqual, "Y"}}, a2, "")
+	assert.Equal(t, []Diff{{DiffEqual, "abX"}, {DiffInsert, "Y"}}, b2, "")
+
+	// Both delete overlapping text.
+	a2, b2, err = Transform(
+		[]Diff{{DiffDelete, "abc"}, {DiffEqual, "d"}},
+		[]Diff{{DiffEqual, "a"}, {DiffDelete, "bcd"}},
+	)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []Diff{{DiffDelete, "a"}}, a2, "")
+	assert.Equal(t, []Diff{{DiffDelete, "d"}}, b2, "")
+
+	_, _, err = Transform([]Diff{{DiffEqual, "a"}}, []Diff{{DiffEqual, "b"}})
+	assert.NotNil(t, err, "")
+
+	// Empty diffs are skipped.
+	a2, b2, err = Transform([]Diff{{DiffEqual, "x"}},
+		[]Diff{{DiffEqual, "x"}, {DiffEqual, ""}})
+	assert.Nil(t, err, "")
+	assert.Equal(t, []Diff{{DiffEqual, "x"}}, a2, "")
+	assert.Equal(t, []Diff{{DiffEqual, "x"}}, b2, "")
+	a2, b2, err = Transform([]Diff{{DiffDelete, ""}, {DiffEqual, "x"}},
+		[]Diff{{DiffEqual, "x"}, {DiffInsert, "y"}})
+	assert.Nil(t, err, "")
+	assert.Equal(t, []Diff{{DiffEqual, "xy"}}, a2, "")
+	assert.Equal(t, []Diff{{DiffEqual, "x"}, {DiffInsert, "y"}}, b2, "")
+
+	rnd := rand.New(rand.NewSource(1))
+	randomText := func() string {
+		b := make([]byte, rnd.Intn(40))
+		for i := range b {
+			b[i] = "abc"[rnd.Intn(3)]
+		}
+		return string(b)
+	}
+	for n := 0; n < 200; n++ {
+		text, text1, text2 := randomText(), randomText(), randomText()
+		a := dmp.DiffMain(text, text1, false)
+		b := dmp.DiffMain(text, text2, false)
+		a2, b2, err := Transform(a, b)
+		assert.Nil(t, err, "")
+		assert.Equal(t, text2, DiffText1(a2), "")
+		assert.Equal(t, text1, DiffText1(b2), "")
+		assert.Equal(t, DiffText2(a2), DiffText2(b2), "")
+	}
+}
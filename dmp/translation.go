@@ -0,0 +1,196 @@
+package dmp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TranslationStatus tells whether the translation of a source segment can
+// be kept after the source changed.
+type TranslationStatus int8
+
+const (
+	// TranslationCurrent segments did not change; the old translation still
+	// applies.
+	TranslationCurrent TranslationStatus = iota
+	// TranslationStale segments were edited; the old translation of the
+	// segment they came from needs review.
+	TranslationStale
+	// TranslationMissing segments are new and have no translation.
+	TranslationMissing
+	// TranslationObsolete segments were removed from the source; their
+	// translation can be dropped.
+	TranslationObsolete
+)
+
+// TranslationSegment proposes what to do with one segment.  OldSource
+// indexes the old source and translation, NewSource the new source; either
+// is -1 if it does not apply.  Source is the new source text, or the old
+// one for obsolete segments.  Translation is the old translation, if any.
+type TranslationSegment struct {
+	Status      TranslationStatus
+	OldSource   int
+	NewSource   int
+	Source      string
+	Translation string
+}
+
+// AlignTranslation maps the segments of a new source text onto the
+// segments of the old source, where oldTranslation[i] is the translation
+// of oldSource[i], to find which translations are still current.
+//
+// Unchanged segments are found by diffing the segment sequences.  Within a
+// run of changed segments, the source texts are diffed character by
+// character and each new segment is paired with the old segment sharing
+// the most text with it, provided that is at least half of the new
+// segment, so that an edited segment keeps its old translation as a
+// starting point.  Several new segments may be paired
+// with the same old one, e.g. when a sentence is split.  The result lists
+// the new segments in order, each run of changes followed by the old
+// segments no new segment was paired with.
+func (dmp *DMP) AlignTranslation(
+	oldSource, newSource, oldTranslation []string,
+) ([]TranslationSegment, error) {
+	if len(oldSource) != len(oldTranslation) {
+		return nil, fmt.Errorf(
+			"Translation has %d segments, source has %d",
+			len(oldTranslation), len(oldSource),
+		)
+	}
+
+	keys := map[string]rune{}
+	segmentRunes := func(segs []string) []rune {
+		runes := make([]rune, len(segs))
+		for i, s := range segs {
+			r, ok := keys[s]
+			if !ok {
+				r = rune(len(keys) + 1)
+				if r >= 0xD800 {
+					r += 0x800
+				}
+				keys[s] = r
+			}
+			runes[i] = r
+		}
+		return runes
+	}
+	runes1 := segmentRunes(oldSource)
+	runes2 := segmentRunes(newSource)
+
+	ret := []TranslationSegment{}
+	i, j := 0, 0
+	start1, start2 := 0, 0
+	flush := func() {
+		ret = dmp.alignChangedSegments(ret,
+			oldSource, newSource, oldTranslation, start1, i, start2, j)
+	}
+	for _, d := range dmp.DiffMainRunes(runes1, runes2, false) {
+		n := len([]rune(d.Text))
+		switch d.Type {
+		case DiffDelete:
+			i += n
+		case DiffInsert:
+			j += n
+		case DiffEqual:
+			flush()
+			for x := 0; x < n; x++ {
+				ret = append(ret, TranslationSegment{
+					TranslationCurrent, i, j, newSource[j],
+					oldTranslation[i],
+				})
+				i++
+				j++
+			}
+			start1, start2 = i, j
+		}
+	}
+	flush()
+	return ret, nil
+}
+
+// alignChangedSegments appends the proposals for the run of changed
+// segments oldSource[start1:end1] and newSource[start2:end2].
+func (dmp *DMP) alignChangedSegments(
+	ret []TranslationSegment, oldSource, newSource, oldTranslation []string,
+	start1, end1, start2, end2 int,
+) []TranslationSegment {
+	// Text offsets of the segment boundaries within the run.
+	bounds := func(segs []string) []int {
+		b := make([]int, len(segs)+1)
+		for k, s := range segs {
+			b[k+1] = b[k] + len(s)
+		}
+		return b
+	}
+	old, cur := oldSource[start1:end1], newSource[start2:end2]
+	bounds1, bounds2 := bounds(old), bounds(cur)
+
+	// shared[j][i] counts the characters of cur[j] that are kept from
+	// old[i].
+	shared := make([][]int, len(cur))
+	for k := range shared {
+		shared[k] = make([]int, len(old))
+	}
+	if len(old) > 0 && len(cur) > 0 {
+		text1 := strings.Join(old, "")
+		text2 := strings.Join(cur, "")
+		diffs := diffCleanupSemantic(
+			dmp.DiffMain(text1, text2, false), semanticOptions{},
+		)
+		x, y := 0, 0
+		i, j := 0, 0
+		for _, d := range diffs {
+			switch d.Type {
+			case DiffDelete:
+				x += len(d.Text)
+			case DiffInsert:
+				y += len(d.Text)
+			case DiffEqual:
+				// Split the equality at the boundaries of both texts.
+				for end := x + len(d.Text); x < end; {
+					for bounds1[i+1] <= x {
+						i++
+					}
+					for bounds2[j+1] <= y {
+						j++
+					}
+					n := min(end, bounds1[i+1]) - x
+					n = min(n, bounds2[j+1]-y)
+					shared[j][i] += n
+					x += n
+					y += n
+				}
+			}
+		}
+	}
+
+	paired := make([]bool, len(old))
+	for j, s := range cur {
+		best := -1
+		for i, n := range shared[j] {
+			if n > 0 && (best == -1 || n > shared[j][best]) {
+				best = i
+			}
+		}
+		if best == -1 || 2*shared[j][best] < len(s) {
+			ret = append(ret, TranslationSegment{
+				TranslationMissing, -1, start2 + j, s, "",
+			})
+			continue
+		}
+		paired[best] = true
+		ret = append(ret, TranslationSegment{
+			TranslationStale, start1 + best, start2 + j, s,
+			oldTranslation[start1+best],
+		})
+	}
+	for i, ok := range paired {
+		if !ok {
+			ret = append(ret, TranslationSegment{
+				TranslationObsolete, start1 + i, -1, old[i],
+				oldTranslation[start1+i],
+			})
+		}
+	}
+	return ret
+}
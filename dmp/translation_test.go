@@ -0,0 +1,62 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestAlignTranslation(t *testing.T) {
+	dmp := New()
+	oldSource := []string{
+		"Welcome to the app.",
+		"Click the button to start.",
+		"Your files are saved automatically.",
+		"Contact support for help.",
+	}
+	oldTranslation := []string{
+		"Willkommen in der App.",
+		"Klicken Sie auf die Schaltfläche, um zu beginnen.",
+		"Ihre Dateien werden automatisch gespeichert.",
+		"Wenden Sie sich an den Support.",
+	}
+	newSource := []string{
+		"Welcome to the app.",
+		"Click the green button to start.",
+		"Press F1 at any time.",
+		"Contact support for help.",
+		"Thank you!",
+	}
+
+	result, err := dmp.AlignTranslation(oldSource, newSource, oldTranslation)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []TranslationSegment{
+		{TranslationCurrent, 0, 0, newSource[0], oldTranslation[0]},
+		{TranslationStale, 1, 1, newSource[1], oldTranslation[1]},
+		{TranslationMissing, -1, 2, newSource[2], ""},
+		{TranslationObsolete, 2, -1, oldSource[2], oldTranslation[2]},
+		{TranslationCurrent, 3, 3, newSource[3], oldTranslation[3]},
+		{TranslationMissing, -1, 4, newSource[4], ""},
+	}, result, "")
+
+	// A sentence split in two keeps the translation for both halves.
+	result, _ = dmp.AlignTranslation(
+		[]string{"Save your work, then close the window."},
+		[]string{"Save your work.", "Then close the window."},
+		[]string{"Speichern Sie, dann schließen Sie das Fenster."},
+	)
+	assert.Equal(t, 2, len(result), "")
+	for _, r := range result {
+		assert.Equal(t, TranslationStale, r.Status, "")
+		assert.Equal(t, 0, r.OldSource, "")
+	}
+
+	result, err = dmp.AlignTranslation(nil, []string{"a"}, nil)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []TranslationSegment{
+		{TranslationMissing, -1, 0, "a", ""},
+	}, result, "")
+
+	_, err = dmp.AlignTranslation([]string{"a"}, nil, nil)
+	assert.NotNil(t, err, "Segment counts differ.")
+}
@@ -0,0 +1,230 @@
+package dmp
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var unifiedHunkHeader = regexp.MustCompile(
+	`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`,
+)
+
+// unifiedHunk is a hunk of a unified diff, with its diffs rebuilt from
+// its lines.
+type unifiedHunk struct {
+	// First line and line count of the old side, as in the header.
+	line1, count1 int
+	diffs         []Diff
+}
+
+// PatchFromUnified parses a unified diff of one file, as printed by
+// diff -u or git diff, into patches that Apply can consume.  File headers
+// and other lines between hunks are skipped.
+//
+// Unified diffs locate hunks by line while patches locate them by byte,
+// so the starts of the patches are estimated from the lengths of the
+// lines in the hunks.  Apply finds hunks by their content and tolerates
+// the estimate as long as it is within MatchDistance or so; ApplyUnified
+// computes exact starts from the text being patched.  Hunks without
+// context lines, as printed by diff -U0, only apply with ApplyUnified,
+// which takes their context from the text.
+func PatchFromUnified(text string) ([]Patch, error) {
+	hunks, err := parseUnified(text)
+	if err != nil {
+		return nil, err
+	}
+	// Estimate the offsets of lines outside the hunks from the average
+	// length of the lines inside them.
+	lines, bytes := 0, 0
+	for _, h := range hunks {
+		lines += h.count1
+		bytes += len(DiffText1(h.diffs))
+	}
+	avg := 40
+	if lines > 0 {
+		avg = (bytes + lines - 1) / lines
+	}
+	lineOffset := func(n int) int { return n * avg }
+	return unifiedPatches(hunks, lineOffset, nil), nil
+}
+
+// ApplyUnified applies a unified diff of one file to text, locating its
+// hunks by their line numbers in text.
+func (dmp *DMP) ApplyUnified(diff, text string) (string, []bool, error) {
+	hunks, err := parseUnified(diff)
+	if err != nil {
+		return text, nil, err
+	}
+	// offsets[n] is the offset of the end of line n.
+	offsets := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	lineOffset := func(n int) int {
+		if n < len(offsets) {
+			return offsets[n]
+		}
+		return len(text)
+	}
+	context := func(start, end int) (string, string) {
+		start = max(0, min(start, len(text)))
+		end = max(start, min(end, len(text)))
+		return text[max(0, start-dmp.PatchMargin):start],
+			text[end:min(len(text), end+dmp.PatchMargin)]
+	}
+	ps := unifiedPatches(hunks, lineOffset, context)
+	patched, applied := dmp.Apply(ps, text)
+	return patched, applied, nil
+}
+
+// unifiedPatches turns hunks into patches, given the offset of the text
+// after n lines of the old file.  If context is set, it returns the text
+// of the old file before and after a range, which is added to the patch
+// as context.
+func unifiedPatches(
+	hunks []unifiedHunk, lineOffset func(n int) int,
+	context func(start, end int) (string, string),
+) []Patch {
+	ps := make([]Patch, 0, len(hunks))
+	// Patches after the first start in the text patched by the ones before.
+	delta := 0
+	for _, h := range hunks {
+		var p Patch
+		p.diffs = h.diffs
+		if h.count1 == 0 {
+			// The hunk goes after line line1.
+			p.start1 = lineOffset(h.line1)
+		} else {
+			p.start1 = lineOffset(h.line1 - 1)
+		}
+		if context != nil {
+			end := p.start1 + len(DiffText1(p.diffs))
+			prefix, suffix := context(p.start1, end)
+			p.diffs = append([]Diff{{DiffEqual, prefix}}, p.diffs...)
+			p.diffs = append(p.diffs, Diff{DiffEqual, suffix})
+			p.diffs = diffCleanupMerge(p.diffs)
+			p.start1 -= len(prefix)
+		}
+		p.length1 = len(DiffText1(p.diffs))
+		p.length2 = len(DiffText2(p.diffs))
+		p.start1 += delta
+		p.start2 = p.start1
+		delta += p.length2 - p.length1
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// parseUnified parses the hunks of a unified diff of one file.
+func parseUnified(text string) ([]unifiedHunk, error) {
+	lines := strings.Split(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	hunks := []unifiedHunk{}
+	files := 0
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "--- ") {
+			if files++; files > 1 || len(hunks) > 0 {
+				return nil, fmt.Errorf(
+					"Unified diff of several files on line %d", i+1,
+				)
+			}
+			continue
+		}
+		m := unifiedHunkHeader.FindStringSubmatch(line)
+		if m == nil {
+			if strings.HasPrefix(line, "@@") {
+				return nil, fmt.Errorf(
+					"Invalid hunk header on line %d: %q", i+1, line,
+				)
+			}
+			// File headers and other chatter.
+			continue
+		}
+		h := unifiedHunk{line1: atoiDefault(m[1], 0)}
+		h.count1 = atoiDefault(m[2], 1)
+		left1, left2 := h.count1, atoiDefault(m[4], 1)
+		header := i + 1
+		for left1 > 0 || left2 > 0 {
+			i++
+			if i == len(lines) {
+				return nil, fmt.Errorf(
+					"Hunk on line %d is truncated", header,
+				)
+			}
+			line := lines[i]
+			if strings.HasPrefix(line, `\`) {
+				// "\ No newline at end of file" about the previous line.
+				h.diffs = trimLastNewline(h.diffs)
+				continue
+			}
+			var op Operation
+			switch {
+			case strings.HasPrefix(line, " ") || line == "":
+				// Some tools strip the space of empty context lines.
+				op = DiffEqual
+				left1--
+				left2--
+			case strings.HasPrefix(line, "-"):
+				op = DiffDelete
+				left1--
+			case strings.HasPrefix(line, "+"):
+				op = DiffInsert
+				left2--
+			default:
+				return nil, fmt.Errorf(
+					"Invalid line %d in hunk on line %d: %q",
+					i+1, header, line,
+				)
+			}
+			if left1 < 0 || left2 < 0 {
+				return nil, fmt.Errorf(
+					"Hunk on line %d is longer than its header says",
+					header,
+				)
+			}
+			body := line
+			if len(body) > 0 {
+				body = body[1:]
+			}
+			h.diffs = appendDiffText(h.diffs, op, body+"\n")
+		}
+		if i+1 < len(lines) && strings.HasPrefix(lines[i+1], `\`) {
+			h.diffs = trimLastNewline(h.diffs)
+			i++
+		}
+		hunks = append(hunks, h)
+	}
+	return hunks, nil
+}
+
+func atoiDefault(s string, def int) int {
+	if s == "" {
+		return def
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// appendDiffText appends text to the last diff if it has the same type.
+func appendDiffText(diffs []Diff, op Operation, text string) []Diff {
+	if n := len(diffs); n > 0 && diffs[n-1].Type == op {
+		diffs[n-1].Text += text
+		return diffs
+	}
+	return append(diffs, Diff{op, text})
+}
+
+// trimLastNewline removes the newline ending the last diff.
+func trimLastNewline(diffs []Diff) []Diff {
+	if n := len(diffs); n > 0 {
+		diffs[n-1].Text = strings.TrimSuffix(diffs[n-1].Text, "\n")
+	}
+	return diffs
+}
@@ -0,0 +1,114 @@
+package dmp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestPatchFromUnified(t *testing.T) {
+	var lines1, lines2 []string
+	for i := 1; i <= 12; i++ {
+		line := "line " + strings.Repeat("x", i)
+		lines1 = append(lines1, line)
+		if i == 2 {
+			line = "line two"
+		}
+		lines2 = append(lines2, line)
+	}
+	text1 := strings.Join(lines1, "\n") + "\n"
+	text2 := strings.Join(append(lines2, "line 13"), "\n")
+	unified := "diff --git a/f.txt b/f.txt\n" +
+		"index 3b18e51..a3e94b2 100644\n" +
+		"--- a/f.txt\n" +
+		"+++ b/f.txt\n" +
+		"@@ -1,5 +1,5 @@ func main() {\n" +
+		" line x\n" +
+		"-line xx\n" +
+		"+line two\n" +
+		" line xxx\n" +
+		" line xxxx\n" +
+		" line xxxxx\n" +
+		"@@ -10,3 +10,4 @@\n" +
+		" line xxxxxxxxxx\n" +
+		" line xxxxxxxxxxx\n" +
+		" line xxxxxxxxxxxx\n" +
+		"+line 13\n" +
+		"\\ No newline at end of file\n"
+
+	ps, err := PatchFromUnified(unified)
+	assert.Nil(t, err, "")
+	assert.Equal(t, 2, len(ps), "")
+	assert.Equal(t, []Diff{
+		{DiffEqual, "line x\n"},
+		{DiffDelete, "line xx\n"},
+		{DiffInsert, "line two\n"},
+		{DiffEqual, "line xxx\nline xxxx\nline xxxxx\n"},
+	}, ps[0].diffs, "")
+	assert.Equal(t, 0, ps[0].start1, "")
+	assert.Equal(t, Diff{DiffInsert, "line 13"}, ps[1].diffs[1], "")
+
+	dmp := New()
+	result, applied := dmp.Apply(ps, text1)
+	assert.Equal(t, text2, result, "Estimated starts.")
+	assert.Equal(t, []bool{true, true}, applied, "")
+
+	result, applied, err = dmp.ApplyUnified(unified, text1)
+	assert.Nil(t, err, "")
+	assert.Equal(t, text2, result, "Exact starts.")
+	assert.Equal(t, []bool{true, true}, applied, "")
+
+	// Pure insertion after a line.
+	ps, err = PatchFromUnified("@@ -2,0 +3 @@\n+inserted\n")
+	assert.Nil(t, err, "")
+	result, _, _ = dmp.ApplyUnified("@@ -2,0 +3 @@\n+inserted\n", "a\nb\nc\n")
+	assert.Equal(t, "a\nb\ninserted\nc\n", result, "")
+
+	_, err = PatchFromUnified("@@ -1,3 +1,3 @@\n a\n-b\n+c\n")
+	assert.NotNil(t, err, "Truncated hunk.")
+	_, err = PatchFromUnified("@@ -1,2 +1,2 @@\n a\n*b\n")
+	assert.NotNil(t, err, "Invalid line.")
+	_, err = PatchFromUnified("@@ -1 +1 @@\n-a\n+b\n" +
+		"--- a/g\n+++ b/g\n@@ -1 +1 @@\n-a\n+b\n")
+	assert.NotNil(t, err, "Several files.")
+}
+
+func TestDiffUnified(t *testing.T) {
+	dmp := New()
+	text1 := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	text2 := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk"
+	assert.Equal(t, "", dmp.DiffUnified(text1, text1, 3), "")
+	assert.Equal(t,
+		"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"+
+			"@@ -10 +10,2 @@\n j\n+k\n\\ No newline at end of file\n",
+		dmp.DiffUnified(text1, text2, 1), "")
+	assert.Equal(t,
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n"+
+			"@@ -8,3 +8,4 @@\n h\n i\n j\n+k\n\\ No newline at end of file\n",
+		dmp.DiffUnified(text1, text2, 3), "")
+	assert.Equal(t, "@@ -0,0 +1 @@\n+x\n", dmp.DiffUnified("", "x\n", 3), "")
+
+	// The hunks apply back.
+	patched, applied, err := dmp.ApplyUnified(
+		dmp.DiffUnified(text1, text2, 1), text1,
+	)
+	assert.Nil(t, err, "")
+	assert.Equal(t, []bool{true, true}, applied, "")
+	assert.Equal(t, text2, patched, "")
+}
+
+func TestDiffWriteUnified(t *testing.T) {
+	dmp := New()
+	text1 := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n"
+	text2 := "a\nB\nc\nd\ne\nf\ng\nh\ni\nJ\n"
+	var buf bytes.Buffer
+	assert.Nil(t, dmp.DiffWriteUnified(&buf, text1, text2, 1), "")
+	assert.Equal(t, dmp.DiffUnified(text1, text2, 1), buf.String(), "")
+	assert.Equal(t, 2, strings.Count(buf.String(), "@@ -"), "")
+
+	w := &failWriter{1}
+	assert.NotNil(t, dmp.DiffWriteUnified(w, text1, text2, 1), "")
+	assert.Equal(t, 0, w.n, "Stops at the first error.")
+}
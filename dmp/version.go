@@ -0,0 +1,186 @@
+package dmp
+
+// VersionVector is a logical clock of a replicated text: the number of
+// changes of each replica, by name, that a version includes.  A replica
+// making a change increments its own count.
+type VersionVector map[string]uint64
+
+// VersionOrder is how two versions relate.
+type VersionOrder int8
+
+const (
+	// VersionEqual versions include the same changes.
+	VersionEqual VersionOrder = iota
+	// VersionBefore means that the other version includes all the changes of
+	// this one and more.
+	VersionBefore
+	// VersionAfter means that this version includes all the changes of the
+	// other and more.
+	VersionAfter
+	// VersionConcurrent versions each include changes the other does not.
+	VersionConcurrent
+)
+
+// Compare tells how v relates to w.
+func (v VersionVector) Compare(w VersionVector) VersionOrder {
+	less, more := false, false
+	for r, n := range v {
+		if n > w[r] {
+			more = true
+		} else if n < w[r] {
+			less = true
+		}
+	}
+	for r, n := range w {
+		if _, ok := v[r]; !ok && n > 0 {
+			less = true
+		}
+	}
+	switch {
+	case less && more:
+		return VersionConcurrent
+	case less:
+		return VersionBefore
+	case more:
+		return VersionAfter
+	}
+	return VersionEqual
+}
+
+// Merge returns the version including the changes of both v and w.
+func (v VersionVector) Merge(w VersionVector) VersionVector {
+	ret := v.Copy()
+	if ret == nil {
+		ret = VersionVector{}
+	}
+	for r, n := range w {
+		if n > ret[r] {
+			ret[r] = n
+		}
+	}
+	return ret
+}
+
+// Copy returns a copy of v.
+func (v VersionVector) Copy() VersionVector {
+	if v == nil {
+		return nil
+	}
+	ret := make(VersionVector, len(v))
+	for r, n := range v {
+		ret[r] = n
+	}
+	return ret
+}
+
+// PatchSequencer orders the patches that arrive from several replicas, in
+// any order and possibly more than once, so that each is applied once and
+// after the changes it was made on.  A patch of replica r with version v
+// is the v[r]th change of r, made on the changes of the other replicas
+// that v counts.  The patches of one change, as made by one PatchMake,
+// share their replica and version and must arrive together.  Patches
+// without a replica are passed on as they arrive.
+type PatchSequencer struct {
+	// Changes delivered so far.
+	seen VersionVector
+	// Changes waiting for the changes they were made on, in order of
+	// arrival.
+	pending [][]Patch
+}
+
+// NewPatchSequencer returns a PatchSequencer for a text that includes the
+// changes of version.
+func NewPatchSequencer(version VersionVector) *PatchSequencer {
+	return &PatchSequencer{seen: VersionVector{}.Merge(version)}
+}
+
+// Version returns the version of the text once the patches returned so
+// far are applied.
+func (s *PatchSequencer) Version() VersionVector {
+	return s.seen.Copy()
+}
+
+// Pending returns the number of changes waiting for others to arrive.
+func (s *PatchSequencer) Pending() int {
+	return len(s.pending)
+}
+
+// Add takes patches that arrived, and returns the patches that can now be
+// applied, in the order to apply them.  Patches of changes already
+// delivered are dropped.
+func (s *PatchSequencer) Add(ps []Patch) []Patch {
+	ready := []Patch{}
+	for i := 0; i < len(ps); {
+		if ps[i].Replica == "" {
+			ready = append(ready, ps[i])
+			i++
+			continue
+		}
+		// Gather the patches of one change.
+		j := i + 1
+		for j < len(ps) && ps[j].Replica == ps[i].Replica &&
+			ps[j].Version.Compare(ps[i].Version) == VersionEqual {
+			j++
+		}
+		if !s.delivered(ps[i]) && !s.queued(ps[i]) {
+			s.pending = append(s.pending, ps[i:j])
+		}
+		i = j
+	}
+
+	// Deliver the changes whose predecessors are all delivered, until none
+	// is left.
+	for progress := true; progress; {
+		progress = false
+		for k := 0; k < len(s.pending); k++ {
+			change := s.pending[k]
+			p := change[0]
+			if s.delivered(p) {
+				s.pending = append(s.pending[:k], s.pending[k+1:]...)
+				k--
+				continue
+			}
+			if !s.deliverable(p) {
+				continue
+			}
+			ready = append(ready, change...)
+			s.seen[p.Replica] = p.Version[p.Replica]
+			s.pending = append(s.pending[:k], s.pending[k+1:]...)
+			k--
+			progress = true
+		}
+	}
+	return ready
+}
+
+// delivered tells whether the change of p was delivered.
+func (s *PatchSequencer) delivered(p Patch) bool {
+	return p.Version[p.Replica] <= s.seen[p.Replica]
+}
+
+// queued tells whether the change of p is pending.
+func (s *PatchSequencer) queued(p Patch) bool {
+	for _, change := range s.pending {
+		q := change[0]
+		if q.Replica == p.Replica &&
+			q.Version[q.Replica] == p.Version[p.Replica] {
+			return true
+		}
+	}
+	return false
+}
+
+// deliverable tells whether the change of p is the next of its replica,
+// and all the changes of other replicas it was made on were delivered.
+func (s *PatchSequencer) deliverable(p Patch) bool {
+	for r, n := range p.Version {
+		if r == p.Replica {
+			if n != s.seen[r]+1 {
+				return false
+			}
+		} else if n > s.seen[r] {
+			return false
+		}
+	}
+	return true
+}
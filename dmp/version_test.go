@@ -0,0 +1,91 @@
+package dmp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestVersionVector(t *testing.T) {
+	v := VersionVector{"a": 1, "b": 2}
+	assert.Equal(t, VersionEqual, v.Compare(VersionVector{"a": 1, "b": 2}), "")
+	assert.Equal(t, VersionEqual,
+		VersionVector{"a": 0}.Compare(VersionVector{}), "")
+	assert.Equal(t, VersionBefore, v.Compare(VersionVector{"a": 1, "b": 3}), "")
+	assert.Equal(t, VersionBefore,
+		v.Compare(VersionVector{"a": 1, "b": 2, "c": 1}), "")
+	assert.Equal(t, VersionAfter, v.Compare(VersionVector{"b": 2}), "")
+	assert.Equal(t, VersionConcurrent,
+		v.Compare(VersionVector{"a": 2, "b": 1}), "")
+
+	assert.Equal(t, VersionVector{"a": 2, "b": 2, "c": 1},
+		v.Merge(VersionVector{"a": 2, "c": 1}), "")
+	assert.Equal(t, VersionVector{"a": 1, "b": 2}, v, "")
+	assert.Equal(t, VersionVector{"x": 1},
+		VersionVector(nil).Merge(VersionVector{"x": 1}), "")
+}
+
+// versioned returns the patches turning text1 into text2, made by replica
+// at version.
+func versioned(
+	text1, text2, replica string, version VersionVector,
+) []Patch {
+	ps := New().PatchMake(text1, text2)
+	for i := range ps {
+		ps[i].Replica = replica
+		ps[i].Version = version
+	}
+	return ps
+}
+
+func TestPatchSequencer(t *testing.T) {
+	dmp := New()
+	base := "The quick brown fox."
+	a1 := versioned(base, "The slow brown fox.", "a", VersionVector{"a": 1})
+	afterA1 := "The slow brown fox."
+	b1 := versioned(afterA1, "The slow brown dog.", "b",
+		VersionVector{"a": 1, "b": 1})
+	a2 := versioned(afterA1, "The slow brown fox!", "a",
+		VersionVector{"a": 2})
+
+	s := NewPatchSequencer(nil)
+	// b1 was made on a1, and waits for it.
+	assert.Equal(t, []Patch{}, s.Add(b1), "")
+	assert.Equal(t, 1, s.Pending(), "")
+	assert.Equal(t, []Patch{}, s.Add(a2), "")
+	ready := s.Add(append(a1, b1...))
+	assert.Equal(t, 0, s.Pending(), "")
+	assert.Equal(t, VersionVector{"a": 2, "b": 1}, s.Version(), "")
+
+	text, applied := dmp.Apply(ready, base)
+	assert.True(t, allTrue(applied), "")
+	assert.Equal(t, "The slow brown dog!", text, "")
+
+	// Duplicates are dropped.
+	assert.Equal(t, []Patch{}, s.Add(a1), "")
+	assert.Equal(t, []Patch{}, s.Add(b1), "")
+
+	// Patches without a replica are passed on.
+	plain := dmp.PatchMake("x", "y")
+	assert.Equal(t, plain, s.Add(plain), "")
+}
+
+func TestPatchVersionJSON(t *testing.T) {
+	ps := versioned("abc", "abd", "a", VersionVector{"a": 3})
+	data, err := json.Marshal(ps[0])
+	assert.Nil(t, err, "")
+	var p Patch
+	assert.Nil(t, json.Unmarshal(data, &p), "")
+	assert.Equal(t, ps[0], p, "")
+
+	// Copies do not share the version.
+	cp := PatchDeepCopy(ps)
+	cp[0].Version["a"] = 4
+	assert.Equal(t, uint64(3), ps[0].Version["a"], "")
+
+	data, err = json.Marshal(New().PatchMake("abc", "abd")[0])
+	assert.Nil(t, err, "")
+	assert.False(t, strings.Contains(string(data), "version"), "")
+}
@@ -0,0 +1,106 @@
+package dmp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WhitespaceClass tells whether a change only touches whitespace, so that
+// renderers can de-emphasize it and reviewers can filter it out.
+type WhitespaceClass int8
+
+const (
+	// NotWhitespace changes alter the text; equalities are also
+	// NotWhitespace.
+	NotWhitespace WhitespaceClass = iota
+	// WhitespaceOnly changes only add, remove or move whitespace.
+	WhitespaceOnly
+	// IndentationOnly changes are WhitespaceOnly changes that only touch
+	// the spaces and tabs at the start of lines.
+	IndentationOnly
+	// EOLOnly changes are WhitespaceOnly changes that only convert line
+	// endings, such as CRLF to LF.
+	EOLOnly
+)
+
+// DiffClassifyWhitespace returns the class of each diff.  The deletions
+// and insertions between two equalities are classified together: they are
+// WhitespaceOnly if the deleted and inserted texts are the same once all
+// whitespace is removed.
+func DiffClassifyWhitespace(diffs []Diff) []WhitespaceClass {
+	diffs = withoutMoves(diffs)
+	classes := make([]WhitespaceClass, len(diffs))
+	// Whether the current line of each text only has blanks so far.
+	blank1, blank2 := true, true
+	start := 0
+	for start < len(diffs) {
+		if diffs[start].Type == DiffEqual {
+			blank1 = blankLineEnd(diffs[start].Text, blank1)
+			blank2 = blankLineEnd(diffs[start].Text, blank2)
+			start++
+			continue
+		}
+		end := start
+		var deleted, inserted []string
+		indentation := true
+		for ; end < len(diffs) && diffs[end].Type != DiffEqual; end++ {
+			d := diffs[end]
+			if d.Type == DiffDelete {
+				deleted = append(deleted, d.Text)
+				indentation = indentation && blank1 && isBlank(d.Text)
+				blank1 = blankLineEnd(d.Text, blank1)
+			} else {
+				inserted = append(inserted, d.Text)
+				indentation = indentation && blank2 && isBlank(d.Text)
+				blank2 = blankLineEnd(d.Text, blank2)
+			}
+		}
+		class := NotWhitespace
+		if stripSpace(deleted) == stripSpace(inserted) {
+			class = WhitespaceOnly
+			var before, after string
+			if start > 0 {
+				before = diffs[start-1].Text
+			}
+			if end < len(diffs) {
+				after = diffs[end].Text
+			}
+			if indentation {
+				class = IndentationOnly
+			} else if eolOnly(before, strings.Join(deleted, ""),
+				strings.Join(inserted, ""), after) {
+				class = EOLOnly
+			}
+		}
+		for i := start; i < end; i++ {
+			classes[i] = class
+		}
+		start = end
+	}
+	return classes
+}
+
+// stripSpace joins texts and removes all whitespace.
+func stripSpace(texts []string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, strings.Join(texts, ""))
+}
+
+// isBlank tells whether s only has spaces and tabs.
+func isBlank(s string) bool {
+	return strings.Trim(s, " \t") == ""
+}
+
+// blankLineEnd tells whether the line is still blank after s, given
+// whether it was blank before s.
+func blankLineEnd(s string, blank bool) bool {
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		s = s[i+1:]
+		blank = true
+	}
+	return blank && isBlank(s)
+}
@@ -0,0 +1,43 @@
+package dmp
+
+import (
+	"testing"
+
+	"github.com/stretchrcom/testify/assert"
+)
+
+func TestDiffClassifyWhitespace(t *testing.T) {
+	diffs := []Diff{
+		{DiffEqual, "if x {\n"},
+		{DiffDelete, "  "}, {DiffInsert, "\t"},
+		{DiffEqual, "return a +"},
+		{DiffInsert, " "},
+		{DiffEqual, "b\n"},
+		{DiffDelete, "}"}, {DiffInsert, "} // done"},
+	}
+	assert.Equal(t, []WhitespaceClass{
+		NotWhitespace,
+		IndentationOnly, IndentationOnly,
+		NotWhitespace,
+		WhitespaceOnly,
+		NotWhitespace,
+		NotWhitespace, NotWhitespace,
+	}, DiffClassifyWhitespace(diffs), "")
+
+	// Text moved across whitespace.
+	diffs = []Diff{
+		{DiffEqual, "a"}, {DiffDelete, " b"}, {DiffInsert, "b\n"},
+		{DiffEqual, "c"},
+	}
+	assert.Equal(t, []WhitespaceClass{
+		NotWhitespace, WhitespaceOnly, WhitespaceOnly, NotWhitespace,
+	}, DiffClassifyWhitespace(diffs), "")
+
+	// Blank lines added at the start.
+	diffs = []Diff{{DiffInsert, "\n\n"}, {DiffEqual, "text"}}
+	assert.Equal(t, []WhitespaceClass{WhitespaceOnly, NotWhitespace},
+		DiffClassifyWhitespace(diffs), "")
+
+	assert.Equal(t, []WhitespaceClass{},
+		DiffClassifyWhitespace([]Diff{}), "")
+}
@@ -0,0 +1,81 @@
+// Command sync demonstrates keeping two copies of a document in sync with
+// patches, in the style of differential synchronization: each side keeps a
+// shadow of what the other side last saw, sends the patch from the shadow
+// to its current text, and applies the patches it receives to both its
+// shadow and its text.  Both sides edit concurrently between rounds.
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/sergi/go-diff/dmp"
+)
+
+// peer is one copy of the document.
+type peer struct {
+	name   string
+	text   string
+	shadow string
+}
+
+// send returns the patch of the local edits since the last round, in the
+// text form that would go over the wire, and updates the shadow.
+func (p *peer) send(d *dmp.DMP) string {
+	patches := d.PatchMake(p.shadow, p.text)
+	p.shadow = p.text
+	return dmp.PatchToText(patches)
+}
+
+// receive applies a patch sent by the other side.
+func (p *peer) receive(d *dmp.DMP, wire string) {
+	patches, err := dmp.PatchFromText(wire)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// The shadow matches what the sender diffed against, so the patches
+	// apply exactly; the text may have local edits and is patched fuzzily.
+	p.shadow, _ = d.Apply(patches, p.shadow)
+	text, applied := d.Apply(patches, p.text)
+	for i, ok := range applied {
+		if !ok {
+			fmt.Printf("%s: patch %d did not apply\n", p.name, i)
+		}
+	}
+	p.text = text
+}
+
+func main() {
+	d := dmp.New()
+	doc := "Shopping list:\nmilk\nbread\neggs\n"
+	client := &peer{name: "client", text: doc, shadow: doc}
+	server := &peer{name: "server", text: doc, shadow: doc}
+
+	edits := []struct{ client, server func(string) string }{
+		{
+			func(s string) string { return s + "apples\n" },
+			func(s string) string { return "Weekly s" + s[1:] },
+		},
+		{
+			func(s string) string {
+				return strings.Replace(s, "apples", "pears", 1)
+			},
+			func(s string) string { return s },
+		},
+	}
+	for round, e := range edits {
+		client.text = e.client(client.text)
+		server.text = e.server(server.text)
+
+		// The client sends first, then the server replies with its own
+		// edits, which now include the client's.
+		server.receive(d, client.send(d))
+		client.receive(d, server.send(d))
+
+		fmt.Printf("after round %d:\n%s", round+1, client.text)
+		if client.text != server.text {
+			log.Fatalf("copies differ:\n%s---\n%s", client.text, server.text)
+		}
+	}
+}